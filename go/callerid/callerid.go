@@ -0,0 +1,49 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package callerid stores and retrieves a mtrpc.CallerID in a context.
+// The CallerID is informational only: it identifies the originating
+// client of a request for logging and auditing purposes.
+package callerid
+
+import (
+	"context"
+
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// callerIDKey is the type of the context key for the CallerID.
+// It is unexported to prevent collisions with context keys defined in
+// other packages.
+type callerIDKey struct{}
+
+// NewCallerID creates a mtrpc.CallerID with the given fields.
+func NewCallerID(principal, component, subcomponent string) *mtrpcpb.CallerID {
+	return &mtrpcpb.CallerID{
+		Principal:    principal,
+		Component:    component,
+		Subcomponent: subcomponent,
+	}
+}
+
+// NewContext returns a copy of ctx that carries the provided CallerID.
+func NewContext(ctx context.Context, cid *mtrpcpb.CallerID) context.Context {
+	return context.WithValue(ctx, callerIDKey{}, cid)
+}
+
+// FromContext returns the CallerID stored in ctx, or nil if there is none.
+func FromContext(ctx context.Context) *mtrpcpb.CallerID {
+	cid, _ := ctx.Value(callerIDKey{}).(*mtrpcpb.CallerID)
+	return cid
+}
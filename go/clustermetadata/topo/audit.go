@@ -0,0 +1,526 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/multigres/multigres/go/callerid"
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// This file implements the optional audit log of the topology. When an
+// AuditSink is configured on the store, every successful mutation
+// (Create, Update, Delete) and every lock acquisition, on the global
+// and on all the cell topologies, is reported to the sink along with
+// the CallerID found in the context.
+
+// AuditOperation is the kind of topology operation being audited.
+type AuditOperation string
+
+// The list of audited operations.
+const (
+	AuditCreate AuditOperation = "create"
+	AuditUpdate AuditOperation = "update"
+	AuditDelete AuditOperation = "delete"
+	AuditLock   AuditOperation = "lock"
+)
+
+// FieldDiff describes the change of a single top-level field of a record.
+// Old and New are empty when the field was not set.
+type FieldDiff struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// AuditEvent describes one audited topology operation.
+type AuditEvent struct {
+	// Time is when the operation completed.
+	Time time.Time
+
+	// Cell is the topology the operation ran against (GlobalCell for
+	// the global topology).
+	Cell string
+
+	// Operation is the kind of operation.
+	Operation AuditOperation
+
+	// Path is the file path for mutations, and the directory path
+	// for locks.
+	Path string
+
+	// CallerID is the caller found in the context, if any.
+	CallerID *mtrpcpb.CallerID
+
+	// Version is the version of the record after the mutation.
+	// It is empty for deletes and locks.
+	Version string
+
+	// Old and New are the decoded record before and after the mutation,
+	// for paths that hold a known record type. Old is nil for creates
+	// and New is nil for deletes.
+	Old proto.Message
+	New proto.Message
+
+	// OldUnknown is set for the updates and deletes of a record whose
+	// previous version couldn't be found. Old is then nil, and Diff
+	// lists the fields set in New.
+	OldUnknown bool
+
+	// Redacted is set for the records the topology encrypts at rest. Old
+	// and New are then nil, and Diff has the names of the fields only.
+	Redacted bool
+
+	// Diff lists the top-level fields that changed between Old and New.
+	Diff []FieldDiff
+
+	// LockContents is the description of the lock holder, for locks.
+	LockContents string
+}
+
+// AuditSink receives audit events. Implementations must be safe for
+// concurrent use.
+type AuditSink interface {
+	Record(ctx context.Context, ev *AuditEvent)
+}
+
+// slogAuditSink writes audit events as structured log records.
+type slogAuditSink struct {
+	logger *slog.Logger
+}
+
+// NewSlogAuditSink returns an AuditSink that writes one log record per
+// event to the provided logger.
+func NewSlogAuditSink(logger *slog.Logger) AuditSink {
+	return &slogAuditSink{logger: logger}
+}
+
+// Record is part of the AuditSink interface.
+func (s *slogAuditSink) Record(ctx context.Context, ev *AuditEvent) {
+	attrs := []slog.Attr{
+		slog.Time("time", ev.Time),
+		slog.String("cell", ev.Cell),
+		slog.String("operation", string(ev.Operation)),
+		slog.String("path", ev.Path),
+	}
+	if ev.CallerID != nil {
+		attrs = append(attrs, slog.Group("caller",
+			"principal", ev.CallerID.Principal,
+			"component", ev.CallerID.Component,
+			"subcomponent", ev.CallerID.Subcomponent,
+			"groups", ev.CallerID.Groups,
+		))
+	}
+	if ev.Version != "" {
+		attrs = append(attrs, slog.String("version", ev.Version))
+	}
+	if ev.OldUnknown {
+		attrs = append(attrs, slog.Bool("old_unknown", true))
+	}
+	if len(ev.Diff) > 0 {
+		attrs = append(attrs, slog.Any("diff", ev.Diff))
+	}
+	if ev.LockContents != "" {
+		attrs = append(attrs, slog.String("lock_contents", ev.LockContents))
+	}
+	s.logger.LogAttrs(ctx, slog.LevelInfo, "topo audit", attrs...)
+}
+
+// FileAuditSink writes audit events as JSON lines to an append-only file.
+type FileAuditSink struct {
+	AuditSink
+	file *os.File
+}
+
+// NewFileAuditSink opens (or creates) the file at filePath in append-only
+// mode and returns a sink writing to it. Close must be called to release
+// the file.
+func NewFileAuditSink(filePath string) (*FileAuditSink, error) {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileAuditSink{
+		AuditSink: NewSlogAuditSink(slog.New(slog.NewJSONHandler(file, nil))),
+		file:      file,
+	}, nil
+}
+
+// Close closes the underlying file.
+func (s *FileAuditSink) Close() error {
+	return s.file.Close()
+}
+
+// StoreOption configures optional behavior of a store created with
+// NewWithFactory or OpenServer.
+type StoreOption func(*store)
+
+// WithAuditSink makes the store report every mutation and lock
+// acquisition to sink.
+func WithAuditSink(sink AuditSink) StoreOption {
+	return func(ts *store) {
+		ts.auditSink = sink
+	}
+}
+
+// EncryptingFactory is implemented by the factories that encrypt some of
+// the records at rest, like the one of encryptedtopo. The audit log
+// redacts the values of these records.
+type EncryptingFactory interface {
+	Factory
+
+	// Encrypts returns true if the record at filePath is encrypted.
+	Encrypts(filePath string) bool
+}
+
+// auditRecord decodes contents as the record stored at filePath.
+// It returns nil if the record type is unknown or can't be decoded.
+func auditRecord(filePath string, contents []byte) proto.Message {
+	msg := newRecordForPath(filePath)
+	if msg == nil || contents == nil {
+		return nil
+	}
+	if _, err := decodeRecord(contents, msg); err != nil {
+		return nil
+	}
+	return msg
+}
+
+// DiffRecords returns the top-level fields that differ between old and
+// new. Either may be nil, in which case all the fields set in the other
+// record are reported.
+func DiffRecords(old, new proto.Message) []FieldDiff {
+	var desc protoreflect.MessageDescriptor
+	switch {
+	case old != nil:
+		desc = old.ProtoReflect().Descriptor()
+	case new != nil:
+		desc = new.ProtoReflect().Descriptor()
+	default:
+		return nil
+	}
+
+	var diffs []FieldDiff
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		oldSet, oldValue := fieldValue(old, fd)
+		newSet, newValue := fieldValue(new, fd)
+		if !oldSet && !newSet {
+			continue
+		}
+		if oldSet && newSet && oldValue.Equal(newValue) {
+			continue
+		}
+		diff := FieldDiff{Field: string(fd.Name())}
+		if oldSet {
+			diff.Old = formatFieldValue(fd, oldValue)
+		}
+		if newSet {
+			diff.New = formatFieldValue(fd, newValue)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+func fieldValue(msg proto.Message, fd protoreflect.FieldDescriptor) (bool, protoreflect.Value) {
+	if msg == nil {
+		return false, protoreflect.Value{}
+	}
+	m := msg.ProtoReflect()
+	if !m.Has(fd) {
+		return false, protoreflect.Value{}
+	}
+	return true, m.Get(fd)
+}
+
+func formatFieldValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch {
+	case fd.IsList():
+		list := v.List()
+		values := make([]string, list.Len())
+		for i := range values {
+			values[i] = formatScalar(fd, list.Get(i))
+		}
+		return fmt.Sprintf("%v", values)
+	case fd.IsMap():
+		entries := make(map[string]string)
+		v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+			entries[k.String()] = formatScalar(fd.MapValue(), mv)
+			return true
+		})
+		return fmt.Sprintf("%v", entries)
+	default:
+		return formatScalar(fd, v)
+	}
+}
+
+func formatScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return prototext.MarshalOptions{}.Format(v.Message().Interface())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return fmt.Sprintf("%d", v.Enum())
+	case protoreflect.BytesKind:
+		return fmt.Sprintf("%x", v.Bytes())
+	default:
+		return v.String()
+	}
+}
+
+// auditSeenRecords is the number of records whose last version an
+// auditConn keeps.
+const auditSeenRecords = 4096
+
+// auditConn wraps a Conn and reports mutations and lock acquisitions
+// to an AuditSink.
+//
+// The old value of an updated or deleted record is the last version of it
+// read or written through the connection, without another round trip. It
+// is only reported if it is the version being replaced, or for the
+// unconditional updates and deletes, which replace whatever version is
+// current. The last versions of the auditSeenRecords records used most
+// recently are kept. Otherwise, the version being replaced is read with
+// GetVersion, if the topology supports it.
+type auditConn struct {
+	Conn
+	cell string
+	sink AuditSink
+	// encrypts returns true for the records to redact, if not nil.
+	encrypts func(filePath string) bool
+
+	// mu protects seen and recent.
+	mu sync.Mutex
+	// seen has the elements of recent, by path.
+	seen map[string]*list.Element
+	// recent has the last version read or written of the audited
+	// records, the most recently used first.
+	recent *list.List
+}
+
+// seenRecord is a version of a record.
+type seenRecord struct {
+	filePath string
+	contents []byte
+	version  string
+}
+
+var _ Conn = (*auditConn)(nil)
+
+func newAuditConn(cell string, conn Conn, sink AuditSink, factory Factory) Conn {
+	c := &auditConn{
+		Conn:   conn,
+		cell:   cell,
+		sink:   sink,
+		seen:   make(map[string]*list.Element),
+		recent: list.New(),
+	}
+	if ef, ok := factory.(EncryptingFactory); ok {
+		c.encrypts = ef.Encrypts
+	}
+	return c
+}
+
+// record builds the event for a completed operation and sends it to the
+// sink. oldKnown is false if the old version of an update or a delete
+// couldn't be found.
+func (c *auditConn) record(ctx context.Context, op AuditOperation, filePath string, version Version, old proto.Message, oldKnown bool, new proto.Message) {
+	ev := &AuditEvent{
+		Time:       time.Now(),
+		Cell:       c.cell,
+		Operation:  op,
+		Path:       filePath,
+		CallerID:   callerid.FromContext(ctx),
+		Old:        old,
+		New:        new,
+		OldUnknown: !oldKnown,
+		Diff:       DiffRecords(old, new),
+	}
+	if version != nil {
+		ev.Version = version.String()
+	}
+	if c.encrypts != nil && c.encrypts(filePath) {
+		ev.Old, ev.New, ev.Redacted = nil, nil, true
+		for i := range ev.Diff {
+			if ev.Diff[i].Old != "" {
+				ev.Diff[i].Old = mterrors.RedactedPlaceholder
+			}
+			if ev.Diff[i].New != "" {
+				ev.Diff[i].New = mterrors.RedactedPlaceholder
+			}
+		}
+	}
+	c.sink.Record(ctx, ev)
+}
+
+// remember records the contents of filePath at version, or forgets them
+// if contents is nil.
+func (c *auditConn) remember(filePath string, contents []byte, version Version) {
+	if newRecordForPath(filePath) == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.seen[filePath]; ok {
+		c.recent.Remove(e)
+		delete(c.seen, filePath)
+	}
+	if contents == nil || version == nil {
+		return
+	}
+	c.seen[filePath] = c.recent.PushFront(seenRecord{filePath: filePath, contents: contents, version: version.String()})
+	if c.recent.Len() > auditSeenRecords {
+		oldest := c.recent.Remove(c.recent.Back()).(seenRecord)
+		delete(c.seen, oldest.filePath)
+	}
+}
+
+// previous returns the decoded record at filePath replaced by an update
+// or a delete at version, nil for an unconditional one, and whether it
+// was found. Records of an unknown type are always found, as nil.
+func (c *auditConn) previous(ctx context.Context, filePath string, version Version) (proto.Message, bool) {
+	if newRecordForPath(filePath) == nil {
+		return nil, true
+	}
+	c.mu.Lock()
+	var seen seenRecord
+	e, ok := c.seen[filePath]
+	if ok {
+		seen = e.Value.(seenRecord)
+	}
+	c.mu.Unlock()
+	if ok && (version == nil || version.String() == seen.version) {
+		return auditRecord(filePath, seen.contents), true
+	}
+	if version == nil {
+		return nil, false
+	}
+	v, err := strconv.ParseInt(version.String(), 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	contents, err := c.Conn.GetVersion(ctx, filePath, v)
+	if err != nil {
+		return nil, false
+	}
+	old := auditRecord(filePath, contents)
+	return old, old != nil
+}
+
+// Get is part of the Conn interface.
+func (c *auditConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	contents, version, err := c.Conn.Get(ctx, filePath)
+	if err == nil {
+		c.remember(filePath, contents, version)
+	}
+	return contents, version, err
+}
+
+// Create is part of the Conn interface.
+func (c *auditConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	version, err := c.Conn.Create(ctx, filePath, contents)
+	if err == nil {
+		c.remember(filePath, contents, version)
+		c.record(ctx, AuditCreate, filePath, version, nil, true, auditRecord(filePath, contents))
+	}
+	return version, err
+}
+
+// Update is part of the Conn interface.
+func (c *auditConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	old, oldKnown := c.previous(ctx, filePath, version)
+	newVersion, err := c.Conn.Update(ctx, filePath, contents, version)
+	if err == nil {
+		c.remember(filePath, contents, newVersion)
+		c.record(ctx, AuditUpdate, filePath, newVersion, old, oldKnown, auditRecord(filePath, contents))
+	}
+	return newVersion, err
+}
+
+// Delete is part of the Conn interface.
+func (c *auditConn) Delete(ctx context.Context, filePath string, version Version) error {
+	old, oldKnown := c.previous(ctx, filePath, version)
+	err := c.Conn.Delete(ctx, filePath, version)
+	if err == nil {
+		c.remember(filePath, nil, nil)
+		c.record(ctx, AuditDelete, filePath, nil, old, oldKnown, nil)
+	}
+	return err
+}
+
+// recordLock sends the event for a lock acquisition to the sink.
+func (c *auditConn) recordLock(ctx context.Context, dirPath, contents string) {
+	c.sink.Record(ctx, &AuditEvent{
+		Time:         time.Now(),
+		Cell:         c.cell,
+		Operation:    AuditLock,
+		Path:         dirPath,
+		CallerID:     callerid.FromContext(ctx),
+		LockContents: contents,
+	})
+}
+
+// Lock is part of the Conn interface.
+func (c *auditConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	ld, err := c.Conn.Lock(ctx, dirPath, contents)
+	if err == nil {
+		c.recordLock(ctx, dirPath, contents)
+	}
+	return ld, err
+}
+
+// LockWithTTL is part of the Conn interface.
+func (c *auditConn) LockWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	ld, err := c.Conn.LockWithTTL(ctx, dirPath, contents, ttl)
+	if err == nil {
+		c.recordLock(ctx, dirPath, contents)
+	}
+	return ld, err
+}
+
+// LockName is part of the Conn interface.
+func (c *auditConn) LockName(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	ld, err := c.Conn.LockName(ctx, dirPath, contents)
+	if err == nil {
+		c.recordLock(ctx, dirPath, contents)
+	}
+	return ld, err
+}
+
+// TryLock is part of the Conn interface.
+func (c *auditConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	ld, err := c.Conn.TryLock(ctx, dirPath, contents)
+	if err == nil {
+		c.recordLock(ctx, dirPath, contents)
+	}
+	return ld, err
+}
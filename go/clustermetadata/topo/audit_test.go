@@ -0,0 +1,303 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/callerid"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/encryptedtopo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+	"github.com/multigres/multigres/go/mterrors"
+)
+
+// memoryAuditSink keeps all the audit events in memory.
+type memoryAuditSink struct {
+	mu     sync.Mutex
+	events []*topo.AuditEvent
+}
+
+func (s *memoryAuditSink) Record(ctx context.Context, ev *topo.AuditEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, ev)
+}
+
+func (s *memoryAuditSink) Events() []*topo.AuditEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*topo.AuditEvent(nil), s.events...)
+}
+
+// newAuditedServer returns a memory topo store reporting to sink.
+func newAuditedServer(t *testing.T, sink topo.AuditSink, cells ...string) topo.Store {
+	t.Helper()
+	ctx := context.Background()
+	_, factory := memorytopo.NewServerAndFactory(ctx, cells...)
+	ts, err := topo.NewWithFactory(factory, "", []string{""}, topo.WithAuditSink(sink))
+	require.NoError(t, err)
+	t.Cleanup(func() { ts.Close() })
+	return ts
+}
+
+func TestAuditMutations(t *testing.T) {
+	sink := &memoryAuditSink{}
+	ts := newAuditedServer(t, sink, "zone1")
+	caller := callerid.NewCallerID("alice", "multiorch", "reparent")
+	ctx := callerid.NewContext(context.Background(), caller)
+
+	mp := topo.NewMultiPooler("1", "zone1", "host1")
+	mp.Database = "db1"
+	require.NoError(t, ts.CreateMultiPooler(ctx, mp))
+	_, err := ts.UpdateMultiPoolerFields(ctx, mp.Id, func(mp *clustermetadatapb.MultiPooler) error {
+		mp.Hostname = "host2"
+		mp.ServingStatus = clustermetadatapb.PoolerServingStatus_NOT_SERVING
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, ts.DeleteMultiPooler(ctx, mp.Id))
+
+	events := sink.Events()
+	require.Len(t, events, 3)
+	poolerPath := path.Join(topo.PoolersPath, topo.MultiPoolerIDString(mp.Id), topo.PoolerFile)
+	for _, ev := range events {
+		require.Equal(t, "zone1", ev.Cell)
+		require.Equal(t, poolerPath, ev.Path)
+		require.Equal(t, "alice", ev.CallerID.GetPrincipal())
+		require.Equal(t, "multiorch", ev.CallerID.GetComponent())
+		require.False(t, ev.Time.IsZero())
+	}
+
+	create := events[0]
+	require.Equal(t, topo.AuditCreate, create.Operation)
+	require.NotEmpty(t, create.Version)
+	require.Nil(t, create.Old)
+	require.Contains(t, create.Diff, topo.FieldDiff{Field: "hostname", New: "host1"})
+	require.Contains(t, create.Diff, topo.FieldDiff{Field: "database", New: "db1"})
+
+	update := events[1]
+	require.Equal(t, topo.AuditUpdate, update.Operation)
+	require.NotEmpty(t, update.Version)
+	require.NotEqual(t, create.Version, update.Version)
+	require.ElementsMatch(t, []topo.FieldDiff{
+		{Field: "hostname", Old: "host1", New: "host2"},
+		{Field: "serving_status", New: "NOT_SERVING"},
+	}, update.Diff)
+
+	del := events[2]
+	require.Equal(t, topo.AuditDelete, del.Operation)
+	require.Empty(t, del.Version)
+	require.Nil(t, del.New)
+	require.Contains(t, del.Diff, topo.FieldDiff{Field: "hostname", Old: "host2"})
+}
+
+func TestAuditNoExtraReads(t *testing.T) {
+	ctx := context.Background()
+	// reads returns the number of reads of the mutations, and the events
+	// they report to sink if not nil.
+	reads := func(sink topo.AuditSink) int64 {
+		_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+		var opts []topo.StoreOption
+		if sink != nil {
+			opts = append(opts, topo.WithAuditSink(sink))
+		}
+		ts, err := topo.NewWithFactory(factory, "", []string{""}, opts...)
+		require.NoError(t, err)
+		defer ts.Close()
+
+		mp := topo.NewMultiPooler("1", "zone1", "host1")
+		require.NoError(t, ts.CreateMultiPooler(ctx, mp))
+		gets := factory.GetCallStats().Counts()["Get"]
+		_, err = ts.UpdateMultiPoolerFields(ctx, mp.Id, func(mp *clustermetadatapb.MultiPooler) error {
+			mp.Hostname = "host2"
+			return nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, ts.DeleteMultiPooler(ctx, mp.Id))
+		return factory.GetCallStats().Counts()["Get"] - gets
+	}
+
+	// The old values come from the reads of the callers.
+	sink := &memoryAuditSink{}
+	require.Equal(t, reads(nil), reads(sink))
+	events := sink.Events()
+	require.Len(t, events, 3)
+	require.Equal(t, []topo.FieldDiff{{Field: "hostname", Old: "host1", New: "host2"}}, events[1].Diff)
+	require.Contains(t, events[2].Diff, topo.FieldDiff{Field: "hostname", Old: "host2"})
+}
+
+func TestAuditOldUnknown(t *testing.T) {
+	ctx := context.Background()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	sink := &memoryAuditSink{}
+	ts, err := topo.NewWithFactory(factory, "", []string{""}, topo.WithAuditSink(sink))
+	require.NoError(t, err)
+	defer ts.Close()
+	conn, err := ts.ConnForCell(ctx, "zone1")
+	require.NoError(t, err)
+
+	// Only the last versions of the records used most recently are kept.
+	var poolerPaths []string
+	for i := range topo.AuditSeenRecords + 1 {
+		mp := topo.NewMultiPooler(fmt.Sprint(i), "zone1", "host1")
+		require.NoError(t, ts.CreateMultiPooler(ctx, mp))
+		poolerPaths = append(poolerPaths, path.Join(topo.PoolersPath, topo.MultiPoolerIDString(mp.Id), topo.PoolerFile))
+	}
+	updated, err := proto.Marshal(topo.NewMultiPooler("0", "zone1", "host2"))
+	require.NoError(t, err)
+	_, err = conn.Update(ctx, poolerPaths[0], updated, nil)
+	require.NoError(t, err)
+	_, err = conn.Update(ctx, poolerPaths[len(poolerPaths)-1], updated, nil)
+	require.NoError(t, err)
+
+	// The memory topology doesn't support GetVersion, so the old version
+	// of the evicted record is unknown, and the diff has the new fields.
+	events := sink.Events()
+	require.Len(t, events, topo.AuditSeenRecords+3)
+	evicted, kept := events[len(events)-2], events[len(events)-1]
+	require.True(t, evicted.OldUnknown)
+	require.Nil(t, evicted.Old)
+	require.Contains(t, evicted.Diff, topo.FieldDiff{Field: "hostname", New: "host2"})
+	require.False(t, kept.OldUnknown)
+	require.NotNil(t, kept.Old)
+	require.Contains(t, kept.Diff, topo.FieldDiff{Field: "hostname", Old: "host1", New: "host2"})
+}
+
+func TestAuditRedactsEncryptedRecords(t *testing.T) {
+	ctx := context.Background()
+	kms, err := encryptedtopo.NewLocalKMS(filepath.Join(t.TempDir(), "keys.json"))
+	require.NoError(t, err)
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	auditFile := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := topo.NewFileAuditSink(auditFile)
+	require.NoError(t, err)
+	memorySink := &memoryAuditSink{}
+	ts, err := topo.NewWithFactory(encryptedtopo.NewFactory(factory, kms, nil), "", []string{""},
+		topo.WithAuditSink(teeAuditSink{sink, memorySink}))
+	require.NoError(t, err)
+	defer ts.Close()
+
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1", BackupLocation: "s3://secret-bucket"}))
+	require.NoError(t, ts.UpdateDatabaseFields(ctx, "db1", func(db *clustermetadatapb.Database) error {
+		db.BackupLocation = "s3://other-secret-bucket"
+		return nil
+	}))
+	require.NoError(t, sink.Close())
+
+	events := memorySink.Events()
+	require.Len(t, events, 2)
+	for _, ev := range events {
+		require.True(t, ev.Redacted)
+		require.Nil(t, ev.Old)
+		require.Nil(t, ev.New)
+	}
+	require.Equal(t, []topo.FieldDiff{
+		{Field: "backup_location", Old: mterrors.RedactedPlaceholder, New: mterrors.RedactedPlaceholder},
+	}, events[1].Diff)
+	data, err := os.ReadFile(auditFile)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "secret")
+}
+
+// teeAuditSink sends the audit events to all its sinks.
+type teeAuditSink []topo.AuditSink
+
+func (s teeAuditSink) Record(ctx context.Context, ev *topo.AuditEvent) {
+	for _, sink := range s {
+		sink.Record(ctx, ev)
+	}
+}
+
+func TestAuditGlobalAndLocks(t *testing.T) {
+	sink := &memoryAuditSink{}
+	ts := newAuditedServer(t, sink, "zone1")
+	ctx := context.Background()
+
+	// NewServerAndFactory created zone1 before the audited store existed.
+	require.Empty(t, sink.Events())
+
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1"}))
+	events := sink.Events()
+	require.Len(t, events, 1)
+	require.Equal(t, topo.GlobalCell, events[0].Cell)
+	require.Equal(t, topo.AuditCreate, events[0].Operation)
+	require.Nil(t, events[0].CallerID)
+
+	// Failed mutations are not reported.
+	require.Error(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1"}))
+	require.Len(t, sink.Events(), 1)
+
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	ld, err := conn.Lock(ctx, path.Join(topo.DatabasesPath, "db1"), "reparent")
+	require.NoError(t, err)
+	require.NoError(t, ld.Unlock(ctx))
+
+	events = sink.Events()
+	require.Len(t, events, 2)
+	require.Equal(t, topo.AuditLock, events[1].Operation)
+	require.Equal(t, path.Join(topo.DatabasesPath, "db1"), events[1].Path)
+	require.Equal(t, "reparent", events[1].LockContents)
+}
+
+func TestFileAuditSink(t *testing.T) {
+	auditFile := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := topo.NewFileAuditSink(auditFile)
+	require.NoError(t, err)
+	ts := newAuditedServer(t, sink, "zone1")
+	ctx := callerid.NewContext(context.Background(), callerid.NewCallerID("bob", "", ""))
+
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1"}))
+	require.NoError(t, ts.UpdateDatabaseFields(ctx, "db1", func(db *clustermetadatapb.Database) error {
+		db.Cells = []string{"zone1"}
+		return nil
+	}))
+	require.NoError(t, sink.Close())
+
+	data, err := os.ReadFile(auditFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	var record struct {
+		Operation string `json:"operation"`
+		Cell      string `json:"cell"`
+		Version   string `json:"version"`
+		Caller    struct {
+			Principal string `json:"principal"`
+		} `json:"caller"`
+		Diff []topo.FieldDiff `json:"diff"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	require.Equal(t, "update", record.Operation)
+	require.Equal(t, topo.GlobalCell, record.Cell)
+	require.NotEmpty(t, record.Version)
+	require.Equal(t, "bob", record.Caller.Principal)
+	require.Equal(t, []topo.FieldDiff{{Field: "cells", New: "[zone1]"}}, record.Diff)
+}
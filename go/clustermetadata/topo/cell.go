@@ -23,8 +23,6 @@ import (
 
 	"github.com/multigres/multigres/go/mterrors"
 	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
-
-	"google.golang.org/protobuf/proto"
 )
 
 // This file provides the utility methods to save / retrieve Cell
@@ -74,7 +72,7 @@ func (ts *store) GetCell(ctx context.Context, cell string) (*clustermetadatapb.C
 
 	// Unpack the contents.
 	ci := &clustermetadatapb.Cell{}
-	if err := proto.Unmarshal(contents, ci); err != nil {
+	if err := UnmarshalRecord(contents, ci); err != nil {
 		return nil, err
 	}
 	return ci, nil
@@ -86,7 +84,7 @@ func (ts *store) CreateCell(ctx context.Context, cell string, ci *clustermetadat
 		return ctx.Err()
 	}
 	// Pack the content.
-	contents, err := proto.Marshal(ci)
+	contents, err := marshalRecord(ts.codec, ci)
 	if err != nil {
 		return err
 	}
@@ -115,7 +113,7 @@ func (ts *store) UpdateCellFields(ctx context.Context, cell string, update func(
 		contents, version, err := ts.globalTopo.Get(ctx, filePath)
 		switch {
 		case err == nil:
-			if err := proto.Unmarshal(contents, ci); err != nil {
+			if err := UnmarshalRecord(contents, ci); err != nil {
 				return err
 			}
 		case errors.Is(err, &TopoError{Code: NoNode}):
@@ -133,7 +131,7 @@ func (ts *store) UpdateCellFields(ctx context.Context, cell string, update func(
 		}
 
 		// Pack and save.
-		contents, err = proto.Marshal(ci)
+		contents, err = marshalRecord(ts.codec, ci)
 		if err != nil {
 			return err
 		}
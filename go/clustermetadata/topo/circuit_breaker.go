@@ -0,0 +1,364 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// This file implements a per-cell circuit breaker for cell topology
+// connections. When a cell topology server is down, every call against it
+// blocks until its context expires, which slows down any loop that visits
+// all the cells. The breaker trips after a number of consecutive failures
+// and fails fast with UNAVAILABLE while open. Once the open timeout has
+// elapsed, a single probe call is let through (half-open state): if it
+// succeeds the breaker closes again, otherwise it re-opens.
+//
+// Only the errors of the server count as failures, including its own
+// timeouts: a call whose context expired says nothing about the server.
+
+var (
+	// DefaultCircuitBreakerFailureThreshold is the number of consecutive
+	// failures against a cell topology server before its breaker opens.
+	DefaultCircuitBreakerFailureThreshold = 5
+
+	// DefaultCircuitBreakerOpenTimeout is how long a breaker stays open
+	// before letting a probe call through.
+	DefaultCircuitBreakerOpenTimeout = 10 * time.Second
+)
+
+// CircuitBreakerState is the state of a cell circuit breaker.
+type CircuitBreakerState int
+
+const (
+	// CircuitClosed means calls to the cell go through normally.
+	CircuitClosed CircuitBreakerState = iota
+
+	// CircuitOpen means calls to the cell fail fast with UNAVAILABLE.
+	CircuitOpen
+
+	// CircuitHalfOpen means a single probe call is allowed through to
+	// decide whether the breaker should close again.
+	CircuitHalfOpen
+)
+
+// String returns a text representation of the breaker state.
+func (s CircuitBreakerState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreakerStatus is a snapshot of a cell circuit breaker,
+// meant to be reported to operators.
+type CircuitBreakerStatus struct {
+	// Cell is the name of the cell the breaker protects.
+	Cell string
+
+	// State is the current state of the breaker.
+	State CircuitBreakerState
+
+	// ConsecutiveFailures is the number of failures observed since
+	// the last successful call.
+	ConsecutiveFailures int
+
+	// LastError is the last failure observed, if any.
+	LastError error
+
+	// OpenedAt is the last time the breaker opened. It is the zero
+	// time if the breaker never opened.
+	OpenedAt time.Time
+}
+
+// circuitBreaker tracks the health of a single cell topology server.
+type circuitBreaker struct {
+	cell        string
+	threshold   int
+	openTimeout time.Duration
+	now         func() time.Time
+
+	// mu protects the following fields.
+	mu                  sync.Mutex
+	state               CircuitBreakerState
+	consecutiveFailures int
+	lastError           error
+	openedAt            time.Time
+	probeInFlight       bool
+}
+
+func newCircuitBreaker(cell string) *circuitBreaker {
+	return &circuitBreaker{
+		cell:        cell,
+		threshold:   DefaultCircuitBreakerFailureThreshold,
+		openTimeout: DefaultCircuitBreakerOpenTimeout,
+		now:         time.Now,
+	}
+}
+
+// ready returns nil if the cell may be used, that is if the breaker is
+// closed, half-open, or open with an expired open timeout. It does not
+// consume the half-open probe.
+func (cb *circuitBreaker) ready() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) < cb.openTimeout {
+		return cb.openError()
+	}
+	return nil
+}
+
+// allow returns nil if a call may proceed. In half-open state only one
+// probe call is allowed at a time; every allowed call must be followed
+// by a call to record.
+func (cb *circuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case CircuitClosed:
+		return nil
+	case CircuitOpen:
+		if cb.now().Sub(cb.openedAt) < cb.openTimeout {
+			return cb.openError()
+		}
+		cb.state = CircuitHalfOpen
+	}
+	// Half-open: let a single probe through.
+	if cb.probeInFlight {
+		return cb.openError()
+	}
+	cb.probeInFlight = true
+	return nil
+}
+
+// record updates the breaker with the outcome of a call allowed by allow,
+// made with ctx.
+func (cb *circuitBreaker) record(ctx context.Context, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if cb.state == CircuitHalfOpen {
+		cb.probeInFlight = false
+	}
+	if !isCircuitBreakerFailure(ctx, err) {
+		if err != nil && ctx.Err() != nil {
+			// The caller gave up, we learned nothing about the server.
+			return
+		}
+		cb.state = CircuitClosed
+		cb.consecutiveFailures = 0
+		cb.lastError = nil
+		return
+	}
+
+	cb.consecutiveFailures++
+	cb.lastError = err
+	if cb.state == CircuitHalfOpen || cb.consecutiveFailures >= cb.threshold {
+		cb.state = CircuitOpen
+		cb.openedAt = cb.now()
+	}
+}
+
+// status returns a snapshot of the breaker.
+func (cb *circuitBreaker) status() CircuitBreakerStatus {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	return CircuitBreakerStatus{
+		Cell:                cb.cell,
+		State:               cb.state,
+		ConsecutiveFailures: cb.consecutiveFailures,
+		LastError:           cb.lastError,
+		OpenedAt:            cb.openedAt,
+	}
+}
+
+// openError returns the error reported while the breaker rejects calls.
+// cb.mu must be held.
+func (cb *circuitBreaker) openError() error {
+	return mterrors.Errorf(mtrpcpb.Code_UNAVAILABLE, "topo circuit breaker is open for cell %v after %v consecutive failures: %v", cb.cell, cb.consecutiveFailures, mterrors.Sensitive(cb.lastError))
+}
+
+// isCircuitBreakerFailure returns true if err indicates the topology
+// server is unhealthy. Errors that are part of the normal topo API
+// contract (missing node, bad version, ...) mean the server answered,
+// and don't count as failures. Neither do the errors of a call whose
+// context is canceled or expired: the caller gave up, possibly with a
+// deadline too short for a healthy server.
+func isCircuitBreakerFailure(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var topoErr TopoError
+	if errors.As(err, &topoErr) {
+		return topoErr.Code == Timeout || topoErr.Code == ResourceExhausted
+	}
+	return true
+}
+
+// circuitBreakerConn wraps a cell Conn and reports the outcome of every
+// call to the cell circuit breaker.
+type circuitBreakerConn struct {
+	Conn
+	cb *circuitBreaker
+}
+
+var _ Conn = (*circuitBreakerConn)(nil)
+
+func newCircuitBreakerConn(conn Conn, cb *circuitBreaker) Conn {
+	return &circuitBreakerConn{Conn: conn, cb: cb}
+}
+
+// ListDir is part of the Conn interface.
+func (c *circuitBreakerConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	entries, err := c.Conn.ListDir(ctx, dirPath, full)
+	c.cb.record(ctx, err)
+	return entries, err
+}
+
+// Create is part of the Conn interface.
+func (c *circuitBreakerConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	version, err := c.Conn.Create(ctx, filePath, contents)
+	c.cb.record(ctx, err)
+	return version, err
+}
+
+// Update is part of the Conn interface.
+func (c *circuitBreakerConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	newVersion, err := c.Conn.Update(ctx, filePath, contents, version)
+	c.cb.record(ctx, err)
+	return newVersion, err
+}
+
+// Get is part of the Conn interface.
+func (c *circuitBreakerConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, nil, err
+	}
+	contents, version, err := c.Conn.Get(ctx, filePath)
+	c.cb.record(ctx, err)
+	return contents, version, err
+}
+
+// GetVersion is part of the Conn interface.
+func (c *circuitBreakerConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	contents, err := c.Conn.GetVersion(ctx, filePath, version)
+	c.cb.record(ctx, err)
+	return contents, err
+}
+
+// List is part of the Conn interface.
+func (c *circuitBreakerConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	kvs, err := c.Conn.List(ctx, filePathPrefix)
+	c.cb.record(ctx, err)
+	return kvs, err
+}
+
+// Delete is part of the Conn interface.
+func (c *circuitBreakerConn) Delete(ctx context.Context, filePath string, version Version) error {
+	if err := c.cb.allow(); err != nil {
+		return err
+	}
+	err := c.Conn.Delete(ctx, filePath, version)
+	c.cb.record(ctx, err)
+	return err
+}
+
+// Lock is part of the Conn interface.
+func (c *circuitBreakerConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	ld, err := c.Conn.Lock(ctx, dirPath, contents)
+	c.cb.record(ctx, err)
+	return ld, err
+}
+
+// LockWithTTL is part of the Conn interface.
+func (c *circuitBreakerConn) LockWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	ld, err := c.Conn.LockWithTTL(ctx, dirPath, contents, ttl)
+	c.cb.record(ctx, err)
+	return ld, err
+}
+
+// LockName is part of the Conn interface.
+func (c *circuitBreakerConn) LockName(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	ld, err := c.Conn.LockName(ctx, dirPath, contents)
+	c.cb.record(ctx, err)
+	return ld, err
+}
+
+// TryLock is part of the Conn interface.
+func (c *circuitBreakerConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, err
+	}
+	ld, err := c.Conn.TryLock(ctx, dirPath, contents)
+	c.cb.record(ctx, err)
+	return ld, err
+}
+
+// Watch is part of the Conn interface. Only the initial read is
+// reported to the breaker.
+func (c *circuitBreakerConn) Watch(ctx context.Context, filePath string) (*WatchData, <-chan *WatchData, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, nil, err
+	}
+	current, changes, err := c.Conn.Watch(ctx, filePath)
+	c.cb.record(ctx, err)
+	return current, changes, err
+}
+
+// WatchRecursive is part of the Conn interface. Only the initial read
+// is reported to the breaker.
+func (c *circuitBreakerConn) WatchRecursive(ctx context.Context, path string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
+	if err := c.cb.allow(); err != nil {
+		return nil, nil, err
+	}
+	current, changes, err := c.Conn.WatchRecursive(ctx, path)
+	c.cb.record(ctx, err)
+	return current, changes, err
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+)
+
+// setCircuitBreakerDefaults overrides the breaker defaults for the
+// duration of the test.
+func setCircuitBreakerDefaults(t *testing.T, threshold int, openTimeout time.Duration) {
+	t.Helper()
+	oldThreshold := topo.DefaultCircuitBreakerFailureThreshold
+	oldOpenTimeout := topo.DefaultCircuitBreakerOpenTimeout
+	topo.DefaultCircuitBreakerFailureThreshold = threshold
+	topo.DefaultCircuitBreakerOpenTimeout = openTimeout
+	t.Cleanup(func() {
+		topo.DefaultCircuitBreakerFailureThreshold = oldThreshold
+		topo.DefaultCircuitBreakerOpenTimeout = oldOpenTimeout
+	})
+}
+
+func breakerStatusForCell(t *testing.T, ts topo.Store, cell string) topo.CircuitBreakerStatus {
+	t.Helper()
+	for _, status := range ts.CircuitBreakerStatuses() {
+		if status.Cell == cell {
+			return status
+		}
+	}
+	require.Failf(t, "no circuit breaker status", "cell %v", cell)
+	return topo.CircuitBreakerStatus{}
+}
+
+func TestCircuitBreakerTripsAfterConsecutiveFailures(t *testing.T) {
+	setCircuitBreakerDefaults(t, 3, time.Hour)
+	ctx := context.Background()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1", "zone2")
+	defer ts.Close()
+
+	id := &clustermetadatapb.ID{Component: clustermetadatapb.ID_MULTIPOOLER, Cell: "zone1", Name: "1"}
+	factory.AddOperationError(memorytopo.Get, "poolers/", topo.NewError(topo.Timeout, "poolers"))
+
+	for i := range 3 {
+		_, err := ts.GetMultiPooler(ctx, id)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, &topo.TopoError{Code: topo.Timeout}), "call %d: %v", i, err)
+	}
+
+	status := breakerStatusForCell(t, ts, "zone1")
+	require.Equal(t, topo.CircuitOpen, status.State)
+	require.Equal(t, 3, status.ConsecutiveFailures)
+	require.Error(t, status.LastError)
+	require.False(t, status.OpenedAt.IsZero())
+
+	// While open, the cell fails fast with UNAVAILABLE.
+	_, err := ts.ConnForCell(ctx, "zone1")
+	require.Error(t, err)
+	require.Equal(t, mtrpcpb.Code_UNAVAILABLE, mterrors.Code(err))
+	_, err = ts.GetMultiPooler(ctx, id)
+	require.Equal(t, mtrpcpb.Code_UNAVAILABLE, mterrors.Code(err))
+
+	// Other cells are not affected.
+	_, err = ts.ConnForCell(ctx, "zone2")
+	require.NoError(t, err)
+	require.Equal(t, topo.CircuitClosed, breakerStatusForCell(t, ts, "zone2").State)
+}
+
+func TestCircuitBreakerIgnoresLogicalErrors(t *testing.T) {
+	setCircuitBreakerDefaults(t, 2, time.Hour)
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	id := &clustermetadatapb.ID{Component: clustermetadatapb.ID_MULTIPOOLER, Cell: "zone1", Name: "missing"}
+	for range 5 {
+		_, err := ts.GetMultiPooler(ctx, id)
+		require.True(t, errors.Is(err, &topo.TopoError{Code: topo.NoNode}))
+	}
+
+	status := breakerStatusForCell(t, ts, "zone1")
+	require.Equal(t, topo.CircuitClosed, status.State)
+	require.Zero(t, status.ConsecutiveFailures)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	setCircuitBreakerDefaults(t, 1, 50*time.Millisecond)
+	ctx := context.Background()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	mp := topo.NewMultiPooler("1", "zone1", "host1")
+	require.NoError(t, ts.CreateMultiPooler(ctx, mp))
+
+	// Trip the breaker.
+	factory.AddOneTimeOperationError(memorytopo.Get, "poolers/", topo.NewError(topo.Timeout, "poolers"))
+	_, err := ts.GetMultiPooler(ctx, mp.Id)
+	require.True(t, errors.Is(err, &topo.TopoError{Code: topo.Timeout}))
+	require.Equal(t, topo.CircuitOpen, breakerStatusForCell(t, ts, "zone1").State)
+
+	// A failed probe re-opens the breaker.
+	time.Sleep(60 * time.Millisecond)
+	factory.AddOneTimeOperationError(memorytopo.Get, "poolers/", topo.NewError(topo.Timeout, "poolers"))
+	_, err = ts.GetMultiPooler(ctx, mp.Id)
+	require.True(t, errors.Is(err, &topo.TopoError{Code: topo.Timeout}))
+	require.Equal(t, topo.CircuitOpen, breakerStatusForCell(t, ts, "zone1").State)
+	_, err = ts.GetMultiPooler(ctx, mp.Id)
+	require.Equal(t, mtrpcpb.Code_UNAVAILABLE, mterrors.Code(err))
+
+	// A successful probe closes it.
+	time.Sleep(60 * time.Millisecond)
+	_, err = ts.GetMultiPooler(ctx, mp.Id)
+	require.NoError(t, err)
+	status := breakerStatusForCell(t, ts, "zone1")
+	require.Equal(t, topo.CircuitClosed, status.State)
+	require.Zero(t, status.ConsecutiveFailures)
+}
+
+func TestCircuitBreakerIgnoresCallerDeadlines(t *testing.T) {
+	setCircuitBreakerDefaults(t, 2, time.Hour)
+	ctx := context.Background()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	require.NoError(t, ts.UpdateCellFields(ctx, "zone1", func(ci *clustermetadatapb.Cell) error {
+		ci.ServerAddresses = []string{memorytopo.UnreachableServerAddr}
+		return nil
+	}))
+
+	// Callers with short deadlines don't trip the breaker: the server
+	// might just be slower than they are patient.
+	for range 3 {
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		_, err := ts.GetMultiPoolerIDsByCell(ctx, "zone1")
+		cancel()
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	}
+	require.Equal(t, topo.CircuitClosed, breakerStatusForCell(t, ts, "zone1").State)
+
+	// The timeouts of the server itself do.
+	require.NoError(t, ts.UpdateCellFields(ctx, "zone1", func(ci *clustermetadatapb.Cell) error {
+		ci.ServerAddresses = nil
+		return nil
+	}))
+	factory.AddOperationError(memorytopo.List, "poolers", context.DeadlineExceeded)
+	for range 2 {
+		_, err := ts.GetMultiPoolerIDsByCell(ctx, "zone1")
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+	}
+	start := time.Now()
+	_, err := ts.GetMultiPoolerIDsByCell(ctx, "zone1")
+	require.Equal(t, mtrpcpb.Code_UNAVAILABLE, mterrors.Code(err))
+	require.Less(t, time.Since(start), time.Second)
+}
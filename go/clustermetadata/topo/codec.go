@@ -0,0 +1,227 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// Codec encodes the records stored in the topology. The store writes
+// records with its codec, and reads records written with any codec:
+// the encoding is detected from the stored bytes.
+type Codec interface {
+	// Name returns the name of the encoding, as used in flags.
+	Name() string
+
+	// Marshal encodes record.
+	Marshal(record proto.Message) ([]byte, error)
+
+	// Unmarshal decodes data, encoded by this codec, into record.
+	Unmarshal(data []byte, record proto.Message) error
+}
+
+var (
+	// ProtoCodec stores records in the binary protobuf wire format.
+	// It is the default codec.
+	ProtoCodec Codec = protoCodec{}
+
+	// JSONCodec stores records as indented protojson, which is easier
+	// to inspect and debug directly in the topology server.
+	JSONCodec Codec = jsonCodec{}
+
+	// codecs contains the codecs that can be selected by name.
+	codecs = map[string]Codec{
+		ProtoCodec.Name(): ProtoCodec,
+		JSONCodec.Name():  JSONCodec,
+	}
+
+	// topoRecordEncoding is the name of the codec used by Open.
+	topoRecordEncoding = ProtoCodec.Name()
+)
+
+// CodecByName returns the codec with the given name, "proto" or "json".
+func CodecByName(name string) (Codec, error) {
+	codec, ok := codecs[name]
+	if !ok {
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "unknown topo record encoding %q", name)
+	}
+	return codec, nil
+}
+
+// WithCodec sets the codec used by the store to write records. Records
+// written with another codec remain readable, and can be rewritten with
+// ReencodeRecords.
+func WithCodec(codec Codec) StoreOption {
+	return func(ts *store) {
+		ts.codec = codec
+	}
+}
+
+type protoCodec struct{}
+
+func (protoCodec) Name() string {
+	return "proto"
+}
+
+func (protoCodec) Marshal(record proto.Message) ([]byte, error) {
+	return proto.Marshal(record)
+}
+
+func (protoCodec) Unmarshal(data []byte, record proto.Message) error {
+	return proto.Unmarshal(data, record)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string {
+	return "json"
+}
+
+func (jsonCodec) Marshal(record proto.Message) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	// protojson randomly adds whitespace to prevent byte comparisons
+	// of its output. Compact it, then indent it, so the same record is
+	// always stored with the same bytes.
+	var compact, indented bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return nil, err
+	}
+	if err := json.Indent(&indented, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
+
+func (jsonCodec) Unmarshal(data []byte, record proto.Message) error {
+	// Unknown fields are discarded, so that records written by a newer
+	// binary can be read by an older one, as with the binary format.
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, record)
+}
+
+// codecOf returns the codec data was encoded with. JSON records start
+// with '{', JSONCodec never writes leading whitespace. A binary record
+// can't: 0x7b would be the tag of field 15 with the deprecated group wire
+// type, which no record uses. Whitespace can't be skipped: a binary record
+// starts with the tag of field 1 as bytes, 0x0a, which is '\n'.
+func codecOf(data []byte) Codec {
+	if len(data) > 0 && data[0] == '{' {
+		return JSONCodec
+	}
+	return ProtoCodec
+}
+
+// decodeRecord decodes data, written by any codec, into record, without
+// upgrading it. It returns the codec that was detected.
+func decodeRecord(data []byte, record proto.Message) (Codec, error) {
+	codec := codecOf(data)
+	if err := codec.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+	return codec, nil
+}
+
+// UnmarshalRecord decodes a record read from the topology, whatever its
+// encoding, and upgrades it to the current format version. It is meant
+// for callers reading records directly from a Conn, for instance through
+// Watch.
+func UnmarshalRecord(data []byte, record proto.Message) error {
+	if _, err := decodeRecord(data, record); err != nil {
+		return err
+	}
+	return upgradeRecord(record)
+}
+
+// ReencodeRecords rewrites, in the given cell, every record that is not
+// stored with codec, upgrading it to the current format version along the
+// way. It uses versioned updates: a record concurrently modified is
+// skipped, as it was rewritten by its writer. It returns the number of
+// records rewritten.
+func ReencodeRecords(ctx context.Context, cp ConnProvider, cell string, codec Codec) (int, error) {
+	return rewriteRecords(ctx, cp, cell, func(record proto.Message, stored Codec) Codec {
+		if stored.Name() == codec.Name() {
+			return nil
+		}
+		return codec
+	})
+}
+
+// rewriteRecords rewrites the records of a cell for which rewrite returns
+// a codec, using that codec. rewrite receives the stored record before it
+// is upgraded, and the codec it was stored with. The records written with
+// a newer format version are skipped.
+func rewriteRecords(ctx context.Context, cp ConnProvider, cell string, rewrite func(record proto.Message, stored Codec) Codec) (int, error) {
+	conn, err := cp.ConnForCell(ctx, cell)
+	if err != nil {
+		return 0, err
+	}
+
+	rewritten := 0
+	for _, dirPath := range recordPathsForCell(cell) {
+		kvs, err := conn.List(ctx, dirPath+"/")
+		if errors.Is(err, &TopoError{Code: NoNode}) {
+			continue
+		}
+		if err != nil {
+			return rewritten, err
+		}
+		for _, kv := range kvs {
+			filePath := string(kv.Key)
+			record := newRecordForPath(filePath)
+			if record == nil {
+				continue
+			}
+			stored, err := decodeRecord(kv.Value, record)
+			if err != nil {
+				return rewritten, mterrors.Wrapf(err, "failed to unmarshal %v", filePath)
+			}
+			if RecordVersion(record) > CurrentRecordVersion(record) {
+				// Written by a newer binary, which will rewrite it.
+				continue
+			}
+			codec := rewrite(record, stored)
+			if codec == nil {
+				continue
+			}
+			if err := upgradeRecord(record); err != nil {
+				return rewritten, err
+			}
+			data, err := marshalRecord(codec, record)
+			if err != nil {
+				return rewritten, err
+			}
+			if _, err := conn.Update(ctx, filePath, data, kv.Version); err != nil {
+				if errors.Is(err, &TopoError{Code: BadVersion}) {
+					continue
+				}
+				return rewritten, mterrors.Wrapf(err, "failed to rewrite %v", filePath)
+			}
+			rewritten++
+		}
+	}
+	return rewritten, nil
+}
@@ -0,0 +1,189 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/test"
+)
+
+// newCodecServers returns two stores sharing the same memory topology,
+// one writing binary records and one writing JSON records, along with
+// a raw Conn to the global topology.
+func newCodecServers(t *testing.T) (binaryTS, jsonTS topo.Store, raw topo.Conn) {
+	t.Helper()
+	ctx := context.Background()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	binaryTS, err := topo.NewWithFactory(factory, "", []string{""})
+	require.NoError(t, err)
+	jsonTS, err = topo.NewWithFactory(factory, "", []string{""}, topo.WithCodec(topo.JSONCodec))
+	require.NoError(t, err)
+	raw, err = factory.Create(topo.GlobalCell, "", []string{""})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		binaryTS.Close()
+		jsonTS.Close()
+		raw.Close()
+	})
+	return binaryTS, jsonTS, raw
+}
+
+func TestJSONCodecTopo(t *testing.T) {
+	// Run the TopoServerTestSuite tests with JSON records.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	test.TopoServerTestSuite(t, ctx, func() topo.Store {
+		_, factory := memorytopo.NewServerAndFactory(ctx, test.LocalCellName)
+		ts, err := topo.NewWithFactory(factory, "", []string{""}, topo.WithCodec(topo.JSONCodec))
+		require.NoError(t, err)
+		return ts
+	})
+}
+
+func TestJSONCodecRecords(t *testing.T) {
+	ctx := context.Background()
+	_, jsonTS, raw := newCodecServers(t)
+
+	db := &clustermetadatapb.Database{
+		Name:           "db1",
+		BackupLocation: "s3://bucket/backups",
+		Cells:          []string{"zone1"},
+	}
+	require.NoError(t, jsonTS.CreateDatabase(ctx, "db1", db))
+
+	// The stored record is human-readable JSON using the proto names.
+	contents, _, err := raw.Get(ctx, "databases/db1/Database")
+	require.NoError(t, err)
+	var fields map[string]any
+	require.NoError(t, json.Unmarshal(contents, &fields))
+	require.Equal(t, "db1", fields["name"])
+	require.Equal(t, "s3://bucket/backups", fields["backup_location"])
+
+	got, err := jsonTS.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(db, got))
+
+	// Encoding is deterministic.
+	again, err := topo.JSONCodec.Marshal(db)
+	require.NoError(t, err)
+	require.Equal(t, again, contents)
+}
+
+func TestCodecMixedReads(t *testing.T) {
+	ctx := context.Background()
+	binaryTS, jsonTS, _ := newCodecServers(t)
+
+	require.NoError(t, binaryTS.CreateDatabase(ctx, "binary", &clustermetadatapb.Database{Name: "binary"}))
+	require.NoError(t, jsonTS.CreateDatabase(ctx, "json", &clustermetadatapb.Database{Name: "json"}))
+
+	// Both stores read both encodings.
+	for _, ts := range []topo.Store{binaryTS, jsonTS} {
+		for _, name := range []string{"binary", "json"} {
+			db, err := ts.GetDatabase(ctx, name)
+			require.NoError(t, err)
+			require.Equal(t, name, db.Name)
+		}
+	}
+
+	// An update rewrites the record with the codec of the store.
+	require.NoError(t, jsonTS.UpdateDatabaseFields(ctx, "binary", func(db *clustermetadatapb.Database) error {
+		db.Cells = []string{"zone1"}
+		return nil
+	}))
+	db, err := binaryTS.GetDatabase(ctx, "binary")
+	require.NoError(t, err)
+	require.Equal(t, []string{"zone1"}, db.Cells)
+}
+
+func TestCodecDetection(t *testing.T) {
+	// A binary record whose first field is 123 bytes long starts with
+	// "\n{", like indented JSON.
+	db := &clustermetadatapb.Database{Name: strings.Repeat("a", 123)}
+	data, err := proto.Marshal(db)
+	require.NoError(t, err)
+	require.Equal(t, []byte("\n{"), data[:2])
+	got := &clustermetadatapb.Database{}
+	require.NoError(t, topo.UnmarshalRecord(data, got))
+	require.True(t, proto.Equal(db, got))
+
+	data, err = topo.JSONCodec.Marshal(db)
+	require.NoError(t, err)
+	got = &clustermetadatapb.Database{}
+	require.NoError(t, topo.UnmarshalRecord(data, got))
+	require.True(t, proto.Equal(db, got))
+}
+
+func TestReencodeRecords(t *testing.T) {
+	ctx := context.Background()
+	binaryTS, jsonTS, raw := newCodecServers(t)
+
+	for _, name := range []string{"db1", "db2"} {
+		require.NoError(t, binaryTS.CreateDatabase(ctx, name, &clustermetadatapb.Database{Name: name}))
+	}
+	require.NoError(t, jsonTS.CreateDatabase(ctx, "db3", &clustermetadatapb.Database{Name: "db3"}))
+	mp := topo.NewMultiPooler("1", "zone1", "host1")
+	require.NoError(t, binaryTS.CreateMultiPooler(ctx, mp))
+
+	// Migrate the global topology to JSON: zone1 and the two binary
+	// databases are rewritten.
+	n, err := topo.ReencodeRecords(ctx, jsonTS, topo.GlobalCell, topo.JSONCodec)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	n, err = topo.ReencodeRecords(ctx, jsonTS, topo.GlobalCell, topo.JSONCodec)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	for _, name := range []string{"db1", "db2", "db3"} {
+		contents, _, err := raw.Get(ctx, "databases/"+name+"/Database")
+		require.NoError(t, err)
+		require.True(t, json.Valid(contents), "%v is not stored as JSON", name)
+	}
+
+	// And back to binary. Cell topologies are migrated separately.
+	n, err = topo.ReencodeRecords(ctx, binaryTS, topo.GlobalCell, topo.ProtoCodec)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+	n, err = topo.ReencodeRecords(ctx, binaryTS, "zone1", topo.JSONCodec)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	got, err := binaryTS.GetMultiPooler(ctx, mp.Id)
+	require.NoError(t, err)
+	require.Equal(t, "host1", got.Hostname)
+	db, err := jsonTS.GetDatabase(ctx, "db3")
+	require.NoError(t, err)
+	require.Equal(t, "db3", db.Name)
+}
+
+func TestCodecByName(t *testing.T) {
+	for _, codec := range []topo.Codec{topo.ProtoCodec, topo.JSONCodec} {
+		got, err := topo.CodecByName(codec.Name())
+		require.NoError(t, err)
+		require.Equal(t, codec, got)
+	}
+	_, err := topo.CodecByName("yaml")
+	require.Error(t, err)
+}
@@ -20,8 +20,6 @@ import (
 	"path"
 
 	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
-
-	"google.golang.org/protobuf/proto"
 )
 
 // This file provides the utility methods to save / retrieve Database
@@ -65,7 +63,7 @@ func (ts *store) GetDatabase(ctx context.Context, database string) (*clustermeta
 
 	// Unpack the contents.
 	db := &clustermetadatapb.Database{}
-	if err := proto.Unmarshal(contents, db); err != nil {
+	if err := UnmarshalRecord(contents, db); err != nil {
 		return nil, err
 	}
 	return db, nil
@@ -77,7 +75,7 @@ func (ts *store) CreateDatabase(ctx context.Context, database string, db *cluste
 		return ctx.Err()
 	}
 	// Pack the content.
-	contents, err := proto.Marshal(db)
+	contents, err := marshalRecord(ts.codec, db)
 	if err != nil {
 		return err
 	}
@@ -106,7 +104,7 @@ func (ts *store) UpdateDatabaseFields(ctx context.Context, database string, upda
 		contents, version, err := ts.globalTopo.Get(ctx, filePath)
 		switch {
 		case err == nil:
-			if err := proto.Unmarshal(contents, db); err != nil {
+			if err := UnmarshalRecord(contents, db); err != nil {
 				return err
 			}
 		case errors.Is(err, &TopoError{Code: NoNode}):
@@ -124,7 +122,7 @@ func (ts *store) UpdateDatabaseFields(ctx context.Context, database string, upda
 		}
 
 		// Pack and save.
-		contents, err = proto.Marshal(db)
+		contents, err = marshalRecord(ts.codec, db)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,320 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encryptedtopo contains a topo.Factory / topo.Conn wrapper that
+// transparently encrypts the records stored at configured paths.
+//
+// Records are protected with envelope encryption: every write generates a
+// fresh data-encryption key, which encrypts the record with AES-GCM, and is
+// itself wrapped by a key-encryption key owned by a KMS. Only the wrapped
+// key and the ID of the key-encryption key are stored next to the record.
+//
+// Versions are those of the underlying Conn, so versioned updates and
+// watches behave exactly as without encryption. Reads transparently return
+// plaintext records, which allows turning encryption on for an existing
+// topology. After rotating the KMS key, Conn.ReEncrypt rewrites the
+// existing records with the new key.
+package encryptedtopo
+
+import (
+	"context"
+	"errors"
+	"path"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+)
+
+// DefaultPaths are the path patterns encrypted when none are provided.
+// Database records hold the backup location, and will hold credentials.
+var DefaultPaths = []string{
+	path.Join(topo.DatabasesPath, "*", topo.DatabaseFile),
+}
+
+// Factory wraps a topo.Factory so that all the Conn it creates encrypt
+// the records matching its paths.
+type Factory struct {
+	factory topo.Factory
+	kms     KMS
+	paths   []string
+}
+
+var _ topo.EncryptingFactory = (*Factory)(nil)
+
+// NewFactory returns a Factory wrapping factory. paths are path.Match
+// patterns relative to the cell root; if empty, DefaultPaths is used.
+func NewFactory(factory topo.Factory, kms KMS, paths []string) *Factory {
+	if len(paths) == 0 {
+		paths = DefaultPaths
+	}
+	return &Factory{
+		factory: factory,
+		kms:     kms,
+		paths:   paths,
+	}
+}
+
+// Create is part of the topo.Factory interface.
+func (f *Factory) Create(cell, root string, serverAddrs []string) (topo.Conn, error) {
+	conn, err := f.factory.Create(cell, root, serverAddrs)
+	if err != nil {
+		return nil, err
+	}
+	return NewConn(conn, f.kms, f.paths), nil
+}
+
+// Encrypts is part of the topo.EncryptingFactory interface.
+func (f *Factory) Encrypts(filePath string) bool {
+	return matchesAny(f.paths, filePath)
+}
+
+// Conn is a topo.Conn that encrypts the records matching its paths
+// before handing them to the wrapped Conn, and decrypts all the
+// encrypted records it reads.
+type Conn struct {
+	topo.Conn
+	kms   KMS
+	paths []string
+}
+
+var _ topo.Conn = (*Conn)(nil)
+
+// NewConn returns a Conn wrapping conn. paths are path.Match patterns
+// relative to the cell root; if empty, DefaultPaths is used.
+func NewConn(conn topo.Conn, kms KMS, paths []string) *Conn {
+	if len(paths) == 0 {
+		paths = DefaultPaths
+	}
+	return &Conn{
+		Conn:  conn,
+		kms:   kms,
+		paths: paths,
+	}
+}
+
+// shouldEncrypt returns true if filePath matches one of the paths.
+func (c *Conn) shouldEncrypt(filePath string) bool {
+	return matchesAny(c.paths, filePath)
+}
+
+// matchesAny returns true if filePath matches one of the patterns.
+func matchesAny(patterns []string, filePath string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, filePath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// encode encrypts contents if filePath is configured to be encrypted.
+func (c *Conn) encode(filePath string, contents []byte) ([]byte, error) {
+	if !c.shouldEncrypt(filePath) {
+		return contents, nil
+	}
+	return encrypt(c.kms, filePath, contents)
+}
+
+// Create is part of the topo.Conn interface.
+func (c *Conn) Create(ctx context.Context, filePath string, contents []byte) (topo.Version, error) {
+	encoded, err := c.encode(filePath, contents)
+	if err != nil {
+		return nil, err
+	}
+	return c.Conn.Create(ctx, filePath, encoded)
+}
+
+// Update is part of the topo.Conn interface.
+func (c *Conn) Update(ctx context.Context, filePath string, contents []byte, version topo.Version) (topo.Version, error) {
+	encoded, err := c.encode(filePath, contents)
+	if err != nil {
+		return nil, err
+	}
+	return c.Conn.Update(ctx, filePath, encoded, version)
+}
+
+// Get is part of the topo.Conn interface.
+func (c *Conn) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
+	contents, version, err := c.Conn.Get(ctx, filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	contents, err = decrypt(c.kms, filePath, contents)
+	if err != nil {
+		return nil, nil, err
+	}
+	return contents, version, nil
+}
+
+// GetVersion is part of the topo.Conn interface.
+func (c *Conn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	contents, err := c.Conn.GetVersion(ctx, filePath, version)
+	if err != nil {
+		return nil, err
+	}
+	return decrypt(c.kms, filePath, contents)
+}
+
+// List is part of the topo.Conn interface.
+func (c *Conn) List(ctx context.Context, filePathPrefix string) ([]topo.KVInfo, error) {
+	kvs, err := c.Conn.List(ctx, filePathPrefix)
+	if err != nil {
+		return kvs, err
+	}
+	for i := range kvs {
+		value, err := decrypt(c.kms, string(kvs[i].Key), kvs[i].Value)
+		if err != nil {
+			return nil, err
+		}
+		kvs[i].Value = value
+	}
+	return kvs, nil
+}
+
+// Watch is part of the topo.Conn interface.
+func (c *Conn) Watch(ctx context.Context, filePath string) (*topo.WatchData, <-chan *topo.WatchData, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	current, changes, err := c.Conn.Watch(watchCtx, filePath)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	contents, err := decrypt(c.kms, filePath, current.Contents)
+	if err != nil {
+		cancel()
+		for range changes {
+		}
+		return nil, nil, err
+	}
+	current = &topo.WatchData{Contents: contents, Version: current.Version}
+
+	decrypted := make(chan *topo.WatchData, cap(changes))
+	go func() {
+		defer cancel()
+		defer close(decrypted)
+		for wd := range changes {
+			if wd.Err == nil {
+				contents, err := decrypt(c.kms, filePath, wd.Contents)
+				if err != nil {
+					// Report the error, stop the underlying watch and
+					// drain it, as the changes channel must be closed
+					// right after an error.
+					decrypted <- &topo.WatchData{Err: err}
+					cancel()
+					for range changes {
+					}
+					return
+				}
+				wd = &topo.WatchData{Contents: contents, Version: wd.Version}
+			}
+			decrypted <- wd
+		}
+	}()
+	return current, decrypted, nil
+}
+
+// WatchRecursive is part of the topo.Conn interface.
+func (c *Conn) WatchRecursive(ctx context.Context, dirPath string) ([]*topo.WatchDataRecursive, <-chan *topo.WatchDataRecursive, error) {
+	watchCtx, cancel := context.WithCancel(ctx)
+	current, changes, err := c.Conn.WatchRecursive(watchCtx, dirPath)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+	result := make([]*topo.WatchDataRecursive, len(current))
+	for i, wd := range current {
+		decoded, err := c.decryptRecursive(wd)
+		if err != nil {
+			cancel()
+			for range changes {
+			}
+			return nil, nil, err
+		}
+		result[i] = decoded
+	}
+
+	decrypted := make(chan *topo.WatchDataRecursive, cap(changes))
+	go func() {
+		defer cancel()
+		defer close(decrypted)
+		for wd := range changes {
+			decoded, err := c.decryptRecursive(wd)
+			if err != nil {
+				decrypted <- &topo.WatchDataRecursive{Path: wd.Path, WatchData: topo.WatchData{Err: err}}
+				cancel()
+				for range changes {
+				}
+				return
+			}
+			decrypted <- decoded
+		}
+	}()
+	return result, decrypted, nil
+}
+
+func (c *Conn) decryptRecursive(wd *topo.WatchDataRecursive) (*topo.WatchDataRecursive, error) {
+	if wd.Err != nil || wd.Contents == nil {
+		return wd, nil
+	}
+	contents, err := decrypt(c.kms, wd.Path, wd.Contents)
+	if err != nil {
+		return nil, err
+	}
+	return &topo.WatchDataRecursive{
+		Path: wd.Path,
+		WatchData: topo.WatchData{
+			Contents: contents,
+			Version:  wd.Version,
+		},
+	}, nil
+}
+
+// ReEncrypt rewrites the records under dirPath that match the Conn paths
+// and are not protected by the current KMS key, including plaintext ones.
+// It uses versioned updates, so records concurrently modified are skipped:
+// their new version was already written with the current key.
+// It returns the number of records rewritten.
+func (c *Conn) ReEncrypt(ctx context.Context, dirPath string) (int, error) {
+	kvs, err := c.Conn.List(ctx, dirPath)
+	if err != nil {
+		if errors.Is(err, &topo.TopoError{Code: topo.NoNode}) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	currentKeyID := c.kms.CurrentKeyID()
+	rewritten := 0
+	for _, kv := range kvs {
+		filePath := string(kv.Key)
+		if !c.shouldEncrypt(filePath) || keyIDOf(kv.Value) == currentKeyID {
+			continue
+		}
+		contents, err := decrypt(c.kms, filePath, kv.Value)
+		if err != nil {
+			return rewritten, err
+		}
+		encoded, err := encrypt(c.kms, filePath, contents)
+		if err != nil {
+			return rewritten, err
+		}
+		if _, err := c.Conn.Update(ctx, filePath, encoded, kv.Version); err != nil {
+			if errors.Is(err, &topo.TopoError{Code: topo.BadVersion}) {
+				continue
+			}
+			return rewritten, err
+		}
+		rewritten++
+	}
+	return rewritten, nil
+}
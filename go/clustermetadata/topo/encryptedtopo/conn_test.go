@@ -0,0 +1,235 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedtopo
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/test"
+)
+
+const secretLocation = "s3://secret-bucket/backups"
+
+// newEncryptedServer returns a store encrypting the default paths, along
+// with a raw Conn to the global topology that bypasses encryption.
+func newEncryptedServer(t *testing.T, kms KMS) (topo.Store, topo.Conn) {
+	t.Helper()
+	ctx := context.Background()
+	_, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	ts, err := topo.NewWithFactory(NewFactory(factory, kms, nil), "", []string{""})
+	require.NoError(t, err)
+	raw, err := factory.Create(topo.GlobalCell, "", []string{""})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		ts.Close()
+		raw.Close()
+	})
+	return ts, raw
+}
+
+func newTestKMS(t *testing.T) *LocalKMS {
+	t.Helper()
+	kms, err := NewLocalKMS(filepath.Join(t.TempDir(), "keys.json"))
+	require.NoError(t, err)
+	return kms
+}
+
+func TestEncryptedTopo(t *testing.T) {
+	// Run the TopoServerTestSuite tests with every record encrypted.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kms := newTestKMS(t)
+	allPaths := []string{"*", "*/*", "*/*/*", "*/*/*/*", "*/*/*/*/*"}
+	test.TopoServerTestSuite(t, ctx, func() topo.Store {
+		_, factory := memorytopo.NewServerAndFactory(ctx, test.LocalCellName)
+		ts, err := topo.NewWithFactory(NewFactory(factory, kms, allPaths), "", []string{""})
+		require.NoError(t, err)
+		return ts
+	})
+}
+
+func TestEncryptedRecords(t *testing.T) {
+	ctx := context.Background()
+	kms := newTestKMS(t)
+	ts, raw := newEncryptedServer(t, kms)
+
+	db := &clustermetadatapb.Database{Name: "db1", BackupLocation: secretLocation}
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", db))
+
+	// The stored bytes don't contain the secret.
+	contents, _, err := raw.Get(ctx, "databases/db1/Database")
+	require.NoError(t, err)
+	require.True(t, isEncrypted(contents))
+	require.False(t, bytes.Contains(contents, []byte(secretLocation)))
+
+	// The store reads it back transparently.
+	got, err := ts.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(db, got))
+
+	// Versioned updates still work.
+	require.NoError(t, ts.UpdateDatabaseFields(ctx, "db1", func(db *clustermetadatapb.Database) error {
+		db.Cells = []string{"zone1"}
+		return nil
+	}))
+	got, err = ts.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"zone1"}, got.Cells)
+	require.Equal(t, secretLocation, got.BackupLocation)
+
+	// Paths that are not configured are stored in plaintext.
+	contents, _, err = raw.Get(ctx, "cells/zone1/Cell")
+	require.NoError(t, err)
+	require.False(t, isEncrypted(contents))
+}
+
+func TestEncryptedRecordMoved(t *testing.T) {
+	ctx := context.Background()
+	kms := newTestKMS(t)
+	ts, raw := newEncryptedServer(t, kms)
+
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1"}))
+	require.NoError(t, ts.CreateDatabase(ctx, "db2", &clustermetadatapb.Database{Name: "db2", BackupLocation: secretLocation}))
+
+	// A record copied to another path doesn't decrypt.
+	contents, _, err := raw.Get(ctx, "databases/db2/Database")
+	require.NoError(t, err)
+	_, err = raw.Update(ctx, "databases/db1/Database", contents, nil)
+	require.NoError(t, err)
+	_, err = ts.GetDatabase(ctx, "db1")
+	require.Equal(t, mtrpcpb.Code_DATA_LOSS, mterrors.Code(err))
+}
+
+func TestEncryptedPlaintextMigration(t *testing.T) {
+	ctx := context.Background()
+	kms := newTestKMS(t)
+	ts, raw := newEncryptedServer(t, kms)
+
+	// A record written before encryption was turned on.
+	plaintext, err := proto.Marshal(&clustermetadatapb.Database{Name: "db1", BackupLocation: secretLocation})
+	require.NoError(t, err)
+	_, err = raw.Create(ctx, "databases/db1/Database", plaintext)
+	require.NoError(t, err)
+
+	got, err := ts.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.Equal(t, secretLocation, got.BackupLocation)
+
+	conn := NewConn(raw, kms, nil)
+	n, err := conn.ReEncrypt(ctx, "")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	contents, _, err := raw.Get(ctx, "databases/db1/Database")
+	require.NoError(t, err)
+	require.Equal(t, kms.CurrentKeyID(), keyIDOf(contents))
+}
+
+func TestKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	keyFile := filepath.Join(t.TempDir(), "keys.json")
+	kms, err := NewLocalKMS(keyFile)
+	require.NoError(t, err)
+	ts, raw := newEncryptedServer(t, kms)
+
+	for _, name := range []string{"db1", "db2"} {
+		require.NoError(t, ts.CreateDatabase(ctx, name, &clustermetadatapb.Database{Name: name, BackupLocation: secretLocation}))
+	}
+	oldKeyID := kms.CurrentKeyID()
+
+	require.NoError(t, kms.Rotate("key-2"))
+	require.Equal(t, "key-2", kms.CurrentKeyID())
+	require.Error(t, kms.Rotate("key-2"))
+
+	// Old records are still readable with the old key.
+	got, err := ts.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.Equal(t, secretLocation, got.BackupLocation)
+
+	conn := NewConn(raw, kms, nil)
+	n, err := conn.ReEncrypt(ctx, topo.DatabasesPath+"/")
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	n, err = conn.ReEncrypt(ctx, topo.DatabasesPath+"/")
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	// The keyfile was persisted: a new KMS without the old key can't
+	// read anything, but one loaded from the keyfile can.
+	reloaded, err := NewLocalKMS(keyFile)
+	require.NoError(t, err)
+	require.Equal(t, "key-2", reloaded.CurrentKeyID())
+	for _, name := range []string{"db1", "db2"} {
+		filePath := "databases/" + name + "/Database"
+		contents, _, err := raw.Get(ctx, filePath)
+		require.NoError(t, err)
+		require.Equal(t, "key-2", keyIDOf(contents))
+		require.NotEqual(t, oldKeyID, keyIDOf(contents))
+		_, err = decrypt(reloaded, filePath, contents)
+		require.NoError(t, err)
+		_, err = decrypt(newTestKMS(t), filePath, contents)
+		require.Error(t, err)
+	}
+}
+
+func TestEncryptedWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	kms := newTestKMS(t)
+	ts, _ := newEncryptedServer(t, kms)
+
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1"}))
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+
+	current, changes, err := conn.Watch(ctx, "databases/db1/Database")
+	require.NoError(t, err)
+	db := &clustermetadatapb.Database{}
+	require.NoError(t, proto.Unmarshal(current.Contents, db))
+	require.Equal(t, "db1", db.Name)
+
+	require.NoError(t, ts.UpdateDatabaseFields(ctx, "db1", func(db *clustermetadatapb.Database) error {
+		db.BackupLocation = secretLocation
+		return nil
+	}))
+
+	select {
+	case wd := <-changes:
+		require.NoError(t, wd.Err)
+		require.NotEqual(t, current.Version, wd.Version)
+		require.NoError(t, proto.Unmarshal(wd.Contents, db))
+		require.Equal(t, secretLocation, db.BackupLocation)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "timed out waiting for watch notification")
+	}
+
+	cancel()
+	for wd := range changes {
+		require.ErrorIs(t, wd.Err, &topo.TopoError{Code: topo.Interrupted})
+	}
+}
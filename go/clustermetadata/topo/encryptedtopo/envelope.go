@@ -0,0 +1,146 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedtopo
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// envelopeMagic prefixes every encrypted record. Records without it are
+// returned as is, which allows turning encryption on for a topology that
+// already holds plaintext records.
+var envelopeMagic = []byte("\x00mtenc1")
+
+// envelope is an encrypted record. Its encoding is:
+//
+//	magic | uvarint len(keyID) | keyID | uvarint len(wrappedKey) | wrappedKey | sealed contents
+//
+// where wrappedKey is the data-encryption key wrapped by the KMS key
+// keyID, and sealed contents is nonce || AES-GCM ciphertext. The
+// additional data of AES-GCM binds the ciphertext to keyID and to the path
+// of the record, so that the record can't be copied to another path.
+type envelope struct {
+	keyID      string
+	wrappedKey []byte
+	sealed     []byte
+}
+
+// isEncrypted returns true if contents is an encrypted record.
+func isEncrypted(contents []byte) bool {
+	return bytes.HasPrefix(contents, envelopeMagic)
+}
+
+func (e *envelope) marshal() []byte {
+	buf := make([]byte, 0, len(envelopeMagic)+2*binary.MaxVarintLen64+len(e.keyID)+len(e.wrappedKey)+len(e.sealed))
+	buf = append(buf, envelopeMagic...)
+	buf = binary.AppendUvarint(buf, uint64(len(e.keyID)))
+	buf = append(buf, e.keyID...)
+	buf = binary.AppendUvarint(buf, uint64(len(e.wrappedKey)))
+	buf = append(buf, e.wrappedKey...)
+	return append(buf, e.sealed...)
+}
+
+func unmarshalEnvelope(contents []byte) (*envelope, error) {
+	buf := contents[len(envelopeMagic):]
+	readField := func() ([]byte, error) {
+		n, read := binary.Uvarint(buf)
+		if read <= 0 || uint64(len(buf)-read) < n {
+			return nil, mterrors.New(mtrpcpb.Code_DATA_LOSS, "corrupted encrypted record")
+		}
+		field := buf[read : read+int(n)]
+		buf = buf[read+int(n):]
+		return field, nil
+	}
+	keyID, err := readField()
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := readField()
+	if err != nil {
+		return nil, err
+	}
+	return &envelope{
+		keyID:      string(keyID),
+		wrappedKey: wrappedKey,
+		sealed:     buf,
+	}, nil
+}
+
+// additionalData returns the AES-GCM additional data of the record at
+// filePath protected by the KMS key keyID.
+func additionalData(keyID, filePath string) []byte {
+	buf := make([]byte, 0, binary.MaxVarintLen64+len(keyID)+len(filePath))
+	buf = binary.AppendUvarint(buf, uint64(len(keyID)))
+	buf = append(buf, keyID...)
+	return append(buf, filePath...)
+}
+
+// encrypt seals the contents of the record at filePath with a fresh
+// data-encryption key, wrapped by the current KMS key.
+func encrypt(kms KMS, filePath string, contents []byte) ([]byte, error) {
+	dek := make([]byte, KeySize)
+	if _, err := rand.Read(dek); err != nil {
+		return nil, err
+	}
+	keyID, wrappedKey, err := kms.WrapKey(dek)
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to wrap data-encryption key")
+	}
+	sealed, err := seal(dek, contents, additionalData(keyID, filePath))
+	if err != nil {
+		return nil, err
+	}
+	e := &envelope{
+		keyID:      keyID,
+		wrappedKey: wrappedKey,
+		sealed:     sealed,
+	}
+	return e.marshal(), nil
+}
+
+// decrypt returns the plaintext of the encrypted record at filePath.
+// Plaintext records are returned unchanged.
+func decrypt(kms KMS, filePath string, contents []byte) ([]byte, error) {
+	if !isEncrypted(contents) {
+		return contents, nil
+	}
+	e, err := unmarshalEnvelope(contents)
+	if err != nil {
+		return nil, err
+	}
+	dek, err := kms.UnwrapKey(e.keyID, e.wrappedKey)
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to unwrap data-encryption key")
+	}
+	return open(dek, e.sealed, additionalData(e.keyID, filePath))
+}
+
+// keyIDOf returns the ID of the KMS key protecting contents, or an empty
+// string for plaintext records.
+func keyIDOf(contents []byte) string {
+	if !isEncrypted(contents) {
+		return ""
+	}
+	e, err := unmarshalEnvelope(contents)
+	if err != nil {
+		return ""
+	}
+	return e.keyID
+}
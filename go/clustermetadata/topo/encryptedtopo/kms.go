@@ -0,0 +1,239 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryptedtopo
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// KeySize is the size in bytes of the key-encryption keys and of the
+// data-encryption keys (AES-256).
+const KeySize = 32
+
+// KMS wraps and unwraps data-encryption keys with key-encryption keys
+// it owns. It is the extension point for real key management services;
+// LocalKMS is a keyfile-based stand-in.
+type KMS interface {
+	// CurrentKeyID returns the ID of the key used to wrap new
+	// data-encryption keys.
+	CurrentKeyID() string
+
+	// WrapKey encrypts dek with the current key-encryption key and
+	// returns the ID of that key along with the wrapped dek.
+	WrapKey(dek []byte) (keyID string, wrapped []byte, err error)
+
+	// UnwrapKey decrypts a dek wrapped by the key with the given ID.
+	UnwrapKey(keyID string, wrapped []byte) ([]byte, error)
+}
+
+// keyFile is the on-disk format of the LocalKMS keyfile.
+type keyFile struct {
+	// Current is the ID of the key used for new writes.
+	Current string `json:"current"`
+	// Keys maps key IDs to base64 encoded AES-256 keys. Old keys are
+	// kept so existing records can still be decrypted until they are
+	// re-encrypted.
+	Keys map[string]string `json:"keys"`
+}
+
+// LocalKMS is a KMS backed by a local JSON keyfile holding the
+// key-encryption keys. It is meant for development and for deployments
+// that don't have a key management service.
+type LocalKMS struct {
+	path string
+
+	// mu protects the following fields.
+	mu      sync.Mutex
+	current string
+	keys    map[string][]byte
+}
+
+var _ KMS = (*LocalKMS)(nil)
+
+// NewLocalKMS loads the keyfile at path. If the file doesn't exist, it is
+// created with a single random key.
+func NewLocalKMS(path string) (*LocalKMS, error) {
+	kms := &LocalKMS{
+		path: path,
+		keys: make(map[string][]byte),
+	}
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		if err := kms.Rotate("key-1"); err != nil {
+			return nil, err
+		}
+		return kms, nil
+	case err != nil:
+		return nil, err
+	}
+
+	var kf keyFile
+	if err := json.Unmarshal(data, &kf); err != nil {
+		return nil, mterrors.Wrapf(err, "invalid keyfile %v", path)
+	}
+	for id, encoded := range kf.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, mterrors.Wrapf(err, "invalid key %v in keyfile %v", id, path)
+		}
+		if len(key) != KeySize {
+			return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "key %v in keyfile %v has %d bytes, expected %d", id, path, len(key), KeySize)
+		}
+		kms.keys[id] = key
+	}
+	if _, ok := kms.keys[kf.Current]; !ok {
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "current key %q not found in keyfile %v", kf.Current, path)
+	}
+	kms.current = kf.Current
+	return kms, nil
+}
+
+// Rotate generates a new random key with the given ID, makes it the
+// current key and saves the keyfile. Previous keys are kept so existing
+// records can still be read; use ReEncrypt to rewrite them with the new key.
+func (k *LocalKMS) Rotate(keyID string) error {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[keyID]; ok {
+		return mterrors.Errorf(mtrpcpb.Code_ALREADY_EXISTS, "key %v already exists", keyID)
+	}
+	k.keys[keyID] = key
+	oldCurrent := k.current
+	k.current = keyID
+	if err := k.saveLocked(); err != nil {
+		delete(k.keys, keyID)
+		k.current = oldCurrent
+		return err
+	}
+	return nil
+}
+
+// saveLocked atomically writes the keyfile. k.mu must be held.
+func (k *LocalKMS) saveLocked() error {
+	kf := keyFile{
+		Current: k.current,
+		Keys:    make(map[string]string, len(k.keys)),
+	}
+	for id, key := range k.keys {
+		kf.Keys[id] = base64.StdEncoding.EncodeToString(key)
+	}
+	data, err := json.MarshalIndent(kf, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(k.path), filepath.Base(k.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), k.path)
+}
+
+// CurrentKeyID is part of the KMS interface.
+func (k *LocalKMS) CurrentKeyID() string {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.current
+}
+
+// WrapKey is part of the KMS interface.
+func (k *LocalKMS) WrapKey(dek []byte) (string, []byte, error) {
+	k.mu.Lock()
+	keyID, kek := k.current, k.keys[k.current]
+	k.mu.Unlock()
+
+	wrapped, err := seal(kek, dek, []byte(keyID))
+	if err != nil {
+		return "", nil, err
+	}
+	return keyID, wrapped, nil
+}
+
+// UnwrapKey is part of the KMS interface.
+func (k *LocalKMS) UnwrapKey(keyID string, wrapped []byte) ([]byte, error) {
+	k.mu.Lock()
+	kek, ok := k.keys[keyID]
+	k.mu.Unlock()
+	if !ok {
+		return nil, mterrors.Errorf(mtrpcpb.Code_FAILED_PRECONDITION, "unknown key-encryption key %q", keyID)
+	}
+	return open(kek, wrapped, []byte(keyID))
+}
+
+// seal encrypts plaintext with AES-GCM and returns nonce || ciphertext.
+func seal(key, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a nonce || ciphertext blob produced by seal.
+func open(key, sealed, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, mterrors.New(mtrpcpb.Code_DATA_LOSS, "encrypted data is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, mterrors.Errorf(mtrpcpb.Code_DATA_LOSS, "failed to decrypt: %v", err)
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
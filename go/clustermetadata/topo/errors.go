@@ -16,6 +16,9 @@ package topo
 
 import (
 	"fmt"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
 )
 
 // ErrorCode is the error code for topo errors.
@@ -82,6 +85,42 @@ func (e TopoError) Error() string {
 	return fmt.Sprintf("topo error [%d]: %s", e.Code, e.Message)
 }
 
+// ErrorCode implements mterrors.ErrorWithCode, so that mterrors.Code
+// and the RPC layer report topo errors with a meaningful code.
+func (e TopoError) ErrorCode() mtrpcpb.Code {
+	switch e.Code {
+	case NodeExists:
+		return mtrpcpb.Code_ALREADY_EXISTS
+	case NoNode:
+		return mtrpcpb.Code_NOT_FOUND
+	case NodeNotEmpty:
+		return mtrpcpb.Code_FAILED_PRECONDITION
+	case Timeout:
+		return mtrpcpb.Code_DEADLINE_EXCEEDED
+	case Interrupted:
+		return mtrpcpb.Code_CANCELED
+	case BadVersion:
+		// The caller raced with another writer, and may retry from
+		// a fresh read.
+		return mtrpcpb.Code_ABORTED
+	case PartialResult:
+		// Some cells could not be reached.
+		return mtrpcpb.Code_UNAVAILABLE
+	case NoUpdateNeeded:
+		// The caller aborted the update itself.
+		return mtrpcpb.Code_ABORTED
+	case NoImplementation, NoReadOnlyImplementation:
+		return mtrpcpb.Code_UNIMPLEMENTED
+	case ResourceExhausted:
+		return mtrpcpb.Code_RESOURCE_EXHAUSTED
+	default:
+		return mtrpcpb.Code_UNKNOWN
+	}
+}
+
+// Ensure TopoError carries an mtrpc code at compile time.
+var _ mterrors.ErrorWithCode = TopoError{}
+
 // Is implements error comparison for errors.Is.
 func (e TopoError) Is(target error) bool {
 	if targetTopo, ok := target.(*TopoError); ok {
@@ -0,0 +1,73 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+)
+
+func TestTopoErrorCode(t *testing.T) {
+	tests := []struct {
+		code topo.ErrorCode
+		want mtrpcpb.Code
+	}{
+		{topo.NodeExists, mtrpcpb.Code_ALREADY_EXISTS},
+		{topo.NoNode, mtrpcpb.Code_NOT_FOUND},
+		{topo.NodeNotEmpty, mtrpcpb.Code_FAILED_PRECONDITION},
+		{topo.Timeout, mtrpcpb.Code_DEADLINE_EXCEEDED},
+		{topo.Interrupted, mtrpcpb.Code_CANCELED},
+		{topo.BadVersion, mtrpcpb.Code_ABORTED},
+		{topo.PartialResult, mtrpcpb.Code_UNAVAILABLE},
+		{topo.NoUpdateNeeded, mtrpcpb.Code_ABORTED},
+		{topo.NoImplementation, mtrpcpb.Code_UNIMPLEMENTED},
+		{topo.NoReadOnlyImplementation, mtrpcpb.Code_UNIMPLEMENTED},
+		{topo.ResourceExhausted, mtrpcpb.Code_RESOURCE_EXHAUSTED},
+		{topo.ErrorCode(1000), mtrpcpb.Code_UNKNOWN},
+	}
+	for _, tt := range tests {
+		err := topo.NewError(tt.code, "some/node")
+		require.Equal(t, tt.want, mterrors.Code(err), "%v", err)
+
+		// The code and the errors.Is matching survive wrapping.
+		wrapped := mterrors.Wrapf(mterrors.Wrap(err, "inner"), "outer %d", 1)
+		require.Equal(t, tt.want, mterrors.Code(wrapped), "%v", wrapped)
+		require.ErrorIs(t, wrapped, &topo.TopoError{Code: tt.code})
+		require.False(t, errors.Is(wrapped, &topo.TopoError{Code: tt.code + 1}))
+	}
+}
+
+func TestStoreErrorCodes(t *testing.T) {
+	ctx := context.Background()
+	ts, _ := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	_, err := ts.GetDatabase(ctx, "missing")
+	require.Equal(t, mtrpcpb.Code_NOT_FOUND, mterrors.Code(err))
+
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1"}))
+	err = ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1"})
+	require.Equal(t, mtrpcpb.Code_ALREADY_EXISTS, mterrors.Code(err))
+}
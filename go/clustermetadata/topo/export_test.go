@@ -0,0 +1,45 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"maps"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ResetRecordUpgradesForTest clears the registered record upgrades, and
+// returns a function restoring them.
+func ResetRecordUpgradesForTest() (restore func()) {
+	recordUpgradesMu.Lock()
+	defer recordUpgradesMu.Unlock()
+	saved := maps.Clone(recordUpgrades)
+	recordUpgrades = make(map[protoreflect.FullName][]RecordUpgrade)
+	return func() {
+		recordUpgradesMu.Lock()
+		defer recordUpgradesMu.Unlock()
+		recordUpgrades = saved
+	}
+}
+
+// AuditSeenRecords is the number of records whose last version the
+// audit log keeps.
+const AuditSeenRecords = auditSeenRecords
+
+// Stats of the topology connections.
+var (
+	TopoStatsConnTimings = topoStatsConnTimings
+	TopoStatsConnErrors  = topoStatsConnErrors
+)
@@ -25,7 +25,7 @@ import (
 
 // ListDir is part of the topo.Conn interface.
 func (c *conn) ListDir(ctx context.Context, dirPath string, full bool) ([]topo.DirEntry, error) {
-	// c.factory.callstats.Add([]string{"ListDir"}, 1)
+	c.factory.callstats.Add([]string{"ListDir"}, 1)
 
 	if err := c.dial(ctx); err != nil {
 		return nil, err
@@ -30,7 +30,7 @@ import (
 
 // Create is part of topo.Conn interface.
 func (c *conn) Create(ctx context.Context, filePath string, contents []byte) (topo.Version, error) {
-	// c.factory.callstats.Add([]string{"Create"}, 1)
+	c.factory.callstats.Add([]string{"Create"}, 1)
 
 	if err := c.dial(ctx); err != nil {
 		return nil, err
@@ -79,7 +79,7 @@ func (c *conn) Create(ctx context.Context, filePath string, contents []byte) (to
 
 // Update is part of topo.Conn interface.
 func (c *conn) Update(ctx context.Context, filePath string, contents []byte, version topo.Version) (topo.Version, error) {
-	//	c.factory.callstats.Add([]string{"Update"}, 1)
+	c.factory.callstats.Add([]string{"Update"}, 1)
 
 	if err := c.dial(ctx); err != nil {
 		return nil, err
@@ -162,7 +162,7 @@ func (c *conn) Update(ctx context.Context, filePath string, contents []byte, ver
 
 // Get is part of topo.Conn interface.
 func (c *conn) Get(ctx context.Context, filePath string) ([]byte, topo.Version, error) {
-	// c.factory.callstats.Add([]string{"Get"}, 1)
+	c.factory.callstats.Add([]string{"Get"}, 1)
 
 	if err := c.dial(ctx); err != nil {
 		return nil, nil, err
@@ -195,7 +195,7 @@ func (c *conn) GetVersion(ctx context.Context, filePath string, version int64) (
 
 // List is part of the topo.Conn interface.
 func (c *conn) List(ctx context.Context, filePathPrefix string) ([]topo.KVInfo, error) {
-	// c.factory.callstats.Add([]string{"List"}, 1)
+	c.factory.callstats.Add([]string{"List"}, 1)
 
 	if err := c.dial(ctx); err != nil {
 		return nil, err
@@ -259,7 +259,7 @@ func gatherChildren(n *node, dirPath string) []topo.KVInfo {
 
 // Delete is part of topo.Conn interface.
 func (c *conn) Delete(ctx context.Context, filePath string, version topo.Version) error {
-	// c.factory.callstats.Add([]string{"Delete"}, 1)
+	c.factory.callstats.Add([]string{"Delete"}, 1)
 
 	if err := c.dial(ctx); err != nil {
 		return err
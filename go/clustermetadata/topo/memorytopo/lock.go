@@ -41,7 +41,7 @@ type memoryTopoLockDescriptor struct {
 
 // TryLock is part of the topo.Conn interface.
 func (c *conn) TryLock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
-	// c.factory.callstats.Add([]string{"TryLock"}, 1)
+	c.factory.callstats.Add([]string{"TryLock"}, 1)
 
 	c.factory.mu.Lock()
 	err := c.factory.getOperationError(TryLock, dirPath)
@@ -88,7 +88,7 @@ func (c *conn) checkLockExistence(ctx context.Context, dirPath string, named boo
 
 // Lock is part of the topo.Conn interface.
 func (c *conn) Lock(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
-	// c.factory.callstats.Add([]string{"Lock"}, 1)
+	c.factory.callstats.Add([]string{"Lock"}, 1)
 
 	c.factory.mu.Lock()
 	err := c.factory.getOperationError(Lock, dirPath)
@@ -103,7 +103,7 @@ func (c *conn) Lock(ctx context.Context, dirPath, contents string) (topo.LockDes
 // LockWithTTL is part of the topo.Conn interface. It behaves the same as Lock
 // as TTLs are not supported in memorytopo.
 func (c *conn) LockWithTTL(ctx context.Context, dirPath, contents string, _ time.Duration) (topo.LockDescriptor, error) {
-	// c.factory.callstats.Add([]string{"LockWithTTL"}, 1)
+	c.factory.callstats.Add([]string{"LockWithTTL"}, 1)
 
 	c.factory.mu.Lock()
 	err := c.factory.getOperationError(Lock, dirPath)
@@ -117,7 +117,7 @@ func (c *conn) LockWithTTL(ctx context.Context, dirPath, contents string, _ time
 
 // LockName is part of the topo.Conn interface.
 func (c *conn) LockName(ctx context.Context, dirPath, contents string) (topo.LockDescriptor, error) {
-	// c.factory.callstats.Add([]string{"LockName"}, 1)
+	c.factory.callstats.Add([]string{"LockName"}, 1)
 	return c.lock(ctx, dirPath, contents, true)
 }
 
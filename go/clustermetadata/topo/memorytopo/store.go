@@ -23,7 +23,9 @@ import (
 	"log/slog"
 	"math/rand/v2"
 	"os"
+	"path"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -31,6 +33,7 @@ import (
 	"github.com/multigres/multigres/go/clustermetadata/topo"
 
 	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	"github.com/multigres/multigres/go/stats"
 )
 
 const (
@@ -95,8 +98,7 @@ type Factory struct {
 	operationErrors map[Operation][]errorSpec
 	// callstats allows us to keep track of how many topo.conn calls
 	// we make (Create, Get, Update, Delete, List, ListDir, etc).
-	// TODO: Implement stats
-	//callstats *stats.CountersWithMultiLabels
+	callstats *stats.CountersWithMultiLabels
 }
 
 type errorSpec struct {
@@ -136,9 +138,11 @@ func (f *Factory) SetError(err error) {
 	}
 }
 
-// func (f *Factory) GetCallStats() *stats.CountersWithMultiLabels {
-//	return f.callstats
-//}
+// GetCallStats returns the number of calls made to the connections of
+// the factory, by method.
+func (f *Factory) GetCallStats() *stats.CountersWithMultiLabels {
+	return f.callstats
+}
 
 // Lock blocks all requests to the topo and is exposed to allow tests to
 // simulate an unresponsive topo server
@@ -180,7 +184,7 @@ func (c *conn) dial(ctx context.Context) error {
 
 // Close is part of the topo.Conn interface.
 func (c *conn) Close() error {
-	//c.factory.callstats.Add([]string{"Close"}, 1)
+	c.factory.callstats.Add([]string{"Close"}, 1)
 	c.closed.Store(true)
 	return nil
 }
@@ -220,6 +224,17 @@ func (n *node) isDirectory() bool {
 	return n.children != nil
 }
 
+// fullPath returns the path of the node, relative to the root directory
+// of its cell.
+func (n *node) fullPath() string {
+	var names []string
+	for ; n.parent != nil; n = n.parent {
+		names = append(names, n.name)
+	}
+	slices.Reverse(names)
+	return path.Join(names...)
+}
+
 func (n *node) recurseContents(callback func(n *node)) {
 	if n.isDirectory() {
 		for _, child := range n.children {
@@ -276,9 +291,9 @@ func (n *node) PropagateWatchError(err error) {
 // in case of a problem.
 func NewServerAndFactory(ctx context.Context, cells ...string) (topo.Store, *Factory) {
 	f := &Factory{
-		cells:      make(map[string]*node),
-		generation: uint64(rand.Int64N(1 << 60)),
-		// callstats:       stats.NewCountersWithMultiLabels("", "", []string{"Call"}),
+		cells:           make(map[string]*node),
+		generation:      uint64(rand.Int64N(1 << 60)),
+		callstats:       stats.NewCountersWithMultiLabels("", "", []string{"Call"}),
 		operationErrors: make(map[Operation][]errorSpec),
 	}
 	f.cells[topo.GlobalCell] = f.newDirectory(topo.GlobalCell, nil)
@@ -23,7 +23,7 @@ import (
 
 // Watch is part of the topo.Conn interface.
 func (c *conn) Watch(ctx context.Context, filePath string) (*topo.WatchData, <-chan *topo.WatchData, error) {
-	// c.factory.callstats.Add([]string{"Watch"}, 1)
+	c.factory.callstats.Add([]string{"Watch"}, 1)
 
 	if c.closed.Load() {
 		return nil, nil, ErrConnectionClosed
@@ -78,7 +78,7 @@ func (c *conn) Watch(ctx context.Context, filePath string) (*topo.WatchData, <-c
 
 // WatchRecursive is part of the topo.Conn interface.
 func (c *conn) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.WatchDataRecursive, <-chan *topo.WatchDataRecursive, error) {
-	// c.factory.callstats.Add([]string{"WatchRecursive"}, 1)
+	c.factory.callstats.Add([]string{"WatchRecursive"}, 1)
 
 	if c.closed.Load() {
 		return nil, nil, ErrConnectionClosed
@@ -102,7 +102,7 @@ func (c *conn) WatchRecursive(ctx context.Context, dirpath string) ([]*topo.Watc
 	var initialwd []*topo.WatchDataRecursive
 	n.recurseContents(func(n *node) {
 		initialwd = append(initialwd, &topo.WatchDataRecursive{
-			Path: n.name,
+			Path: n.fullPath(),
 			WatchData: topo.WatchData{
 				Contents: n.contents,
 				Version:  NodeVersion(n.version),
@@ -98,7 +98,7 @@ func (ts *store) GetMultiGateway(ctx context.Context, id *clustermetadatapb.ID)
 		return nil, mterrors.Wrap(err, fmt.Sprintf("unable to get multigateway %q", id))
 	}
 	multigateway := &clustermetadatapb.MultiGateway{}
-	if err := proto.Unmarshal(data, multigateway); err != nil {
+	if err := UnmarshalRecord(data, multigateway); err != nil {
 		return nil, mterrors.Wrap(err, "failed to unmarshal multigateway data")
 	}
 
@@ -131,7 +131,7 @@ func (ts *store) GetMultiGatewayIDsByCell(ctx context.Context, cell string) ([]*
 	result := make([]*clustermetadatapb.ID, len(children))
 	for i, child := range children {
 		multigateway := &clustermetadatapb.MultiGateway{}
-		if err := proto.Unmarshal(child.Value, multigateway); err != nil {
+		if err := UnmarshalRecord(child.Value, multigateway); err != nil {
 			return nil, err
 		}
 		result[i] = multigateway.Id
@@ -160,7 +160,7 @@ func (ts *store) GetMultiGatewaysByCell(ctx context.Context, cellName string) ([
 	mtgateways := make([]*MultiGatewayInfo, 0, len(listResults))
 	for n := range listResults {
 		multigateway := &clustermetadatapb.MultiGateway{}
-		if err := proto.Unmarshal(listResults[n].Value, multigateway); err != nil {
+		if err := UnmarshalRecord(listResults[n].Value, multigateway); err != nil {
 			return nil, err
 		}
 		mtgateways = append(mtgateways, &MultiGatewayInfo{MultiGateway: multigateway, version: listResults[n].Version})
@@ -175,7 +175,7 @@ func (ts *store) UpdateMultiGateway(ctx context.Context, mgi *MultiGatewayInfo)
 		return err
 	}
 
-	data, err := proto.Marshal(mgi.MultiGateway)
+	data, err := marshalRecord(ts.codec, mgi.MultiGateway)
 	if err != nil {
 		return err
 	}
@@ -220,7 +220,7 @@ func (ts *store) CreateMultiGateway(ctx context.Context, mtgateway *clustermetad
 		return err
 	}
 
-	data, err := proto.Marshal(mtgateway)
+	data, err := marshalRecord(ts.codec, mtgateway)
 	if err != nil {
 		return err
 	}
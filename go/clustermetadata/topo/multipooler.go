@@ -98,7 +98,7 @@ func (ts *store) GetMultiPooler(ctx context.Context, id *clustermetadatapb.ID) (
 		return nil, mterrors.Wrap(err, fmt.Sprintf("unable to get multipooler %q", id))
 	}
 	multipooler := &clustermetadatapb.MultiPooler{}
-	if err := proto.Unmarshal(data, multipooler); err != nil {
+	if err := UnmarshalRecord(data, multipooler); err != nil {
 		return nil, mterrors.Wrap(err, "failed to unmarshal multipooler data")
 	}
 
@@ -131,7 +131,7 @@ func (ts *store) GetMultiPoolerIDsByCell(ctx context.Context, cell string) ([]*c
 	result := make([]*clustermetadatapb.ID, len(children))
 	for i, child := range children {
 		multipooler := &clustermetadatapb.MultiPooler{}
-		if err := proto.Unmarshal(child.Value, multipooler); err != nil {
+		if err := UnmarshalRecord(child.Value, multipooler); err != nil {
 			return nil, err
 		}
 		result[i] = multipooler.Id
@@ -178,7 +178,7 @@ func (ts *store) GetMultiPoolersByCell(ctx context.Context, cellName string, opt
 	mtpoolers := make([]*MultiPoolerInfo, 0, capHint)
 	for n := range listResults {
 		multipooler := &clustermetadatapb.MultiPooler{}
-		if err := proto.Unmarshal(listResults[n].Value, multipooler); err != nil {
+		if err := UnmarshalRecord(listResults[n].Value, multipooler); err != nil {
 			return nil, err
 		}
 		if opt != nil && opt.DatabaseShard != nil && opt.DatabaseShard.Database != "" {
@@ -201,7 +201,7 @@ func (ts *store) UpdateMultiPooler(ctx context.Context, mpi *MultiPoolerInfo) er
 		return err
 	}
 
-	data, err := proto.Marshal(mpi.MultiPooler)
+	data, err := marshalRecord(ts.codec, mpi.MultiPooler)
 	if err != nil {
 		return err
 	}
@@ -246,7 +246,7 @@ func (ts *store) CreateMultiPooler(ctx context.Context, mtpooler *clustermetadat
 		return err
 	}
 
-	data, err := proto.Marshal(mtpooler)
+	data, err := marshalRecord(ts.codec, mtpooler)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,214 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"path"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// This file provides the record format versioning of the topology.
+//
+// Every record stored by the store (Cell, Database, MultiPooler,
+// MultiGateway) has a format_version field. Records written before a
+// format change keep their old version, and are upgraded on read by the
+// chain of upgrade functions registered for their type. Writes store the
+// current version. Records with a newer version, written by a newer binary
+// during a rolling upgrade, are read as is, and written back with their
+// version in the binary encoding only. UpgradeRecords and
+// RunRecordUpgrader rewrite old records in place, so the upgrade functions
+// can eventually be removed.
+
+// formatVersionField is the name of the version field of every record.
+const formatVersionField = "format_version"
+
+// RecordUpgrade upgrades, in place, a record from the version it was
+// registered for to the next one.
+type RecordUpgrade func(record proto.Message) error
+
+var (
+	// recordUpgradesMu protects recordUpgrades.
+	recordUpgradesMu sync.RWMutex
+
+	// recordUpgrades has, for each record type, the list of upgrade
+	// functions. The function at index i upgrades a record from
+	// version i to version i+1, so the current version of a type is
+	// the number of upgrades registered for it.
+	recordUpgrades = make(map[protoreflect.FullName][]RecordUpgrade)
+)
+
+// RegisterRecordUpgrade registers the function upgrading records of the
+// same type as record from fromVersion to fromVersion+1. Upgrades must be
+// registered in order, starting at version 0. It will log.Fatal on a gap
+// or a duplicate. Call this function in an 'init' function.
+func RegisterRecordUpgrade(record proto.Message, fromVersion uint32, upgrade RecordUpgrade) {
+	name := record.ProtoReflect().Descriptor().FullName()
+	if versionField(record) == nil {
+		log.Fatalf("Record type %v has no %v field", name, formatVersionField)
+	}
+
+	recordUpgradesMu.Lock()
+	defer recordUpgradesMu.Unlock()
+	if int(fromVersion) != len(recordUpgrades[name]) {
+		log.Fatalf("Record upgrade for %v from version %v registered out of order, expected version %v", name, fromVersion, len(recordUpgrades[name]))
+	}
+	recordUpgrades[name] = append(recordUpgrades[name], upgrade)
+}
+
+// CurrentRecordVersion returns the format version written for records
+// of the same type as record.
+func CurrentRecordVersion(record proto.Message) uint32 {
+	recordUpgradesMu.RLock()
+	defer recordUpgradesMu.RUnlock()
+	return uint32(len(recordUpgrades[record.ProtoReflect().Descriptor().FullName()]))
+}
+
+func versionField(record proto.Message) protoreflect.FieldDescriptor {
+	fd := record.ProtoReflect().Descriptor().Fields().ByName(formatVersionField)
+	if fd == nil || fd.Kind() != protoreflect.Uint32Kind {
+		return nil
+	}
+	return fd
+}
+
+// RecordVersion returns the format version of record.
+func RecordVersion(record proto.Message) uint32 {
+	fd := versionField(record)
+	if fd == nil {
+		return 0
+	}
+	return uint32(record.ProtoReflect().Get(fd).Uint())
+}
+
+func setRecordVersion(record proto.Message, version uint32) {
+	if fd := versionField(record); fd != nil {
+		record.ProtoReflect().Set(fd, protoreflect.ValueOfUint32(version))
+	}
+}
+
+// upgradeRecord runs the registered upgrades on record, from its version
+// to the current one. Records written with a newer version than the
+// current one are left untouched.
+func upgradeRecord(record proto.Message) error {
+	name := record.ProtoReflect().Descriptor().FullName()
+	recordUpgradesMu.RLock()
+	upgrades := recordUpgrades[name]
+	recordUpgradesMu.RUnlock()
+
+	for version := RecordVersion(record); int(version) < len(upgrades); version++ {
+		if err := upgrades[version](record); err != nil {
+			return mterrors.Wrapf(err, "failed to upgrade %v record from version %v", name, version)
+		}
+		setRecordVersion(record, version+1)
+	}
+	return nil
+}
+
+// marshalRecord encodes a record to be stored with codec, with the
+// current format version. The provided record is not modified. Records
+// read with a newer format version, written by a newer binary during an
+// upgrade, keep their version. They are refused with JSONCodec: the binary
+// encoding keeps the fields this binary doesn't know, JSON would drop them.
+func marshalRecord(codec Codec, record proto.Message) ([]byte, error) {
+	current := CurrentRecordVersion(record)
+	version := RecordVersion(record)
+	if version > current && codec.Name() == JSONCodec.Name() {
+		return nil, mterrors.Errorf(mtrpcpb.Code_FAILED_PRECONDITION, "%v record has format version %v, newer than the supported version %v, and can't be written as JSON", record.ProtoReflect().Descriptor().FullName(), version, current)
+	}
+	if version < current {
+		record = proto.Clone(record)
+		setRecordVersion(record, current)
+	}
+	return codec.Marshal(record)
+}
+
+// newRecordForPath returns an empty record of the type stored at filePath,
+// or nil if the path doesn't hold a known record.
+func newRecordForPath(filePath string) proto.Message {
+	switch path.Base(filePath) {
+	case CellFile:
+		return &clustermetadatapb.Cell{}
+	case DatabaseFile:
+		return &clustermetadatapb.Database{}
+	case GatewayFile:
+		return &clustermetadatapb.MultiGateway{}
+	case PoolerFile:
+		return &clustermetadatapb.MultiPooler{}
+	default:
+		return nil
+	}
+}
+
+// recordPathsForCell returns the directories holding records in a cell.
+func recordPathsForCell(cell string) []string {
+	if cell == GlobalCell {
+		return []string{CellsPath, DatabasesPath}
+	}
+	return []string{GatewaysPath, PoolersPath}
+}
+
+// UpgradeRecords rewrites, in the given cell, every record stored with an
+// older format version than the current one. It uses versioned updates:
+// a record concurrently modified is skipped, as its writer already stored
+// it with the current version. It returns the number of records rewritten.
+func UpgradeRecords(ctx context.Context, cp ConnProvider, cell string) (int, error) {
+	// Records keep the encoding they were stored with.
+	return rewriteRecords(ctx, cp, cell, func(record proto.Message, stored Codec) Codec {
+		if RecordVersion(record) >= CurrentRecordVersion(record) {
+			return nil
+		}
+		return stored
+	})
+}
+
+// RunRecordUpgrader runs UpgradeRecords on the global topology and on
+// every cell, then again every interval, until ctx is done. Errors are
+// logged, and the cell is retried at the next round.
+func RunRecordUpgrader(ctx context.Context, ts Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		cells, err := ts.GetCellNames(ctx)
+		if err != nil {
+			slog.Warn("record upgrader failed to list cells", "error", err)
+		}
+		for _, cell := range append([]string{GlobalCell}, cells...) {
+			n, err := UpgradeRecords(ctx, ts, cell)
+			if err != nil {
+				slog.Warn("record upgrader failed", "cell", cell, "error", err)
+			}
+			if n > 0 {
+				slog.Info("record upgrader rewrote records", "cell", cell, "count", n)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,284 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+)
+
+// Records encoded before the format_version field existed.
+const (
+	legacyCellHex         = "0a057a6f6e6531120e6c6f63616c686f73743a323337391a102f6d756c7469677265732f7a6f6e6531"
+	legacyDatabaseHex     = "0a03646231120c2f6261636b7570732f6462311a0973656d695f73796e6322057a6f6e653122057a6f6e6532"
+	legacyMultiPoolerHex  = "0a12080112057a6f6e65311a07706f6f6c65723112036462311a0764656661756c7422013030014205686f7374314a090a046772706310fc75"
+	legacyMultiGatewayHex = "0a13080212057a6f6e65311a0867617465776179311205686f7374321a090a0467727063109975"
+)
+
+// writeRaw stores contents at filePath, bypassing the store encoding.
+func writeRaw(t *testing.T, ts topo.Store, cell, filePath, hexContents string) {
+	t.Helper()
+	ctx := context.Background()
+	contents, err := hex.DecodeString(hexContents)
+	require.NoError(t, err)
+	conn, err := ts.ConnForCell(ctx, cell)
+	require.NoError(t, err)
+	_, err = conn.Create(ctx, filePath, contents)
+	require.NoError(t, err)
+}
+
+// readRaw returns the record stored at filePath, without upgrading it.
+func readRaw(t *testing.T, ts topo.Store, cell, filePath string, record proto.Message) {
+	t.Helper()
+	ctx := context.Background()
+	conn, err := ts.ConnForCell(ctx, cell)
+	require.NoError(t, err)
+	contents, _, err := conn.Get(ctx, filePath)
+	require.NoError(t, err)
+	require.NoError(t, proto.Unmarshal(contents, record))
+}
+
+func TestLegacyRecordsLoad(t *testing.T) {
+	defer topo.ResetRecordUpgradesForTest()()
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx)
+	defer ts.Close()
+
+	writeRaw(t, ts, topo.GlobalCell, "cells/zone1/Cell", legacyCellHex)
+	cell, err := ts.GetCell(ctx, "zone1")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&clustermetadatapb.Cell{
+		Name:            "zone1",
+		ServerAddresses: []string{"localhost:2379"},
+		Root:            "/multigres/zone1",
+	}, cell))
+
+	writeRaw(t, ts, topo.GlobalCell, "databases/db1/Database", legacyDatabaseHex)
+	db, err := ts.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&clustermetadatapb.Database{
+		Name:             "db1",
+		BackupLocation:   "/backups/db1",
+		DurabilityPolicy: "semi_sync",
+		Cells:            []string{"zone1", "zone2"},
+	}, db))
+
+	// Cell records need a cell known to the memory topo factory.
+	ts = memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	poolerID := &clustermetadatapb.ID{Component: clustermetadatapb.ID_MULTIPOOLER, Cell: "zone1", Name: "pooler1"}
+	writeRaw(t, ts, "zone1", path.Join(topo.PoolersPath, topo.MultiPoolerIDString(poolerID), topo.PoolerFile), legacyMultiPoolerHex)
+	mpi, err := ts.GetMultiPooler(ctx, poolerID)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&clustermetadatapb.MultiPooler{
+		Id:            poolerID,
+		Database:      "db1",
+		TableGroup:    "default",
+		Shard:         "0",
+		Type:          clustermetadatapb.PoolerType_PRIMARY,
+		ServingStatus: clustermetadatapb.PoolerServingStatus_SERVING,
+		Hostname:      "host1",
+		PortMap:       map[string]int32{"grpc": 15100},
+	}, mpi.MultiPooler))
+
+	gatewayID := &clustermetadatapb.ID{Component: clustermetadatapb.ID_MULTIGATEWAY, Cell: "zone1", Name: "gateway1"}
+	writeRaw(t, ts, "zone1", path.Join(topo.GatewaysPath, topo.MultiGatewayIDString(gatewayID), topo.GatewayFile), legacyMultiGatewayHex)
+	mgi, err := ts.GetMultiGateway(ctx, gatewayID)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(&clustermetadatapb.MultiGateway{
+		Id:       gatewayID,
+		Hostname: "host2",
+		PortMap:  map[string]int32{"grpc": 15001},
+	}, mgi.MultiGateway))
+}
+
+// registerTestDatabaseUpgrades registers two upgrades of the Database
+// record: v0 sets a default durability policy, v1 renames a legacy one.
+func registerTestDatabaseUpgrades() {
+	topo.RegisterRecordUpgrade(&clustermetadatapb.Database{}, 0, func(record proto.Message) error {
+		db := record.(*clustermetadatapb.Database)
+		if db.DurabilityPolicy == "" {
+			db.DurabilityPolicy = "none"
+		}
+		return nil
+	})
+	topo.RegisterRecordUpgrade(&clustermetadatapb.Database{}, 1, func(record proto.Message) error {
+		db := record.(*clustermetadatapb.Database)
+		if db.DurabilityPolicy == "semi_sync" {
+			db.DurabilityPolicy = "ANY_2"
+		}
+		return nil
+	})
+}
+
+func TestRecordUpgradeOnRead(t *testing.T) {
+	defer topo.ResetRecordUpgradesForTest()()
+	registerTestDatabaseUpgrades()
+	require.EqualValues(t, 2, topo.CurrentRecordVersion(&clustermetadatapb.Database{}))
+	require.EqualValues(t, 0, topo.CurrentRecordVersion(&clustermetadatapb.Cell{}))
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx)
+	defer ts.Close()
+
+	writeRaw(t, ts, topo.GlobalCell, "databases/db1/Database", legacyDatabaseHex)
+	db, err := ts.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.Equal(t, "ANY_2", db.DurabilityPolicy)
+	require.EqualValues(t, 2, db.FormatVersion)
+
+	// Reading doesn't rewrite the stored record.
+	stored := &clustermetadatapb.Database{}
+	readRaw(t, ts, topo.GlobalCell, "databases/db1/Database", stored)
+	require.EqualValues(t, 0, stored.FormatVersion)
+	require.Equal(t, "semi_sync", stored.DurabilityPolicy)
+
+	// Writes store the current version, without modifying the caller record.
+	db2 := &clustermetadatapb.Database{Name: "db2", DurabilityPolicy: "ANY_2"}
+	require.NoError(t, ts.CreateDatabase(ctx, "db2", db2))
+	require.EqualValues(t, 0, db2.FormatVersion)
+	readRaw(t, ts, topo.GlobalCell, "databases/db2/Database", stored)
+	require.EqualValues(t, 2, stored.FormatVersion)
+	got, err := ts.GetDatabase(ctx, "db2")
+	require.NoError(t, err)
+	require.Equal(t, "ANY_2", got.DurabilityPolicy)
+}
+
+func TestUpgradeRecords(t *testing.T) {
+	defer topo.ResetRecordUpgradesForTest()()
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	writeRaw(t, ts, topo.GlobalCell, "databases/db1/Database", legacyDatabaseHex)
+	require.NoError(t, ts.CreateDatabase(ctx, "db2", &clustermetadatapb.Database{Name: "db2"}))
+	require.NoError(t, ts.CreateMultiPooler(ctx, topo.NewMultiPooler("1", "zone1", "host1")))
+
+	// Nothing to upgrade yet.
+	n, err := topo.UpgradeRecords(ctx, ts, topo.GlobalCell)
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	registerTestDatabaseUpgrades()
+	n, err = topo.UpgradeRecords(ctx, ts, topo.GlobalCell)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	n, err = topo.UpgradeRecords(ctx, ts, "zone1")
+	require.NoError(t, err)
+	require.Zero(t, n)
+
+	stored := &clustermetadatapb.Database{}
+	readRaw(t, ts, topo.GlobalCell, "databases/db1/Database", stored)
+	require.EqualValues(t, 2, stored.FormatVersion)
+	require.Equal(t, "ANY_2", stored.DurabilityPolicy)
+	readRaw(t, ts, topo.GlobalCell, "databases/db2/Database", stored)
+	require.EqualValues(t, 2, stored.FormatVersion)
+	require.Equal(t, "none", stored.DurabilityPolicy)
+
+	n, err = topo.UpgradeRecords(ctx, ts, topo.GlobalCell)
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestNewerRecordVersions(t *testing.T) {
+	defer topo.ResetRecordUpgradesForTest()()
+	ctx := context.Background()
+	ts, factory := memorytopo.NewServerAndFactory(ctx)
+	defer ts.Close()
+
+	// A record written by a newer binary, with a field this one ignores.
+	newer := &clustermetadatapb.Database{Name: "db1", DurabilityPolicy: "ANY_2", FormatVersion: 3}
+	contents, err := proto.Marshal(newer)
+	require.NoError(t, err)
+	contents = protowire.AppendTag(contents, 100, protowire.BytesType)
+	contents = protowire.AppendString(contents, "new field")
+	writeRaw(t, ts, topo.GlobalCell, "databases/db1/Database", hex.EncodeToString(contents))
+
+	// It is read, and written back with its version and the unknown field.
+	db, err := ts.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.EqualValues(t, 3, db.FormatVersion)
+	require.NoError(t, ts.UpdateDatabaseFields(ctx, "db1", func(db *clustermetadatapb.Database) error {
+		db.DurabilityPolicy = "none"
+		return nil
+	}))
+	conn, err := ts.ConnForCell(ctx, topo.GlobalCell)
+	require.NoError(t, err)
+	stored, _, err := conn.Get(ctx, "databases/db1/Database")
+	require.NoError(t, err)
+	require.True(t, bytes.Contains(stored, []byte("new field")))
+	db, err = ts.GetDatabase(ctx, "db1")
+	require.NoError(t, err)
+	require.EqualValues(t, 3, db.FormatVersion)
+	require.Equal(t, "none", db.DurabilityPolicy)
+
+	// JSON would drop the unknown field: the record isn't re-encoded.
+	n, err := topo.ReencodeRecords(ctx, ts, topo.GlobalCell, topo.JSONCodec)
+	require.NoError(t, err)
+	require.Zero(t, n)
+	reencoded, _, err := conn.Get(ctx, "databases/db1/Database")
+	require.NoError(t, err)
+	require.Equal(t, stored, reencoded)
+
+	// Nor written as JSON.
+	jsonStore, err := topo.NewWithFactory(factory, "", []string{""}, topo.WithCodec(topo.JSONCodec))
+	require.NoError(t, err)
+	defer jsonStore.Close()
+	err = jsonStore.UpdateDatabaseFields(ctx, "db1", func(db *clustermetadatapb.Database) error {
+		db.DurabilityPolicy = "ANY_2"
+		return nil
+	})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+}
+
+func TestRunRecordUpgrader(t *testing.T) {
+	defer topo.ResetRecordUpgradesForTest()()
+	registerTestDatabaseUpgrades()
+	ctx, cancel := context.WithCancel(context.Background())
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	writeRaw(t, ts, topo.GlobalCell, "databases/db1/Database", legacyDatabaseHex)
+
+	done := make(chan struct{})
+	go func() {
+		topo.RunRecordUpgrader(ctx, ts, 10*time.Millisecond)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		stored := &clustermetadatapb.Database{}
+		readRaw(t, ts, topo.GlobalCell, "databases/db1/Database", stored)
+		return stored.FormatVersion == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/multigres/multigres/go/mterrors"
+	"github.com/multigres/multigres/go/stats"
+	"github.com/multigres/multigres/go/tracing"
+)
+
+var (
+	topoStatsConnTimings = stats.NewMultiTimings(
+		"TopologyConnOperations",
+		"Timings of the operations on the topology servers",
+		[]string{"Operation", "Cell"})
+
+	topoStatsConnErrors = stats.NewCountersWithMultiLabels(
+		"TopologyConnErrors",
+		"Errors of the operations on the topology servers",
+		[]string{"Operation", "Cell", "Code"})
+)
+
+// statsConn wraps a Conn and records the timings and the errors of its
+// operations, for the global and for the cell topologies. It also traces
+// every operation in a span.
+type statsConn struct {
+	Conn
+	cell string
+}
+
+var _ Conn = (*statsConn)(nil)
+
+// TODO: Limit the concurrent reads to DefaultReadConcurrency.
+func newStatsConn(cell string, conn Conn) Conn {
+	return &statsConn{Conn: conn, cell: cell}
+}
+
+// start starts the span of an operation on path, and returns the function
+// recording its outcome.
+func (c *statsConn) start(ctx context.Context, operation, path string) (context.Context, func(error)) {
+	start := time.Now()
+	ctx, span := tracing.StartSpan(ctx, "topo."+operation,
+		attribute.String("topo.cell", c.cell),
+		attribute.String("topo.path", path))
+	return ctx, func(err error) {
+		topoStatsConnTimings.Record([]string{operation, c.cell}, start)
+		if err != nil {
+			topoStatsConnErrors.Add([]string{operation, c.cell, mterrors.Code(err).String()}, 1)
+		}
+		tracing.EndSpan(span, err)
+	}
+}
+
+// ListDir is part of the Conn interface.
+func (c *statsConn) ListDir(ctx context.Context, dirPath string, full bool) ([]DirEntry, error) {
+	ctx, done := c.start(ctx, "ListDir", dirPath)
+	entries, err := c.Conn.ListDir(ctx, dirPath, full)
+	done(err)
+	return entries, err
+}
+
+// Create is part of the Conn interface.
+func (c *statsConn) Create(ctx context.Context, filePath string, contents []byte) (Version, error) {
+	ctx, done := c.start(ctx, "Create", filePath)
+	version, err := c.Conn.Create(ctx, filePath, contents)
+	done(err)
+	return version, err
+}
+
+// Update is part of the Conn interface.
+func (c *statsConn) Update(ctx context.Context, filePath string, contents []byte, version Version) (Version, error) {
+	ctx, done := c.start(ctx, "Update", filePath)
+	newVersion, err := c.Conn.Update(ctx, filePath, contents, version)
+	done(err)
+	return newVersion, err
+}
+
+// Get is part of the Conn interface.
+func (c *statsConn) Get(ctx context.Context, filePath string) ([]byte, Version, error) {
+	ctx, done := c.start(ctx, "Get", filePath)
+	contents, version, err := c.Conn.Get(ctx, filePath)
+	done(err)
+	return contents, version, err
+}
+
+// GetVersion is part of the Conn interface.
+func (c *statsConn) GetVersion(ctx context.Context, filePath string, version int64) ([]byte, error) {
+	ctx, done := c.start(ctx, "GetVersion", filePath)
+	contents, err := c.Conn.GetVersion(ctx, filePath, version)
+	done(err)
+	return contents, err
+}
+
+// List is part of the Conn interface.
+func (c *statsConn) List(ctx context.Context, filePathPrefix string) ([]KVInfo, error) {
+	ctx, done := c.start(ctx, "List", filePathPrefix)
+	kvs, err := c.Conn.List(ctx, filePathPrefix)
+	done(err)
+	return kvs, err
+}
+
+// Delete is part of the Conn interface.
+func (c *statsConn) Delete(ctx context.Context, filePath string, version Version) error {
+	ctx, done := c.start(ctx, "Delete", filePath)
+	err := c.Conn.Delete(ctx, filePath, version)
+	done(err)
+	return err
+}
+
+// Lock is part of the Conn interface.
+func (c *statsConn) Lock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	ctx, done := c.start(ctx, "Lock", dirPath)
+	ld, err := c.Conn.Lock(ctx, dirPath, contents)
+	done(err)
+	return ld, err
+}
+
+// LockWithTTL is part of the Conn interface.
+func (c *statsConn) LockWithTTL(ctx context.Context, dirPath, contents string, ttl time.Duration) (LockDescriptor, error) {
+	ctx, done := c.start(ctx, "LockWithTTL", dirPath)
+	ld, err := c.Conn.LockWithTTL(ctx, dirPath, contents, ttl)
+	done(err)
+	return ld, err
+}
+
+// LockName is part of the Conn interface.
+func (c *statsConn) LockName(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	ctx, done := c.start(ctx, "LockName", dirPath)
+	ld, err := c.Conn.LockName(ctx, dirPath, contents)
+	done(err)
+	return ld, err
+}
+
+// TryLock is part of the Conn interface.
+func (c *statsConn) TryLock(ctx context.Context, dirPath, contents string) (LockDescriptor, error) {
+	ctx, done := c.start(ctx, "TryLock", dirPath)
+	ld, err := c.Conn.TryLock(ctx, dirPath, contents)
+	done(err)
+	return ld, err
+}
+
+// Watch is part of the Conn interface. Only the initial read is
+// recorded.
+func (c *statsConn) Watch(ctx context.Context, filePath string) (*WatchData, <-chan *WatchData, error) {
+	ctx, done := c.start(ctx, "Watch", filePath)
+	current, changes, err := c.Conn.Watch(ctx, filePath)
+	done(err)
+	return current, changes, err
+}
+
+// WatchRecursive is part of the Conn interface. Only the initial read
+// is recorded.
+func (c *statsConn) WatchRecursive(ctx context.Context, path string) ([]*WatchDataRecursive, <-chan *WatchDataRecursive, error) {
+	ctx, done := c.start(ctx, "WatchRecursive", path)
+	current, changes, err := c.Conn.WatchRecursive(ctx, path)
+	done(err)
+	return current, changes, err
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package topo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+	"github.com/multigres/multigres/go/tracing"
+)
+
+func TestStatsConn(t *testing.T) {
+	ctx := context.Background()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+
+	timingsBefore := topo.TopoStatsConnTimings.Snapshots()
+	errorsBefore := topo.TopoStatsConnErrors.Counts()
+	callsBefore := factory.GetCallStats().Counts()["Get"]
+
+	id := &clustermetadatapb.ID{Component: clustermetadatapb.ID_MULTIPOOLER, Cell: "zone1", Name: "missing"}
+	_, err := ts.GetMultiPooler(ctx, id)
+	require.Error(t, err)
+	_, err = ts.GetCell(ctx, "zone1")
+	require.NoError(t, err)
+
+	// Every call is timed, per cell, and the errors are counted by code.
+	timings := topo.TopoStatsConnTimings.Snapshots()
+	require.Equal(t, timingsBefore["Get.zone1"].Count+1, timings["Get.zone1"].Count)
+	require.Less(t, timingsBefore["Get.global"].Count, timings["Get.global"].Count)
+	require.Equal(t, errorsBefore["Get.zone1.NOT_FOUND"]+1, topo.TopoStatsConnErrors.Counts()["Get.zone1.NOT_FOUND"])
+	require.Less(t, callsBefore, factory.GetCallStats().Counts()["Get"])
+}
+
+func TestStatsConnSpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracing.NewTracerProvider("test", sdktrace.WithSyncer(exporter), 1))
+	defer otel.SetTracerProvider(previous)
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	exporter.Reset()
+
+	ctx, parent := tracing.StartSpan(ctx, "parent")
+	id := &clustermetadatapb.ID{Component: clustermetadatapb.ID_MULTIPOOLER, Cell: "zone1", Name: "missing"}
+	_, err := ts.GetMultiPooler(ctx, id)
+	require.Error(t, err)
+	tracing.EndSpan(parent, nil)
+
+	// Every operation is traced in a child span of the caller.
+	var get tracetest.SpanStub
+	for _, span := range exporter.GetSpans() {
+		attrs := attribute.NewSet(span.Attributes...)
+		if cell, _ := attrs.Value("topo.cell"); span.Name == "topo.Get" && cell.AsString() == "zone1" {
+			get = span
+		}
+	}
+	require.Equal(t, "topo.Get", get.Name)
+	require.Equal(t, parent.SpanContext().TraceID(), get.SpanContext.TraceID())
+	attrs := attribute.NewSet(get.Attributes...)
+	path, _ := attrs.Value("topo.path")
+	require.Contains(t, path.AsString(), "missing")
+	require.Equal(t, codes.Error, get.Status.Code)
+}
@@ -61,14 +61,16 @@ package topo
 import (
 	"context"
 	"errors"
-	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"os"
+	"sort"
 	"strings"
 	"sync"
 
+	"github.com/spf13/pflag"
+
 	"github.com/multigres/multigres/go/mterrors"
 	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
 )
@@ -183,6 +185,10 @@ type Store interface {
 	// Connection provider for accessing cell-specific connections
 	ConnProvider
 
+	// CircuitBreakerStatuses returns the state of the circuit breaker
+	// of every cell that has been accessed, sorted by cell name.
+	CircuitBreakerStatuses() []CircuitBreakerStatus
+
 	// Resource cleanup
 	io.Closer
 }
@@ -215,6 +221,19 @@ type store struct {
 	// will read the cell configuration from the global cluster and create clients
 	// as needed.
 	cellConns map[string]cellConn
+	// cellBreakers contains one circuit breaker per cell. They outlive
+	// the cached connections, so a cell configuration change doesn't
+	// reset the health of the cell.
+	cellBreakers map[string]*circuitBreaker
+
+	// auditSink, if set, receives every mutation and lock acquisition
+	// made through the store connections. It is set at construction time.
+	auditSink AuditSink
+
+	// codec encodes the records written by the store. Records are
+	// read whatever codec they were written with. It is set at
+	// construction time.
+	codec Codec
 }
 
 // Ensure store implements the Store interface at compile time.
@@ -249,6 +268,15 @@ var (
 	DefaultReadConcurrency int64 = 32
 )
 
+// RegisterFlags registers the flags used by Open on the provided FlagSet.
+// Binaries listed in FlagBinaries register them at startup.
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&topoImplementation, "topo_implementation", topoImplementation, "the topology implementation to use")
+	fs.StringSliceVar(&topoGlobalServerAddresses, "topo_global_server_addresses", topoGlobalServerAddresses, "the addresses of the global topology servers")
+	fs.StringVar(&topoGlobalRoot, "topo_global_root", topoGlobalRoot, "the path of the global topology data in the global topology server")
+	fs.StringVar(&topoRecordEncoding, "topo_record_encoding", topoRecordEncoding, "the encoding of the records written to the topology server (proto, json)")
+}
+
 // RegisterFactory registers a Factory for a specific topology implementation.
 // If an implementation with that name already exists, it will log.Fatal and exit.
 // Call this function in the 'init' function of your topology implementation module.
@@ -261,29 +289,37 @@ func RegisterFactory(name string, factory Factory) {
 
 // NewWithFactory creates a new topology store based on the given Factory.
 // It also opens the global topology connection and initializes the store.
-func NewWithFactory(factory Factory, root string, serverAddrs []string) (Store, error) {
+func NewWithFactory(factory Factory, root string, serverAddrs []string, opts ...StoreOption) (Store, error) {
 	conn, err := factory.Create(GlobalCell, root, serverAddrs)
 	if err != nil {
 		return nil, err
 	}
-	// TODO: Add statistics and monitoring module for topology operations
-	// conn = NewStatsConn(GlobalTopo, conn, globalReadSem)
-
-	return &store{
-		globalTopo: conn,
-		factory:    factory,
-		cellConns:  make(map[string]cellConn),
-	}, nil
+	conn = newStatsConn(GlobalCell, conn)
+
+	ts := &store{
+		factory:      factory,
+		cellConns:    make(map[string]cellConn),
+		cellBreakers: make(map[string]*circuitBreaker),
+		codec:        ProtoCodec,
+	}
+	for _, opt := range opts {
+		opt(ts)
+	}
+	if ts.auditSink != nil {
+		conn = newAuditConn(GlobalCell, conn, ts.auditSink, factory)
+	}
+	ts.globalTopo = conn
+	return ts, nil
 }
 
 // OpenServer returns a topology store using the specified implementation,
 // root path, and server addresses for the global topology server.
-func OpenServer(implementation, root string, serverAddrs []string) (Store, error) {
+func OpenServer(implementation, root string, serverAddrs []string, opts ...StoreOption) (Store, error) {
 	factory, ok := factories[implementation]
 	if !ok {
 		return nil, NewError(NoImplementation, implementation)
 	}
-	return NewWithFactory(factory, root, serverAddrs)
+	return NewWithFactory(factory, root, serverAddrs, opts...)
 }
 
 // Open returns a topology store using the command-line parameter flags
@@ -300,7 +336,12 @@ func Open() Store {
 		slog.Error("topo_global_root must be non-empty")
 		os.Exit(1)
 	}
-	ts, err := OpenServer(topoImplementation, topoGlobalRoot, topoGlobalServerAddresses)
+	codec, err := CodecByName(topoRecordEncoding)
+	if err != nil {
+		slog.Error("Invalid topo record encoding", "error", err)
+		os.Exit(1)
+	}
+	ts, err := OpenServer(topoImplementation, topoGlobalRoot, topoGlobalServerAddresses, WithCodec(codec))
 	if err != nil {
 		slog.Error("Failed to open topo server", "error", err, "implementation", topoImplementation, "addresses", topoGlobalServerAddresses, "root", topoGlobalRoot)
 		os.Exit(1)
@@ -311,6 +352,7 @@ func Open() Store {
 // ConnForCell returns a connection object for the given cell.
 // It caches connection objects from previously requested cells and reuses them
 // when the cell configuration hasn't changed.
+// If the cell circuit breaker is open, it fails fast with UNAVAILABLE.
 func (ts *store) ConnForCell(ctx context.Context, cell string) (Conn, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, err
@@ -333,6 +375,14 @@ func (ts *store) ConnForCell(ctx context.Context, cell string) (Conn, error) {
 	// Return a cached client if present and configuration hasn't changed.
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
+	cb, ok := ts.cellBreakers[cell]
+	if !ok {
+		cb = newCircuitBreaker(cell)
+		ts.cellBreakers[cell] = cb
+	}
+	if err := cb.ready(); err != nil {
+		return nil, err
+	}
 	cc, ok := ts.cellConns[cell]
 	if ok {
 		// Client exists in cache. Verify that it's for the same cell configuration.
@@ -351,32 +401,52 @@ func (ts *store) ConnForCell(ctx context.Context, cell string) (Conn, error) {
 	// Connect to the cell topology server while holding the lock.
 	// This ensures only one connection is established at any given time.
 	// Create the connection and cache it for future use.
+	if err := cb.allow(); err != nil {
+		return nil, err
+	}
 	conn, err := ts.factory.Create(cell, ci.Root, ci.ServerAddresses)
+	cb.record(ctx, err)
 	switch {
 	case err == nil:
-		// TODO: Add statistics and monitoring module for cell operations
-		// cellReadSem := semaphore.NewWeighted(DefaultReadConcurrency)
-		// conn = NewStatsConn(cell, conn, cellReadSem)
+		conn = newStatsConn(cell, conn)
+		conn = newCircuitBreakerConn(conn, cb)
+		if ts.auditSink != nil {
+			conn = newAuditConn(cell, conn, ts.auditSink, ts.factory)
+		}
 		ts.cellConns[cell] = cellConn{ci, conn}
 		return conn, nil
 	case errors.Is(err, &TopoError{Code: NoNode}):
-		err = mterrors.Wrap(err, fmt.Sprintf("failed to create topo connection to %v, %v", serverAddrsStr, ci.Root))
+		err = mterrors.Wrapf(err, "failed to create topo connection to %v, %v", mterrors.Sensitive(serverAddrsStr), mterrors.Sensitive(ci.Root))
 		return nil, NewError(NoNode, err.Error())
 	default:
-		return nil, mterrors.Wrap(err, fmt.Sprintf("failed to create topo connection to %v, %v", serverAddrsStr, ci.Root))
+		return nil, mterrors.Wrapf(err, "failed to create topo connection to %v, %v", mterrors.Sensitive(serverAddrsStr), mterrors.Sensitive(ci.Root))
+	}
+}
+
+// CircuitBreakerStatuses is part of the Store interface.
+func (ts *store) CircuitBreakerStatuses() []CircuitBreakerStatus {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	result := make([]CircuitBreakerStatus, 0, len(ts.cellBreakers))
+	for _, cb := range ts.cellBreakers {
+		result = append(result, cb.status())
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Cell < result[j].Cell
+	})
+	return result
 }
 
 // Close will close all connections to underlying topology stores.
 // It will nil all member variables, so any further access will panic.
 // Returns a combined error if any errors occurred during cleanup.
 func (ts *store) Close() error {
-	var errs []error
+	var rec mterrors.AllErrorRecorder
 
 	// Close global topology connection
 	if ts.globalTopo != nil {
 		if err := ts.globalTopo.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to close global topo: %w", err))
+			rec.RecordError(mterrors.Wrap(err, "failed to close global topo"))
 		}
 		ts.globalTopo = nil
 	}
@@ -388,7 +458,7 @@ func (ts *store) Close() error {
 	for cell, cc := range ts.cellConns {
 		if cc.conn != nil {
 			if err := cc.conn.Close(); err != nil {
-				errs = append(errs, fmt.Errorf("failed to close cell connection %s: %w", cell, err))
+				rec.RecordError(mterrors.Wrapf(err, "failed to close cell connection %s", cell))
 			}
 		}
 	}
@@ -397,8 +467,8 @@ func (ts *store) Close() error {
 	ts.cellConns = make(map[string]cellConn)
 
 	// Return combined error if any occurred during cleanup
-	if len(errs) > 0 {
-		return fmt.Errorf("errors occurred while closing connections: %v", errs)
+	if rec.HasErrors() {
+		return mterrors.Wrap(rec.Error(), "errors occurred while closing connections")
 	}
 
 	return nil
@@ -56,7 +56,7 @@ func waitForInitialValue(t *testing.T, conn topo.Conn, database *clustermetadata
 		break
 	}
 	got := &clustermetadatapb.Database{}
-	err = proto.Unmarshal(current.Contents, got)
+	err = topo.UnmarshalRecord(current.Contents, got)
 	if err != nil {
 		cancel()
 		require.NoError(t, err, "cannot proto-unmarshal data")
@@ -99,8 +99,9 @@ func waitForInitialValueRecursive(t *testing.T, conn topo.Conn, database *cluste
 		// we got a valid result
 		break
 	}
+	require.Equal(t, "databases/test_database/Database", current[0].Path)
 	got := &clustermetadatapb.Database{}
-	err = proto.Unmarshal(current[0].Contents, got)
+	err = topo.UnmarshalRecord(current[0].Contents, got)
 	if err != nil {
 		cancel()
 		require.NoError(t, err, "cannot proto-unmarshal data")
@@ -161,7 +162,7 @@ func checkWatch(t *testing.T, ctx context.Context, ts topo.Store) {
 			require.NoError(t, wd.Err, "watch interrupted")
 		}
 		got := &clustermetadatapb.Database{}
-		err := proto.Unmarshal(wd.Contents, got)
+		err := topo.UnmarshalRecord(wd.Contents, got)
 		require.NoError(t, err, "cannot proto-unmarshal data")
 
 		if got.Name == "test_database" {
@@ -196,7 +197,7 @@ func checkWatch(t *testing.T, ctx context.Context, ts topo.Store) {
 		}
 		// we got something, better be the right value
 		got := &clustermetadatapb.Database{}
-		err := proto.Unmarshal(wd.Contents, got)
+		err := topo.UnmarshalRecord(wd.Contents, got)
 		require.NoError(t, err, "cannot proto-unmarshal data")
 		if got.Name == "test_database_new" {
 			// good value
@@ -248,7 +249,7 @@ func checkWatchInterrupt(t *testing.T, ctx context.Context, ts topo.Store) {
 		}
 		// we got something, better be the right value
 		got := &clustermetadatapb.Database{}
-		err := proto.Unmarshal(wd.Contents, got)
+		err := topo.UnmarshalRecord(wd.Contents, got)
 		require.NoError(t, err, "cannot proto-unmarshal data")
 		if got.Name == "test_database" {
 			// good value
@@ -313,7 +314,7 @@ func checkWatchRecursive(t *testing.T, ctx context.Context, ts topo.Store) {
 			require.NoError(t, wd.Err, "watch interrupted")
 		}
 		got := &clustermetadatapb.Database{}
-		err := proto.Unmarshal(wd.Contents, got)
+		err := topo.UnmarshalRecord(wd.Contents, got)
 		require.NoError(t, err, "cannot proto-unmarshal data")
 
 		if got.Name == "test_database" {
@@ -349,7 +350,7 @@ func checkWatchRecursive(t *testing.T, ctx context.Context, ts topo.Store) {
 		}
 		// we got something, better be the right value
 		got := &clustermetadatapb.Database{}
-		err := proto.Unmarshal(wd.Contents, got)
+		err := topo.UnmarshalRecord(wd.Contents, got)
 		require.NoError(t, err, "cannot proto-unmarshal data")
 		if got.Name == "test_database_new" {
 			// good value
@@ -378,7 +379,7 @@ func checkWatchRecursive(t *testing.T, ctx context.Context, ts topo.Store) {
 		}
 		// we got something, better be the right value
 		got := &clustermetadatapb.Database{}
-		err := proto.Unmarshal(wd.Contents, got)
+		err := topo.UnmarshalRecord(wd.Contents, got)
 		require.NoError(t, err, "cannot proto-unmarshal data")
 		if got.Name == "test_database" {
 			// good value
@@ -20,98 +20,93 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/multigateway"
+	"github.com/multigres/multigres/go/servenv"
 )
 
-func setupConfig() {
+func main() {
 	// Define flags
 	pflag.StringP("port", "p", "5432", "Port to listen on")
-	pflag.StringP("log-level", "l", "info", "Log level (debug, info, warn, error)")
-	pflag.StringP("config", "c", "", "Config file path")
-	pflag.Parse()
-
-	// Setup viper
-	viper.SetDefault("port", "5432")
-	viper.SetDefault("log-level", "info")
-
-	// Bind pflags to viper
-	if err := viper.BindPFlags(pflag.CommandLine); err != nil {
-		slog.Error("Failed to bind flags", "error", err)
-		os.Exit(1)
-	}
-
-	// Set config file path if provided
-	if configFile := viper.GetString("config"); configFile != "" {
-		viper.SetConfigFile(configFile)
-	} else {
-		viper.SetConfigName("multigateway")
-		viper.SetConfigType("yaml")
-		viper.AddConfigPath(".")
-		viper.AddConfigPath("./config")
-		viper.AddConfigPath("/etc/multigres")
-	}
-
-	// Enable environment variables
-	viper.SetEnvPrefix("MULTIGATEWAY")
-	viper.AutomaticEnv()
-
-	// Read config file
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			// Config file was found but another error was produced
-			slog.Error("Error reading config file", "error", err)
-			os.Exit(1)
-		}
-		// Config file not found; ignore error
-	}
-}
-
-func main() {
-	setupConfig()
-
-	// Setup structured logging
-	var level slog.Level
-	switch viper.GetString("log-level") {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
-	}
-
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	}))
-	slog.SetDefault(logger)
+	pflag.String("grpc-port", "15000", "gRPC port to listen on")
+	pflag.String("cell", "", "Cell of the multigateway, to register it in the topology of the cell. Not registered if empty")
+	pflag.String("name", "", "Name of the multigateway in its cell, <hostname>-<grpc-port> if empty")
+	servenv.SetDefaultHTTPPort(15001)
+	servenv.Init("multigateway")
 
+	logger := slog.Default()
 	logger.Info("starting multigateway",
 		"port", viper.GetString("port"),
+		"grpc_port", viper.GetString("grpc-port"),
+		"cell", viper.GetString("cell"),
 		"log_level", viper.GetString("log-level"),
 		"config_file", viper.ConfigFileUsed(),
 	)
 
-	// Create context that cancels on interrupt
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
-
 	// TODO: Initialize Postgres protocol server
 	// TODO: Setup connections to multipoolers
 	// TODO: Implement query routing logic
 
-	logger.Info("multigateway ready to accept connections")
+	registration := register(logger)
+
+	servenv.OnRun(func() {
+		logger.Info("multigateway ready to accept connections")
+	})
+	servenv.OnTerm(func() {
+		if registration != nil {
+			if err := registration.Unregister(context.Background()); err != nil {
+				logger.Error("Failed to unregister the multigateway", "error", err)
+			}
+		}
+	})
+	servenv.Run()
+}
+
+// register registers the multigateway in the topology of its cell, and
+// adds its record to the status page. It returns nil if --cell is not set,
+// and exits if the registration fails.
+func register(logger *slog.Logger) *multigateway.Registration {
+	cell := viper.GetString("cell")
+	if cell == "" {
+		logger.Warn("--cell is not set, multigateway is not registered in the topology")
+		return nil
+	}
+
+	ts := topo.Open()
+	servenv.OnClose(func() { _ = ts.Close() })
+	servenv.AddTopoStatusPart(ts)
 
-	// Wait for shutdown signal
-	<-ctx.Done()
-	logger.Info("shutting down multigateway")
+	hostname, err := os.Hostname()
+	if err != nil {
+		logger.Error("Failed to get the hostname", "error", err)
+		os.Exit(1)
+	}
+	// A multigateway restarted after a crash updates the record it left:
+	// the name must be stable.
+	name := viper.GetString("name")
+	if name == "" {
+		name = fmt.Sprintf("%v-%v", hostname, viper.GetInt("grpc-port"))
+	}
+	record := topo.NewMultiGateway(name, cell, hostname)
+	record.PortMap["postgres"] = int32(viper.GetInt("port"))
+	record.PortMap["grpc"] = int32(viper.GetInt("grpc-port"))
+	if port := servenv.HTTPPort(); port != 0 {
+		record.PortMap["http"] = int32(port)
+	}
+
+	registration, err := multigateway.Register(context.Background(), ts, record)
+	if err != nil {
+		logger.Error("Failed to register the multigateway", "error", err)
+		os.Exit(1)
+	}
+	servenv.AddRecordStatusPart("MultiGateway", func() proto.Message { return registration.Record() })
+	return registration
 }
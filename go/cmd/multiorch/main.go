@@ -19,82 +19,22 @@ limitations under the License.
 package main
 
 import (
-	"context"
 	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/multigres/multigres/go/servenv"
 )
 
-func setupConfig() {
+func main() {
 	// Define flags
 	pflag.StringP("grpc-port", "p", "15300", "gRPC port to listen on")
 	pflag.StringP("topology-addr", "t", "localhost:2379", "etcd topology server address")
-	pflag.StringP("log-level", "l", "info", "Log level (debug, info, warn, error)")
-	pflag.StringP("config", "c", "", "Config file path")
-	pflag.Parse()
-
-	// Setup viper
-	viper.SetDefault("grpc-port", "15300")
-	viper.SetDefault("topology-addr", "localhost:2379")
-	viper.SetDefault("log-level", "info")
-
-	// Bind pflags to viper
-	if err := viper.BindPFlags(pflag.CommandLine); err != nil {
-		slog.Error("Failed to bind flags", "error", err)
-		os.Exit(1)
-	}
-
-	// Set config file path if provided
-	if configFile := viper.GetString("config"); configFile != "" {
-		viper.SetConfigFile(configFile)
-	} else {
-		viper.SetConfigName("multiorch")
-		viper.SetConfigType("yaml")
-		viper.AddConfigPath(".")
-		viper.AddConfigPath("./config")
-		viper.AddConfigPath("/etc/multigres")
-	}
-
-	// Enable environment variables
-	viper.SetEnvPrefix("MULTIORCH")
-	viper.AutomaticEnv()
-
-	// Read config file
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			slog.Error("Error reading config file", "error", err)
-			os.Exit(1)
-		}
-	}
-}
-
-func main() {
-	setupConfig()
-
-	// Setup structured logging
-	var level slog.Level
-	switch viper.GetString("log-level") {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
-	}
-
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	}))
-	slog.SetDefault(logger)
+	servenv.SetDefaultHTTPPort(15301)
+	servenv.Init("multiorch")
 
+	logger := slog.Default()
 	logger.Info("starting multiorch",
 		"grpc_port", viper.GetString("grpc-port"),
 		"topology_addr", viper.GetString("topology-addr"),
@@ -102,18 +42,13 @@ func main() {
 		"config_file", viper.ConfigFileUsed(),
 	)
 
-	// Create context that cancels on interrupt
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
-
 	// TODO: Initialize connection to topology server (etcd)
 	// TODO: Setup consensus protocol management
 	// TODO: Implement failover detection and repair
 	// TODO: Setup health monitoring of multipooler instances
 
-	logger.Info("multiorch ready to orchestrate cluster")
-
-	// Wait for shutdown signal
-	<-ctx.Done()
-	logger.Info("shutting down multiorch")
+	servenv.OnRun(func() {
+		logger.Info("multiorch ready to orchestrate cluster")
+	})
+	servenv.Run()
 }
@@ -20,99 +20,140 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/mterrors"
+	"github.com/multigres/multigres/go/multipooler"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	pgctldservicepb "github.com/multigres/multigres/go/pb/pgctldservice"
+	"github.com/multigres/multigres/go/servenv"
 )
 
-func setupConfig() {
+func main() {
 	// Define flags
 	pflag.StringP("grpc-port", "p", "15100", "gRPC port to listen on")
 	pflag.StringP("pgctld-addr", "a", "localhost:15200", "Address of pgctld gRPC service")
-	pflag.StringP("log-level", "l", "info", "Log level (debug, info, warn, error)")
-	pflag.StringP("config", "c", "", "Config file path")
-	pflag.Parse()
-
-	// Setup viper
-	viper.SetDefault("grpc-port", "15100")
-	viper.SetDefault("pgctld-addr", "localhost:15200")
-	viper.SetDefault("log-level", "info")
-
-	// Bind pflags to viper
-	if err := viper.BindPFlags(pflag.CommandLine); err != nil {
-		slog.Error("Failed to bind flags", "error", err)
+	pflag.String("cell", "", "Cell of the multipooler, to register it in the topology of the cell. Not registered if empty")
+	pflag.String("database", "", "Database served by the multipooler")
+	pflag.String("table-group", "", "Table group served by the multipooler")
+	pflag.String("shard", "", "Shard served by the multipooler")
+	pflag.String("name", "", "Name of the multipooler in its cell, <hostname>-<grpc-port> if empty")
+	pflag.Int("admin-port", 15102, "Port of the HTTP server taking the backups and the restores, on localhost only, 0 to disable. It is not authenticated")
+	servenv.SetDefaultHTTPPort(15101)
+	servenv.Init("multipooler")
+
+	logger := slog.Default()
+	logger.Info("starting multipooler",
+		"grpc_port", viper.GetString("grpc-port"),
+		"pgctld_addr", viper.GetString("pgctld-addr"),
+		"cell", viper.GetString("cell"),
+		"database", viper.GetString("database"),
+		"table_group", viper.GetString("table-group"),
+		"shard", viper.GetString("shard"),
+		"admin_port", viper.GetInt("admin-port"),
+		"log_level", viper.GetString("log-level"),
+		"config_file", viper.ConfigFileUsed(),
+	)
+
+	// TODO: Setup health check endpoint
+
+	conn, err := grpc.NewClient(viper.GetString("pgctld-addr"),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(mterrors.UnaryClientInterceptor),
+	)
+	if err != nil {
+		logger.Error("Failed to create the pgctld client", "error", err)
 		os.Exit(1)
 	}
-
-	// Set config file path if provided
-	if configFile := viper.GetString("config"); configFile != "" {
-		viper.SetConfigFile(configFile)
-	} else {
-		viper.SetConfigName("multipooler")
-		viper.SetConfigType("yaml")
-		viper.AddConfigPath(".")
-		viper.AddConfigPath("./config")
-		viper.AddConfigPath("/etc/multigres")
+	servenv.OnClose(func() { _ = conn.Close() })
+	pgctld := pgctldservicepb.NewPgCtldClient(conn)
+
+	registration := register(logger)
+	if port := viper.GetInt("admin-port"); registration != nil && port != 0 {
+		// The backups go to the backup location of the database, in the
+		// topology. The server is stopped before the multipooler is marked
+		// NOT_SERVING, after the ongoing backups and restores complete.
+		admin := http.NewServeMux()
+		admin.Handle("/backup", multipooler.HandleBackup(registration, pgctld))
+		admin.Handle("/restore", multipooler.HandleRestore(registration, pgctld))
+		servenv.ServeHTTP(admin, "localhost", port)
 	}
 
-	// Enable environment variables
-	viper.SetEnvPrefix("MULTIPOOLER")
-	viper.AutomaticEnv()
-
-	// Read config file
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			slog.Error("Error reading config file", "error", err)
-			os.Exit(1)
+	servenv.OnRun(func() {
+		if registration != nil {
+			setServingStatus(logger, registration, clustermetadatapb.PoolerServingStatus_SERVING)
 		}
-	}
+		logger.Info("multipooler ready to serve connections")
+	})
+	servenv.OnTerm(func() {
+		if registration != nil {
+			setServingStatus(logger, registration, clustermetadatapb.PoolerServingStatus_NOT_SERVING)
+		}
+	})
+	servenv.Run()
 }
 
-func main() {
-	setupConfig()
-
-	// Setup structured logging
-	var level slog.Level
-	switch viper.GetString("log-level") {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
+// register registers the multipooler in the topology of its cell, and
+// adds its record to the status page. It returns nil if --cell is not
+// set, and exits if the registration fails.
+func register(logger *slog.Logger) *multipooler.Registration {
+	cell := viper.GetString("cell")
+	if cell == "" {
+		logger.Warn("--cell is not set, multipooler is not registered in the topology")
+		return nil
+	}
+	if viper.GetString("database") == "" {
+		logger.Error("--database is required to register the multipooler")
+		os.Exit(1)
 	}
 
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	}))
-	slog.SetDefault(logger)
-
-	logger.Info("starting multipooler",
-		"grpc_port", viper.GetString("grpc-port"),
-		"pgctld_addr", viper.GetString("pgctld-addr"),
-		"log_level", viper.GetString("log-level"),
-		"config_file", viper.ConfigFileUsed(),
-	)
-
-	// Create context that cancels on interrupt
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
+	ts := topo.Open()
+	servenv.OnClose(func() { _ = ts.Close() })
+	servenv.AddTopoStatusPart(ts)
 
-	// TODO: Initialize gRPC connection to pgctld
-	// TODO: Setup health check endpoint
-	// TODO: Register with topology service
+	hostname, err := os.Hostname()
+	if err != nil {
+		logger.Error("Failed to get the hostname", "error", err)
+		os.Exit(1)
+	}
+	// The record is kept across restarts: the name must be stable.
+	name := viper.GetString("name")
+	if name == "" {
+		name = fmt.Sprintf("%v-%v", hostname, viper.GetInt("grpc-port"))
+	}
+	record := topo.NewMultiPooler(name, cell, hostname)
+	record.Database = viper.GetString("database")
+	record.TableGroup = viper.GetString("table-group")
+	record.Shard = viper.GetString("shard")
+	record.PortMap["grpc"] = int32(viper.GetInt("grpc-port"))
+	if port := servenv.HTTPPort(); port != 0 {
+		record.PortMap["http"] = int32(port)
+	}
 
-	logger.Info("multipooler ready to serve connections")
+	registration, err := multipooler.Register(context.Background(), ts, record)
+	if err != nil {
+		logger.Error("Failed to register the multipooler", "error", err)
+		os.Exit(1)
+	}
+	servenv.AddRecordStatusPart("MultiPooler", func() proto.Message { return registration.Record() })
+	return registration
+}
 
-	// Wait for shutdown signal
-	<-ctx.Done()
-	logger.Info("shutting down multipooler")
+// setServingStatus updates the serving status of the multipooler in the
+// topology, and logs the failures: the record is updated again at the
+// next transition.
+func setServingStatus(logger *slog.Logger, registration *multipooler.Registration, status clustermetadatapb.PoolerServingStatus) {
+	if err := registration.SetServingStatus(context.Background(), status); err != nil {
+		logger.Error("Failed to update the serving status", "error", err)
+	}
 }
@@ -15,116 +15,75 @@ limitations under the License.
 */
 
 // pgctld provides a gRPC interface for direct communication with PostgreSQL instances,
-// handling query execution and database operations.
+// handling query execution and database operations. It manages the lifecycle of
+// the local PostgreSQL server with the PgCtld service.
 package main
 
 import (
 	"context"
 	"log/slog"
-	"os"
-	"os/signal"
-	"syscall"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+
+	"github.com/multigres/multigres/go/pgctld"
+	"github.com/multigres/multigres/go/servenv"
+
+	// Backup storage implementations.
+	_ "github.com/multigres/multigres/go/pgctld/backupstorage/filebackupstorage"
 )
 
-func setupConfig() {
+func main() {
 	// Define flags
 	pflag.StringP("grpc-port", "g", "15200", "gRPC port to listen on")
-	pflag.StringP("pg-host", "H", "localhost", "PostgreSQL host")
+	pflag.String("grpc-bind-address", "localhost", "Address to listen on for gRPC, all the interfaces if empty. The service is not authenticated: only expose it to trusted clients")
 	pflag.StringP("pg-port", "P", "5432", "PostgreSQL port")
-	pflag.StringP("pg-database", "d", "postgres", "PostgreSQL database name")
 	pflag.StringP("pg-user", "u", "postgres", "PostgreSQL username")
 	pflag.StringP("pg-password", "p", "", "PostgreSQL password")
-	pflag.StringP("log-level", "l", "info", "Log level (debug, info, warn, error)")
-	pflag.StringP("config", "c", "", "Config file path")
-	pflag.Parse()
-
-	// Setup viper
-	viper.SetDefault("grpc-port", "15200")
-	viper.SetDefault("pg-host", "localhost")
-	viper.SetDefault("pg-port", "5432")
-	viper.SetDefault("pg-database", "postgres")
-	viper.SetDefault("pg-user", "postgres")
-	viper.SetDefault("pg-password", "")
-	viper.SetDefault("log-level", "info")
-
-	// Bind pflags to viper
-	if err := viper.BindPFlags(pflag.CommandLine); err != nil {
-		slog.Error("Failed to bind flags", "error", err)
-		os.Exit(1)
-	}
-
-	// Set config file path if provided
-	if configFile := viper.GetString("config"); configFile != "" {
-		viper.SetConfigFile(configFile)
-	} else {
-		viper.SetConfigName("pgctld")
-		viper.SetConfigType("yaml")
-		viper.AddConfigPath(".")
-		viper.AddConfigPath("./config")
-		viper.AddConfigPath("/etc/multigres")
-	}
-
-	// Enable environment variables
-	viper.SetEnvPrefix("PGCTLD")
-	viper.AutomaticEnv()
-
-	// Read config file
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			slog.Error("Error reading config file", "error", err)
-			os.Exit(1)
-		}
-	}
-}
-
-func main() {
-	setupConfig()
-
-	// Setup structured logging
-	var level slog.Level
-	switch viper.GetString("log-level") {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
-	}
-
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: level,
-	}))
-	slog.SetDefault(logger)
-
+	pflag.StringP("pg-data-dir", "D", "", "PostgreSQL data directory")
+	pflag.String("pg-bin-dir", "", "Directory of the PostgreSQL binaries, looked up in the PATH if empty")
+	pflag.String("pg-state-dir", "", "Directory of the files of pgctld out of the data directory: WAL archive, server log, previous configuration files. <pg-data-dir>.pgctld if empty")
+	pflag.Duration("pg-ctl-timeout", time.Minute, "Maximum time to wait for PostgreSQL to start or stop")
+	pflag.String("pg-config-overrides", "", "YAML file overriding postgresql.conf settings and adding pg_hba.conf rules")
+	pflag.Duration("wal-ship-interval", pgctld.DefaultWALShipInterval, "Interval at which the archived WAL is shipped to the last backup")
+	servenv.SetDefaultHTTPPort(15201)
+	servenv.Init("pgctld")
+
+	logger := slog.Default()
 	logger.Info("starting pgctld",
 		"grpc_port", viper.GetString("grpc-port"),
-		"pg_host", viper.GetString("pg-host"),
+		"grpc_bind_address", viper.GetString("grpc-bind-address"),
 		"pg_port", viper.GetString("pg-port"),
-		"pg_database", viper.GetString("pg-database"),
 		"pg_user", viper.GetString("pg-user"),
+		"pg_data_dir", viper.GetString("pg-data-dir"),
+		"pg_bin_dir", viper.GetString("pg-bin-dir"),
+		"pg_state_dir", viper.GetString("pg-state-dir"),
+		"pg_config_overrides", viper.GetString("pg-config-overrides"),
+		"wal_ship_interval", viper.GetDuration("wal-ship-interval"),
 		"log_level", viper.GetString("log-level"),
 		"config_file", viper.ConfigFileUsed(),
 	)
 
-	// Create context that cancels on interrupt
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
-	defer cancel()
-
-	// TODO: Setup gRPC server
-	// TODO: Implement PostgreSQL query interface
-	// TODO: Use pgPassword for database connection
-	_ = viper.GetString("pg-password")
-
-	logger.Info("pgctld ready to serve gRPC requests")
-
-	// Wait for shutdown signal
-	<-ctx.Done()
-	logger.Info("shutting down pgctld")
+	manager := pgctld.NewManager(pgctld.Config{
+		BinDir:        viper.GetString("pg-bin-dir"),
+		DataDir:       viper.GetString("pg-data-dir"),
+		StateDir:      viper.GetString("pg-state-dir"),
+		Port:          viper.GetInt("pg-port"),
+		User:          viper.GetString("pg-user"),
+		Password:      viper.GetString("pg-password"),
+		OverridesFile: viper.GetString("pg-config-overrides"),
+		Timeout:       viper.GetDuration("pg-ctl-timeout"),
+	})
+	grpcServer := servenv.NewGRPCServer()
+	pgctld.RegisterServer(grpcServer, manager)
+	servenv.ServeGRPC(grpcServer, viper.GetString("grpc-bind-address"), viper.GetInt("grpc-port"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	servenv.OnRun(func() {
+		go manager.ShipWAL(ctx, viper.GetDuration("wal-ship-interval"))
+		logger.Info("pgctld ready to serve gRPC requests")
+	})
+	servenv.OnTerm(cancel)
+	servenv.Run()
 }
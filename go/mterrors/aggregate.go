@@ -0,0 +1,161 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// A list of all mtrpc codes, ordered by their priority when aggregating
+// errors: the aggregate of several errors has the code with the highest
+// priority among them. Codes that are more actionable by the caller, or
+// that describe the whole request rather than one of its parts, have a
+// higher priority.
+var errorPriority = map[mtrpcpb.Code]int{
+	mtrpcpb.Code_OK:                  0,
+	mtrpcpb.Code_NOT_FOUND:           1,
+	mtrpcpb.Code_ALREADY_EXISTS:      2,
+	mtrpcpb.Code_OUT_OF_RANGE:        3,
+	mtrpcpb.Code_UNAVAILABLE:         4,
+	mtrpcpb.Code_FAILED_PRECONDITION: 5,
+	mtrpcpb.Code_ABORTED:             6,
+	mtrpcpb.Code_UNIMPLEMENTED:       7,
+	mtrpcpb.Code_INTERNAL:            8,
+	mtrpcpb.Code_UNKNOWN:             9,
+	mtrpcpb.Code_DEADLINE_EXCEEDED:   10,
+	mtrpcpb.Code_RESOURCE_EXHAUSTED:  11,
+	mtrpcpb.Code_CANCELED:            12,
+	mtrpcpb.Code_INVALID_ARGUMENT:    13,
+	mtrpcpb.Code_PERMISSION_DENIED:   14,
+	mtrpcpb.Code_UNAUTHENTICATED:     15,
+	mtrpcpb.Code_CLUSTER_EVENT:       16,
+	mtrpcpb.Code_READ_ONLY:           17,
+	mtrpcpb.Code_DATA_LOSS:           18,
+}
+
+// AggregateCodes returns the code with the highest priority among the
+// codes of errs, or OK if errs is empty.
+func AggregateCodes(errs []error) mtrpcpb.Code {
+	highCode := mtrpcpb.Code_OK
+	for _, e := range errs {
+		code := Code(e)
+		if errorPriority[code] > errorPriority[highCode] {
+			highCode = code
+		}
+	}
+	return highCode
+}
+
+// Aggregate returns a single error combining errs, or nil if errs has
+// no non-nil error. The aggregate has the code with the highest priority,
+// the State of its members if they all have the same, and the sorted
+// and deduplicated messages of its members. errors.Is and errors.As
+// match any of its members.
+func Aggregate(errs []error) error {
+	var members []error
+	for _, err := range errs {
+		if err != nil {
+			members = append(members, err)
+		}
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	return &aggregateError{errs: members}
+}
+
+// aggregateError is the error returned by Aggregate.
+type aggregateError struct {
+	errs []error
+}
+
+// Error is part of the error interface.
+func (e *aggregateError) Error() string {
+	seen := make(map[string]bool, len(e.errs))
+	msgs := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		msg := err.Error()
+		if !seen[msg] {
+			seen[msg] = true
+			msgs = append(msgs, msg)
+		}
+	}
+	// Sort the messages so the ordering is deterministic.
+	sort.Strings(msgs)
+	return strings.Join(msgs, "\n")
+}
+
+// ErrorCode is part of the ErrorWithCode interface.
+func (e *aggregateError) ErrorCode() mtrpcpb.Code {
+	return AggregateCodes(e.errs)
+}
+
+// ErrorState is part of the ErrorWithState interface.
+func (e *aggregateError) ErrorState() State {
+	state := ErrState(e.errs[0])
+	for _, err := range e.errs[1:] {
+		if ErrState(err) != state {
+			return Undefined
+		}
+	}
+	return state
+}
+
+// Unwrap returns the members of the aggregate, for errors.Is and
+// errors.As.
+func (e *aggregateError) Unwrap() []error {
+	return e.errs
+}
+
+// AllErrorRecorder records all the errors reported to it, concurrently.
+// The zero value is ready to use.
+type AllErrorRecorder struct {
+	mu     sync.Mutex
+	errors []error
+}
+
+// RecordError records err, if it is not nil.
+func (aer *AllErrorRecorder) RecordError(err error) {
+	if err == nil {
+		return
+	}
+	aer.mu.Lock()
+	defer aer.mu.Unlock()
+	aer.errors = append(aer.errors, err)
+}
+
+// HasErrors returns true if at least one error was recorded.
+func (aer *AllErrorRecorder) HasErrors() bool {
+	aer.mu.Lock()
+	defer aer.mu.Unlock()
+	return len(aer.errors) > 0
+}
+
+// Errors returns the recorded errors.
+func (aer *AllErrorRecorder) Errors() []error {
+	aer.mu.Lock()
+	defer aer.mu.Unlock()
+	return append([]error(nil), aer.errors...)
+}
+
+// Error returns the Aggregate of the recorded errors, or nil if there
+// are none.
+func (aer *AllErrorRecorder) Error() error {
+	return Aggregate(aer.Errors())
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+func TestAggregateCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		errs []error
+		want mtrpcpb.Code
+	}{
+		{"empty", nil, mtrpcpb.Code_OK},
+		{"single", []error{mterrors.New(mtrpcpb.Code_NOT_FOUND, "a")}, mtrpcpb.Code_NOT_FOUND},
+		{
+			name: "highest priority wins",
+			errs: []error{
+				mterrors.New(mtrpcpb.Code_UNAVAILABLE, "a"),
+				mterrors.New(mtrpcpb.Code_INVALID_ARGUMENT, "b"),
+				mterrors.New(mtrpcpb.Code_NOT_FOUND, "c"),
+			},
+			want: mtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "errors without code are unknown",
+			errs: []error{errors.New("a"), mterrors.New(mtrpcpb.Code_UNAVAILABLE, "b")},
+			want: mtrpcpb.Code_UNKNOWN,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, mterrors.AggregateCodes(tt.errs))
+		})
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	require.NoError(t, mterrors.Aggregate(nil))
+	require.NoError(t, mterrors.Aggregate([]error{nil, nil}))
+
+	pgErr := mterrors.NewPGError(mterrors.UniqueViolation, "duplicate key", "", "")
+	err := mterrors.Aggregate([]error{
+		mterrors.New(mtrpcpb.Code_UNAVAILABLE, "cell zone2 unreachable"),
+		nil,
+		mterrors.Wrap(context.DeadlineExceeded, "cell zone1"),
+		mterrors.New(mtrpcpb.Code_UNAVAILABLE, "cell zone2 unreachable"),
+		mterrors.Wrap(pgErr, "cell zone3"),
+	})
+	require.Error(t, err)
+	require.Equal(t, mtrpcpb.Code_DEADLINE_EXCEEDED, mterrors.Code(err))
+	require.Equal(t, mterrors.Undefined, mterrors.ErrState(err))
+	require.Equal(t, "cell zone1: context deadline exceeded\ncell zone2 unreachable\ncell zone3: duplicate key", err.Error())
+
+	// Every member can be matched.
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	var target *mterrors.PGError
+	require.ErrorAs(t, err, &target)
+	require.Equal(t, mterrors.UniqueViolation, target.State)
+
+	// Matching also works through wrapping.
+	wrapped := mterrors.Wrap(err, "reading cells")
+	require.ErrorIs(t, wrapped, context.DeadlineExceeded)
+	require.Equal(t, mtrpcpb.Code_DEADLINE_EXCEEDED, mterrors.Code(wrapped))
+
+	// A State shared by all the members is kept.
+	err = mterrors.Aggregate([]error{
+		mterrors.NewError(mtrpcpb.Code_ABORTED, mterrors.SerializationFailure, "a"),
+		mterrors.NewError(mtrpcpb.Code_ABORTED, mterrors.SerializationFailure, "b"),
+	})
+	require.Equal(t, mterrors.SerializationFailure, mterrors.ErrState(err))
+}
+
+func TestAllErrorRecorder(t *testing.T) {
+	var rec mterrors.AllErrorRecorder
+	require.False(t, rec.HasErrors())
+	require.NoError(t, rec.Error())
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if i%2 == 0 {
+				rec.RecordError(nil)
+				return
+			}
+			rec.RecordError(mterrors.Errorf(mtrpcpb.Code_UNAVAILABLE, "cell%d unreachable", i))
+		}()
+	}
+	wg.Wait()
+
+	require.True(t, rec.HasErrors())
+	require.Len(t, rec.Errors(), 5)
+	err := rec.Error()
+	require.Equal(t, mtrpcpb.Code_UNAVAILABLE, mterrors.Code(err))
+	for i := 1; i < 10; i += 2 {
+		require.Contains(t, err.Error(), fmt.Sprintf("cell%d unreachable", i))
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors
+
+import (
+	"expvar"
+	"strings"
+	"sync"
+)
+
+// This file counts the errors returned by the processes, whenever they
+// cross an RPC or SQL boundary. The gRPC server interceptors count the
+// errors they convert, with the gRPC service as component. Other
+// boundaries, like the SQL protocol of the gateway, call CountError.
+//
+// The counts are exported as the ErrorCounts expvar, keyed by
+// "component.CODE.state", and sent to the hooks added with AddErrorHook.
+
+// ErrorCountsName is the name of the expvar holding the error counts.
+const ErrorCountsName = "ErrorCounts"
+
+// ErrorCountsLabels are the labels of the ErrorCounts keys, in order.
+var ErrorCountsLabels = []string{"component", "code", "state"}
+
+var errorCounts = expvar.NewMap(ErrorCountsName)
+
+// ErrorHook is called for every error counted by CountError.
+type ErrorHook func(component string, err error)
+
+var (
+	// errorHooksMu protects errorHooks.
+	errorHooksMu sync.RWMutex
+	errorHooks   []ErrorHook
+)
+
+// AddErrorHook adds a hook called for every error counted.
+func AddErrorHook(hook ErrorHook) {
+	errorHooksMu.Lock()
+	defer errorHooksMu.Unlock()
+	errorHooks = append(errorHooks, hook)
+}
+
+// CountError counts err, if not nil, as returned by component through
+// one of its boundaries.
+func CountError(component string, err error) {
+	if err == nil {
+		return
+	}
+	errorCounts.Add(errorCountsKey(component, err), 1)
+
+	errorHooksMu.RLock()
+	defer errorHooksMu.RUnlock()
+	for _, hook := range errorHooks {
+		hook(component, err)
+	}
+}
+
+// ErrorCount returns the number of errors with the same code and State
+// as err counted for component.
+func ErrorCount(component string, err error) int64 {
+	if v, ok := errorCounts.Get(errorCountsKey(component, err)).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// errorCountsKey returns the ErrorCounts key of err. Dots are removed
+// from the component, as they separate the labels.
+func errorCountsKey(component string, err error) string {
+	return strings.Join([]string{
+		strings.ReplaceAll(component, ".", "_"),
+		Code(err).String(),
+		ErrState(err).String(),
+	}, ".")
+}
+
+// grpcComponent returns the component of a gRPC method: the name of its
+// service, without the package.
+func grpcComponent(fullMethod string) string {
+	service := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(service, "/"); i >= 0 {
+		service = service[:i]
+	}
+	if i := strings.LastIndex(service, "."); i >= 0 {
+		service = service[i+1:]
+	}
+	return service
+}
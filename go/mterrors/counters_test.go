@@ -0,0 +1,65 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors_test
+
+import (
+	"encoding/json"
+	"expvar"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+func TestCountError(t *testing.T) {
+	var mu sync.Mutex
+	var hooked []string
+	mterrors.AddErrorHook(func(component string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		hooked = append(hooked, component+": "+err.Error())
+	})
+
+	unique := mterrors.NewPGError(mterrors.UniqueViolation, "duplicate key", "", "")
+	unavailable := mterrors.New(mtrpcpb.Code_UNAVAILABLE, "pooler down")
+
+	mterrors.CountError("sql.test", unique)
+	mterrors.CountError("sql.test", mterrors.Wrap(unique, "executing"))
+	mterrors.CountError("sql.test", unavailable)
+	mterrors.CountError("sql.test", nil)
+
+	require.Equal(t, int64(2), mterrors.ErrorCount("sql.test", unique))
+	require.Equal(t, int64(1), mterrors.ErrorCount("sql.test", unavailable))
+	require.Zero(t, mterrors.ErrorCount("other", unavailable))
+
+	mu.Lock()
+	require.Equal(t, []string{
+		"sql.test: duplicate key",
+		"sql.test: executing: duplicate key",
+		"sql.test: pooler down",
+	}, hooked)
+	mu.Unlock()
+
+	// The counts are exported with one key per component, code and state.
+	v := expvar.Get(mterrors.ErrorCountsName)
+	require.NotNil(t, v)
+	var counts map[string]int64
+	require.NoError(t, json.Unmarshal([]byte(v.String()), &counts))
+	require.Equal(t, int64(2), counts["sql_test.ALREADY_EXISTS.unique_violation"])
+	require.Equal(t, int64(1), counts["sql_test.UNAVAILABLE.undefined"])
+}
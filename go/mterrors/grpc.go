@@ -0,0 +1,177 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors
+
+import (
+	"context"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// This file contains the conversion of errors to and from gRPC statuses.
+//
+// The mtrpc codes 0 to 16 are the gRPC codes. The Multigres-specific ones
+// are sent as the closest gRPC code, so that any gRPC client handles them
+// sensibly. The exact code, along with the State, travels in an RPCError
+// detail of the status, which FromGRPC uses when present.
+
+// CodeToGRPC returns the gRPC code used to send an error with code.
+func CodeToGRPC(code mtrpcpb.Code) codes.Code {
+	switch code {
+	case mtrpcpb.Code_CLUSTER_EVENT:
+		// A cluster operation is in progress, the call can be retried.
+		return codes.Unavailable
+	case mtrpcpb.Code_READ_ONLY:
+		return codes.FailedPrecondition
+	}
+	if code < mtrpcpb.Code_OK || code > mtrpcpb.Code_UNAUTHENTICATED {
+		return codes.Unknown
+	}
+	return codes.Code(code)
+}
+
+// CodeFromGRPC returns the mtrpc code of a gRPC code.
+func CodeFromGRPC(code codes.Code) mtrpcpb.Code {
+	if code > codes.Unauthenticated {
+		return mtrpcpb.Code_UNKNOWN
+	}
+	return mtrpcpb.Code(code)
+}
+
+// ToGRPC returns err as a gRPC status error. The status has the code of
+// err, its full message, and an RPCError detail with its code, State and
+// redacted message.
+// Errors without a code that already carry a gRPC status, for instance
+// ones returned by a gRPC client without interceptors, keep its code.
+func ToGRPC(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code := Code(err)
+	if code == mtrpcpb.Code_UNKNOWN {
+		if st, ok := status.FromError(err); ok {
+			code = CodeFromGRPC(st.Code())
+		}
+	}
+	msg := err.Error()
+	st := status.New(CodeToGRPC(code), msg)
+	detailed, detailErr := st.WithDetails(&mtrpcpb.RPCError{
+		Message:         msg,
+		Code:            code,
+		State:           int32(ErrState(err)),
+		RedactedMessage: Redacted(err).Error(),
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// FromGRPC returns the error received from a gRPC call as an mterrors
+// error with the same message, code, State and redacted message. Errors that are not gRPC
+// statuses, like io.EOF at the end of a stream, are returned unchanged.
+func FromGRPC(err error) error {
+	if err == nil || err == io.EOF {
+		return err
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	code := CodeFromGRPC(st.Code())
+	state := Undefined
+	// Without details, the peer is not a Multigres component, and its
+	// message can't be returned to external clients.
+	redactedMsg := genericMessage(code)
+	for _, detail := range st.Details() {
+		if rpcErr, ok := detail.(*mtrpcpb.RPCError); ok {
+			code = rpcErr.Code
+			state = State(rpcErr.State)
+			redactedMsg = rpcErr.RedactedMessage
+			break
+		}
+	}
+	return &fundamental{
+		msg:         st.Message(),
+		redactedMsg: redactedMsg,
+		code:        code,
+		state:       state,
+		stack:       callers(),
+	}
+}
+
+// UnaryServerInterceptor is a grpc.UnaryServerInterceptor converting the
+// errors returned by the handlers with ToGRPC. It counts them with
+// CountError, the gRPC service being the component.
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	CountError(grpcComponent(info.FullMethod), err)
+	return resp, ToGRPC(err)
+}
+
+// StreamServerInterceptor is a grpc.StreamServerInterceptor converting
+// the errors returned by the handlers with ToGRPC. It counts them with
+// CountError, the gRPC service being the component.
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	CountError(grpcComponent(info.FullMethod), err)
+	return ToGRPC(err)
+}
+
+// UnaryClientInterceptor is a grpc.UnaryClientInterceptor converting the
+// errors returned by the calls with FromGRPC.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return FromGRPC(invoker(ctx, method, req, reply, cc, opts...))
+}
+
+// StreamClientInterceptor is a grpc.StreamClientInterceptor converting
+// the errors returned by the streams with FromGRPC.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	cs, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, FromGRPC(err)
+	}
+	return &clientStream{ClientStream: cs}, nil
+}
+
+// clientStream converts the errors of a grpc.ClientStream with FromGRPC.
+type clientStream struct {
+	grpc.ClientStream
+}
+
+func (cs *clientStream) Header() (metadata.MD, error) {
+	md, err := cs.ClientStream.Header()
+	return md, FromGRPC(err)
+}
+
+func (cs *clientStream) CloseSend() error {
+	return FromGRPC(cs.ClientStream.CloseSend())
+}
+
+func (cs *clientStream) SendMsg(m any) error {
+	return FromGRPC(cs.ClientStream.SendMsg(m))
+}
+
+func (cs *clientStream) RecvMsg(m any) error {
+	return FromGRPC(cs.ClientStream.RecvMsg(m))
+}
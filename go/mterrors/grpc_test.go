@@ -0,0 +1,141 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+func TestToGRPC(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantGRPC codes.Code
+		wantCode mtrpcpb.Code
+	}{
+		{"plain code", mterrors.New(mtrpcpb.Code_NOT_FOUND, "no such table"), codes.NotFound, mtrpcpb.Code_NOT_FOUND},
+		{"wrapped", mterrors.Wrap(mterrors.New(mtrpcpb.Code_ABORTED, "conflict"), "update"), codes.Aborted, mtrpcpb.Code_ABORTED},
+		{"multigres code", mterrors.New(mtrpcpb.Code_READ_ONLY, "read-only"), codes.FailedPrecondition, mtrpcpb.Code_READ_ONLY},
+		{"cluster event", mterrors.New(mtrpcpb.Code_CLUSTER_EVENT, "reparenting"), codes.Unavailable, mtrpcpb.Code_CLUSTER_EVENT},
+		{"no code", errors.New("boom"), codes.Unknown, mtrpcpb.Code_UNKNOWN},
+		{"context", context.DeadlineExceeded, codes.DeadlineExceeded, mtrpcpb.Code_DEADLINE_EXCEEDED},
+		{"grpc status", status.Error(codes.PermissionDenied, "denied"), codes.PermissionDenied, mtrpcpb.Code_PERMISSION_DENIED},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grpcErr := mterrors.ToGRPC(tt.err)
+			st, ok := status.FromError(grpcErr)
+			require.True(t, ok)
+			require.Equal(t, tt.wantGRPC, st.Code())
+			require.Equal(t, tt.err.Error(), st.Message())
+
+			back := mterrors.FromGRPC(grpcErr)
+			require.Equal(t, tt.wantCode, mterrors.Code(back))
+			require.Equal(t, tt.err.Error(), back.Error())
+		})
+	}
+
+	require.NoError(t, mterrors.ToGRPC(nil))
+	require.NoError(t, mterrors.FromGRPC(nil))
+	require.Equal(t, io.EOF, mterrors.FromGRPC(io.EOF))
+}
+
+func TestFromGRPCWithoutDetails(t *testing.T) {
+	err := mterrors.FromGRPC(status.Error(codes.ResourceExhausted, "too many connections"))
+	require.Equal(t, mtrpcpb.Code_RESOURCE_EXHAUSTED, mterrors.Code(err))
+	require.Equal(t, mterrors.Undefined, mterrors.ErrState(err))
+	require.Equal(t, "too many connections", err.Error())
+}
+
+// healthServer returns err from all its calls.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	err error
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	return nil, s.err
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	if err := stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}); err != nil {
+		return err
+	}
+	return s.err
+}
+
+func TestInterceptors(t *testing.T) {
+	returned := mterrors.Wrap(mterrors.NewError(mtrpcpb.Code_INVALID_ARGUMENT, mterrors.BadFieldError, "unknown column"), "executing query")
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(mterrors.UnaryServerInterceptor),
+		grpc.StreamInterceptor(mterrors.StreamServerInterceptor),
+	)
+	healthpb.RegisterHealthServer(server, &healthServer{err: returned})
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(mterrors.UnaryClientInterceptor),
+		grpc.WithStreamInterceptor(mterrors.StreamClientInterceptor),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	ctx := context.Background()
+	countBefore := mterrors.ErrorCount("Health", returned)
+
+	check := func(err error) {
+		t.Helper()
+		require.Error(t, err)
+		require.Equal(t, returned.Error(), err.Error())
+		require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+		require.Equal(t, mterrors.BadFieldError, mterrors.ErrState(err))
+	}
+
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	check(err)
+
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+	_, err = stream.Recv()
+	check(err)
+
+	// Both errors were counted by the server interceptors.
+	require.Equal(t, countBefore+2, mterrors.ErrorCount("Health", returned))
+}
@@ -70,6 +70,13 @@
 // causer interface is not exported by this package, but is considered a part
 // of stable public API.
 //
+// # Redacting errors for external clients
+//
+// Format arguments wrapped with mterrors.Sensitive are printed in full by
+// Error() and in the logs, but replaced by a placeholder in the error
+// returned by mterrors.Redacted, which also drops the context added by
+// Wrap. Only redacted errors should be returned to SQL clients.
+//
 // # Formatted printing of errors
 //
 // All error values returned from this package implement fmt.Formatter and can
@@ -127,9 +134,10 @@ func RegisterFlags(fs *pflag.FlagSet) {
 // New also records the stack trace at the point it was called.
 func New(code mtrpcpb.Code, message string) error {
 	return &fundamental{
-		msg:   message,
-		code:  code,
-		stack: callers(),
+		msg:         message,
+		redactedMsg: message,
+		code:        code,
+		stack:       callers(),
 	}
 }
 
@@ -139,9 +147,10 @@ func New(code mtrpcpb.Code, message string) error {
 // Use this for Multigres-specific errors that don't have a PostgreSQL counterpart
 func Errorf(code mtrpcpb.Code, format string, args ...any) error {
 	return &fundamental{
-		msg:   fmt.Sprintf(format, args...),
-		code:  code,
-		stack: callers(),
+		msg:         fmt.Sprintf(format, args...),
+		redactedMsg: redactedSprintf(format, args...),
+		code:        code,
+		stack:       callers(),
 	}
 }
 
@@ -150,7 +159,13 @@ func Errorf(code mtrpcpb.Code, format string, args ...any) error {
 // NewErrorf also records the stack trace at the point it was called.
 // Use this for errors in Multigres that we eventually want to mimic as a PostgreSQL error
 func NewErrorf(code mtrpcpb.Code, state State, format string, args ...any) error {
-	return NewError(code, state, fmt.Sprintf(format, args...))
+	return &fundamental{
+		msg:         fmt.Sprintf(format, args...),
+		redactedMsg: redactedSprintf(format, args...),
+		code:        code,
+		state:       state,
+		stack:       callers(),
+	}
 }
 
 // NewErrorf formats according to a format specifier and returns the string
@@ -159,18 +174,21 @@ func NewErrorf(code mtrpcpb.Code, state State, format string, args ...any) error
 // Use this for errors in Multigres that we eventually want to mimic as a PostgreSQL error
 func NewError(code mtrpcpb.Code, state State, msg string) error {
 	return &fundamental{
-		msg:   msg,
-		code:  code,
-		state: state,
-		stack: callers(),
+		msg:         msg,
+		redactedMsg: msg,
+		code:        code,
+		state:       state,
+		stack:       callers(),
 	}
 }
 
 // fundamental is an error that has a message and a stack, but no caller.
 type fundamental struct {
-	msg   string
-	code  mtrpcpb.Code
-	state State
+	msg string
+	// redactedMsg is msg with the Sensitive values redacted.
+	redactedMsg string
+	code        mtrpcpb.Code
+	state       State
 	*stack
 }
 
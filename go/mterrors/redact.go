@@ -0,0 +1,129 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// This file implements the redaction of error messages returned to
+// external clients, like the SQL clients of the gateway.
+//
+// Values that must not leave the cluster, like server addresses, paths or
+// credentials, are marked with Sensitive when formatting a message:
+//
+//	mterrors.Errorf(code, "cannot reach %v", mterrors.Sensitive(addr))
+//
+// Error() and the logs keep the full message. Redacted returns the error
+// to send to external clients: sensitive values are replaced with
+// RedactedPlaceholder, and the context added by Wrap is dropped, as it
+// describes the internals of Multigres.
+
+// RedactedPlaceholder replaces the sensitive values in redacted messages.
+const RedactedPlaceholder = "<redacted>"
+
+// sensitiveValue is a format argument that is redacted.
+type sensitiveValue struct {
+	value any
+}
+
+// Sensitive marks a format argument of Errorf or NewErrorf as sensitive.
+// It is formatted as the value itself, except in the message of Redacted.
+func Sensitive(value any) fmt.Formatter {
+	return sensitiveValue{value: value}
+}
+
+// Format implements fmt.Formatter.
+func (s sensitiveValue) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), s.value)
+}
+
+// redactedValue formats as RedactedPlaceholder, whatever the verb.
+type redactedValue struct{}
+
+// Format implements fmt.Formatter.
+func (redactedValue) Format(f fmt.State, verb rune) {
+	panicIfError(io.WriteString(f, RedactedPlaceholder))
+}
+
+// redactedSprintf formats like fmt.Sprintf, with the Sensitive arguments
+// replaced by RedactedPlaceholder.
+func redactedSprintf(format string, args ...any) string {
+	var redacted []any
+	for i, arg := range args {
+		if _, ok := arg.(sensitiveValue); ok {
+			if redacted == nil {
+				redacted = append([]any(nil), args...)
+			}
+			redacted[i] = redactedValue{}
+		}
+	}
+	if redacted == nil {
+		return fmt.Sprintf(format, args...)
+	}
+	return fmt.Sprintf(format, redacted...)
+}
+
+// Redacted returns the form of err that can be returned to external
+// clients. It has the code and State of err, and the message of its root
+// cause with the Sensitive values redacted. Errors received from
+// PostgreSQL are returned as is. Errors that don't come from this package
+// only keep a generic message describing their code, as their message
+// may contain anything.
+func Redacted(err error) error {
+	if err == nil {
+		return nil
+	}
+	code, state := Code(err), ErrState(err)
+	switch root := RootCause(err).(type) {
+	case *fundamental:
+		return &fundamental{
+			msg:         root.redactedMsg,
+			redactedMsg: root.redactedMsg,
+			code:        code,
+			state:       state,
+			stack:       root.stack,
+		}
+	case *PGError:
+		return root
+	case *aggregateError:
+		redacted := make([]error, len(root.errs))
+		for i, member := range root.errs {
+			redacted[i] = Redacted(member)
+		}
+		return Aggregate(redacted)
+	default:
+		msg := genericMessage(code)
+		return &fundamental{
+			msg:         msg,
+			redactedMsg: msg,
+			code:        code,
+			state:       state,
+			stack:       callers(),
+		}
+	}
+}
+
+// genericMessage returns a message describing code, like "not found".
+func genericMessage(code mtrpcpb.Code) string {
+	if code == mtrpcpb.Code_UNKNOWN {
+		return "unknown error"
+	}
+	return strings.ToLower(strings.ReplaceAll(code.String(), "_", " "))
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+func TestSensitiveFormatting(t *testing.T) {
+	// Sensitive values are formatted as the value itself, with the verb
+	// and flags of the format.
+	require.Equal(t, `"10.0.0.1:2379" 0042 [a b]`, fmt.Sprintf("%q %04d %v",
+		mterrors.Sensitive("10.0.0.1:2379"), mterrors.Sensitive(42), mterrors.Sensitive([]string{"a", "b"})))
+}
+
+func TestRedacted(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantFull     string
+		wantRedacted string
+		wantCode     mtrpcpb.Code
+	}{
+		{
+			name:         "sensitive values",
+			err:          mterrors.Errorf(mtrpcpb.Code_UNAVAILABLE, "cannot reach %v for cell %v", mterrors.Sensitive("10.0.0.1:2379"), "zone1"),
+			wantFull:     "cannot reach 10.0.0.1:2379 for cell zone1",
+			wantRedacted: "cannot reach <redacted> for cell zone1",
+			wantCode:     mtrpcpb.Code_UNAVAILABLE,
+		},
+		{
+			name: "wrap context is dropped",
+			err: mterrors.Wrapf(
+				mterrors.NewErrorf(mtrpcpb.Code_NOT_FOUND, mterrors.UndefinedTable, "relation %q does not exist", "users"),
+				"failed to create topo connection to %v, %v", mterrors.Sensitive("10.0.0.1:2379"), mterrors.Sensitive("/multigres")),
+			wantFull:     `failed to create topo connection to 10.0.0.1:2379, /multigres: relation "users" does not exist`,
+			wantRedacted: `relation "users" does not exist`,
+			wantCode:     mtrpcpb.Code_NOT_FOUND,
+		},
+		{
+			name:         "foreign errors get a generic message",
+			err:          mterrors.Wrap(topo.NewError(topo.NoNode, "/multigres/cells/zone1"), "reading cell"),
+			wantFull:     "reading cell: topo error [1]: node doesn't exist: /multigres/cells/zone1",
+			wantRedacted: "not found",
+			wantCode:     mtrpcpb.Code_NOT_FOUND,
+		},
+		{
+			name:         "plain errors",
+			err:          errors.New("open /etc/multigres/secret: permission denied"),
+			wantFull:     "open /etc/multigres/secret: permission denied",
+			wantRedacted: "unknown error",
+			wantCode:     mtrpcpb.Code_UNKNOWN,
+		},
+		{
+			name:         "postgres errors are kept",
+			err:          mterrors.Wrap(mterrors.NewPGError(mterrors.UniqueViolation, "duplicate key", "Key (id)=(1) already exists.", ""), "executing on pooler"),
+			wantFull:     "executing on pooler: duplicate key",
+			wantRedacted: "duplicate key",
+			wantCode:     mtrpcpb.Code_ALREADY_EXISTS,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.wantFull, tt.err.Error())
+			redacted := mterrors.Redacted(tt.err)
+			require.Equal(t, tt.wantRedacted, redacted.Error())
+			require.Equal(t, tt.wantCode, mterrors.Code(redacted))
+			require.Equal(t, mterrors.ErrState(tt.err), mterrors.ErrState(redacted))
+		})
+	}
+
+	require.NoError(t, mterrors.Redacted(nil))
+
+	// Detail and hint of PostgreSQL errors are kept.
+	resp := mterrors.ToErrorResponse(mterrors.Redacted(tests[4].err))
+	require.Equal(t, "Key (id)=(1) already exists.", resp.Detail)
+}
+
+func TestRedactedAggregate(t *testing.T) {
+	err := mterrors.Aggregate([]error{
+		mterrors.Errorf(mtrpcpb.Code_UNAVAILABLE, "zone1 at %v is down", mterrors.Sensitive("10.0.0.1")),
+		mterrors.Wrap(mterrors.New(mtrpcpb.Code_UNAVAILABLE, "zone2 is down"), "reading zone2"),
+	})
+	redacted := mterrors.Redacted(err)
+	require.Equal(t, "zone1 at <redacted> is down\nzone2 is down", redacted.Error())
+	require.Equal(t, mtrpcpb.Code_UNAVAILABLE, mterrors.Code(redacted))
+}
+
+func TestRedactedThroughGRPC(t *testing.T) {
+	err := mterrors.Wrap(mterrors.Errorf(mtrpcpb.Code_UNAVAILABLE, "pooler %v is down", mterrors.Sensitive("10.0.0.2:5432")), "executing")
+
+	received := mterrors.FromGRPC(mterrors.ToGRPC(err))
+	require.Equal(t, "executing: pooler 10.0.0.2:5432 is down", received.Error())
+	require.Equal(t, "pooler <redacted> is down", mterrors.Redacted(received).Error())
+
+	// Statuses from other peers are fully redacted.
+	received = mterrors.FromGRPC(status.Error(codes.Unavailable, "connection refused to 10.0.0.3"))
+	require.Equal(t, "unavailable", mterrors.Redacted(received).Error())
+}
@@ -0,0 +1,136 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// This file classifies errors following the litmus test of mtrpc.proto:
+//
+//   - UNAVAILABLE: the failing call can be retried as is. So can the
+//     other transient conditions: CLUSTER_EVENT, RESOURCE_EXHAUSTED and
+//     DEADLINE_EXCEEDED, unless the deadline is the caller's own.
+//   - ABORTED: the caller should retry at a higher level, for instance
+//     by restarting a read-modify-write sequence from a fresh read.
+//   - FAILED_PRECONDITION and all the other codes: the caller should not
+//     retry until the state of the system has been fixed.
+//
+// Errors of other packages are classified through their code, for
+// instance a topo BadVersion error is ABORTED and a topo Timeout is
+// DEADLINE_EXCEEDED.
+
+// IsRetryable returns true if the call that returned err can be retried
+// as is, after a backoff.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	// The caller gave up: its context is done.
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	switch Code(err) {
+	case mtrpcpb.Code_UNAVAILABLE,
+		mtrpcpb.Code_CLUSTER_EVENT,
+		mtrpcpb.Code_RESOURCE_EXHAUSTED,
+		mtrpcpb.Code_DEADLINE_EXCEEDED:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsRetryableAtHigherLevel returns true if the call that returned err
+// must not be retried as is, but the sequence of calls it belongs to
+// can be restarted, for instance after a concurrent modification.
+func IsRetryableAtHigherLevel(err error) bool {
+	return err != nil && Code(err) == mtrpcpb.Code_ABORTED
+}
+
+// RetryPolicy configures Retry.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of calls, including the first
+	// one. Zero means retrying until the context is done.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry. It doubles
+	// with every retry, up to MaxBackoff. Each wait is randomized
+	// between half and all of its value. When MaxAttempts is zero, the
+	// backoff is at least MinUnboundedBackoff, so that Retry doesn't spin.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// RetryAtHigherLevel also retries the errors for which
+	// IsRetryableAtHigherLevel is true. Set it when the retried
+	// function is a full read-modify-write sequence.
+	RetryAtHigherLevel bool
+}
+
+// MinUnboundedBackoff is the minimum backoff of the policies without
+// MaxAttempts.
+const MinUnboundedBackoff = 10 * time.Millisecond
+
+// DefaultRetryPolicy is a reasonable policy for calls to other
+// components or to the topology.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 50 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+}
+
+// Retry calls fn until it succeeds, returns an error that is not
+// retryable according to policy, the attempts are exhausted, or ctx is
+// done. It returns the last error returned by fn.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	backoff := policy.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if !IsRetryable(err) && (!policy.RetryAtHigherLevel || !IsRetryableAtHigherLevel(err)) {
+			return err
+		}
+		if policy.MaxAttempts > 0 && attempt >= policy.MaxAttempts {
+			return err
+		}
+
+		if policy.MaxAttempts <= 0 && backoff < MinUnboundedBackoff {
+			backoff = MinUnboundedBackoff
+		}
+		wait := backoff
+		if wait > 0 {
+			wait = wait/2 + rand.N(wait/2+1)
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+func TestRetryableCodes(t *testing.T) {
+	tests := []struct {
+		code        mtrpcpb.Code
+		retryable   bool
+		higherLevel bool
+	}{
+		{code: mtrpcpb.Code_OK},
+		{code: mtrpcpb.Code_CANCELED},
+		{code: mtrpcpb.Code_UNKNOWN},
+		{code: mtrpcpb.Code_INVALID_ARGUMENT},
+		{code: mtrpcpb.Code_DEADLINE_EXCEEDED, retryable: true},
+		{code: mtrpcpb.Code_NOT_FOUND},
+		{code: mtrpcpb.Code_ALREADY_EXISTS},
+		{code: mtrpcpb.Code_PERMISSION_DENIED},
+		{code: mtrpcpb.Code_RESOURCE_EXHAUSTED, retryable: true},
+		{code: mtrpcpb.Code_FAILED_PRECONDITION},
+		{code: mtrpcpb.Code_ABORTED, higherLevel: true},
+		{code: mtrpcpb.Code_OUT_OF_RANGE},
+		{code: mtrpcpb.Code_UNIMPLEMENTED},
+		{code: mtrpcpb.Code_INTERNAL},
+		{code: mtrpcpb.Code_UNAVAILABLE, retryable: true},
+		{code: mtrpcpb.Code_DATA_LOSS},
+		{code: mtrpcpb.Code_UNAUTHENTICATED},
+		{code: mtrpcpb.Code_CLUSTER_EVENT, retryable: true},
+		{code: mtrpcpb.Code_READ_ONLY},
+	}
+	require.Len(t, tests, len(mtrpcpb.Code_name), "every code must be classified")
+	for _, tt := range tests {
+		t.Run(tt.code.String(), func(t *testing.T) {
+			err := mterrors.Wrap(mterrors.New(tt.code, "failed"), "calling")
+			require.Equal(t, tt.retryable, mterrors.IsRetryable(err))
+			require.Equal(t, tt.higherLevel, mterrors.IsRetryableAtHigherLevel(err))
+		})
+	}
+}
+
+func TestRetryableErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		retryable   bool
+		higherLevel bool
+	}{
+		{name: "nil"},
+		{name: "plain", err: errors.New("boom")},
+		{name: "canceled context", err: context.Canceled},
+		{name: "expired context", err: mterrors.Wrap(context.DeadlineExceeded, "reading")},
+		{name: "topo no node", err: topo.NewError(topo.NoNode, "cells/zone1")},
+		{name: "topo node exists", err: topo.NewError(topo.NodeExists, "cells/zone1")},
+		{name: "topo bad version", err: topo.NewError(topo.BadVersion, "cells/zone1"), higherLevel: true},
+		{name: "topo timeout", err: topo.NewError(topo.Timeout, "cells/zone1"), retryable: true},
+		{name: "topo resource exhausted", err: topo.NewError(topo.ResourceExhausted, "cells/zone1"), retryable: true},
+		{name: "topo partial result", err: topo.NewError(topo.PartialResult, "cells"), retryable: true},
+		{name: "topo interrupted", err: topo.NewError(topo.Interrupted, "cells/zone1")},
+		{name: "serialization failure", err: mterrors.FromErrorResponse(&mterrors.ErrorResponse{Code: "40001"}), higherLevel: true},
+		{name: "too many connections", err: mterrors.FromErrorResponse(&mterrors.ErrorResponse{Code: "53300"}), retryable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.retryable, mterrors.IsRetryable(tt.err))
+			require.Equal(t, tt.higherLevel, mterrors.IsRetryableAtHigherLevel(tt.err))
+		})
+	}
+}
+
+var testRetryPolicy = mterrors.RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     2 * time.Millisecond,
+}
+
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	unavailable := mterrors.New(mtrpcpb.Code_UNAVAILABLE, "unavailable")
+	notFound := mterrors.New(mtrpcpb.Code_NOT_FOUND, "missing")
+	aborted := topo.NewError(topo.BadVersion, "cells/zone1")
+
+	tests := []struct {
+		name         string
+		policy       mterrors.RetryPolicy
+		errs         []error
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "success", policy: testRetryPolicy, wantAttempts: 1},
+		{name: "transient", policy: testRetryPolicy, errs: []error{unavailable, unavailable}, wantAttempts: 3},
+		{name: "exhausted", policy: testRetryPolicy, errs: []error{unavailable, unavailable, unavailable, unavailable, unavailable}, wantErr: unavailable, wantAttempts: 4},
+		{name: "permanent", policy: testRetryPolicy, errs: []error{unavailable, notFound}, wantErr: notFound, wantAttempts: 2},
+		{name: "aborted not retried", policy: testRetryPolicy, errs: []error{aborted}, wantErr: aborted, wantAttempts: 1},
+		{
+			name: "aborted retried at higher level",
+			policy: mterrors.RetryPolicy{
+				MaxAttempts:        4,
+				InitialBackoff:     time.Millisecond,
+				RetryAtHigherLevel: true,
+			},
+			errs:         []error{aborted, unavailable},
+			wantAttempts: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := mterrors.Retry(ctx, tt.policy, func(ctx context.Context) error {
+				attempts++
+				if attempts <= len(tt.errs) {
+					return tt.errs[attempts-1]
+				}
+				return nil
+			})
+			require.Equal(t, tt.wantAttempts, attempts)
+			require.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestRetryContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	unavailable := mterrors.New(mtrpcpb.Code_UNAVAILABLE, "unavailable")
+
+	attempts := 0
+	err := mterrors.Retry(ctx, mterrors.RetryPolicy{InitialBackoff: 5 * time.Millisecond}, func(ctx context.Context) error {
+		attempts++
+		return unavailable
+	})
+	require.Equal(t, unavailable, err)
+	require.Greater(t, attempts, 1)
+	require.Error(t, ctx.Err())
+}
+
+func TestRetryUnboundedMinBackoff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	unavailable := mterrors.New(mtrpcpb.Code_UNAVAILABLE, "unavailable")
+
+	// Without backoff nor attempt limit, Retry still waits between calls.
+	attempts := 0
+	err := mterrors.Retry(ctx, mterrors.RetryPolicy{}, func(ctx context.Context) error {
+		attempts++
+		return unavailable
+	})
+	require.Equal(t, unavailable, err)
+	require.LessOrEqual(t, attempts, int(50*time.Millisecond/(mterrors.MinUnboundedBackoff/2))+1)
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+)
+
+// This file makes the errors of this package slog.LogValuer, so that
+// logging them, for instance with slog.Any("error", err), produces a
+// group instead of a flat string:
+//
+//	{"message": "...", "code": "NOT_FOUND", "state": "undefined_table",
+//	 "wrap": ["outer context", "inner context"], "stack": ["..."]}
+//
+// wrap lists the messages added by Wrap, from the outermost one, and is
+// omitted for errors that are not wrapped. stack is only present when
+// log_err_stacks is set.
+
+var (
+	_ slog.LogValuer = (*fundamental)(nil)
+	_ slog.LogValuer = (*wrapping)(nil)
+	_ slog.LogValuer = (*aggregateError)(nil)
+	_ slog.LogValuer = (*PGError)(nil)
+)
+
+// LogValue implements slog.LogValuer.
+func (f *fundamental) LogValue() slog.Value {
+	return logValue(f)
+}
+
+// LogValue implements slog.LogValuer.
+func (w *wrapping) LogValue() slog.Value {
+	return logValue(w)
+}
+
+// LogValue implements slog.LogValuer.
+func (e *aggregateError) LogValue() slog.Value {
+	attrs := commonAttrs(e)
+	members := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		members[i] = err.Error()
+	}
+	attrs = append(attrs, slog.Any("errors", members))
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer.
+func (e *PGError) LogValue() slog.Value {
+	return logValue(e)
+}
+
+// logValue returns the group describing err.
+func logValue(err error) slog.Value {
+	attrs := commonAttrs(err)
+
+	// Walk the wrap chain, down to the root cause. The stack logged is
+	// the innermost one, as close as possible to the origin of the error.
+	var wrap []string
+	var st *stack
+	cause := err
+	for {
+		w, ok := cause.(*wrapping)
+		if !ok {
+			break
+		}
+		wrap = append(wrap, w.msg)
+		if w.stack != nil {
+			st = w.stack
+		}
+		cause = w.cause
+	}
+	if f, ok := cause.(*fundamental); ok && f.stack != nil {
+		st = f.stack
+	}
+	if pgErr, ok := cause.(*PGError); ok {
+		attrs = append(attrs, pgErrorAttrs(pgErr)...)
+	}
+
+	if len(wrap) > 0 {
+		attrs = append(attrs, slog.Any("wrap", wrap))
+	}
+	if st != nil && getLogErrStacks() {
+		attrs = append(attrs, slog.Any("stack", stackFrames(st)))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// commonAttrs returns the attributes logged for every error.
+func commonAttrs(err error) []slog.Attr {
+	return []slog.Attr{
+		slog.String("message", err.Error()),
+		slog.String("code", Code(err).String()),
+		slog.String("state", ErrState(err).String()),
+	}
+}
+
+// pgErrorAttrs returns the ErrorResponse fields of a PGError.
+func pgErrorAttrs(e *PGError) []slog.Attr {
+	attrs := []slog.Attr{slog.String("sqlstate", e.Code)}
+	if e.Detail != "" {
+		attrs = append(attrs, slog.String("detail", e.Detail))
+	}
+	if e.Hint != "" {
+		attrs = append(attrs, slog.String("hint", e.Hint))
+	}
+	return attrs
+}
+
+// stackFrames renders the frames of a stack as "function file:line".
+func stackFrames(s *stack) []string {
+	frames := make([]string, 0, len(*s))
+	for _, f := range s.StackTrace() {
+		name := "unknown"
+		if fn := runtime.FuncForPC(f.pc()); fn != nil {
+			name = fn.Name()
+		}
+		frames = append(frames, fmt.Sprintf("%s %s:%d", name, f.file(), f.line()))
+	}
+	return frames
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// loggedError logs err with a JSON handler and returns the "error" group.
+func loggedError(t *testing.T, err error) map[string]any {
+	t.Helper()
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	logger.Error("query failed", "error", err)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	group, ok := line["error"].(map[string]any)
+	require.True(t, ok, "error is not logged as a group: %s", buf.String())
+	return group
+}
+
+// setLogErrStacks sets log_err_stacks for the duration of the test.
+func setLogErrStacks(t *testing.T, value string) {
+	t.Helper()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	mterrors.RegisterFlags(fs)
+	require.NoError(t, fs.Set("log_err_stacks", value))
+	t.Cleanup(func() {
+		require.NoError(t, fs.Set("log_err_stacks", "false"))
+	})
+}
+
+func TestLogValue(t *testing.T) {
+	setLogErrStacks(t, "false")
+	err := mterrors.NewErrorf(mtrpcpb.Code_NOT_FOUND, mterrors.UndefinedTable, "relation %q does not exist", "users")
+	err = mterrors.Wrapf(mterrors.Wrap(err, "planning"), "executing on %v", "db1")
+
+	group := loggedError(t, err)
+	require.Equal(t, `executing on db1: planning: relation "users" does not exist`, group["message"])
+	require.Equal(t, "NOT_FOUND", group["code"])
+	require.Equal(t, "undefined_table", group["state"])
+	require.Equal(t, []any{"executing on db1", "planning"}, group["wrap"])
+	require.NotContains(t, group, "stack")
+
+	// Unwrapped errors have no wrap chain.
+	group = loggedError(t, mterrors.New(mtrpcpb.Code_INTERNAL, "boom"))
+	require.Equal(t, "boom", group["message"])
+	require.Equal(t, "INTERNAL", group["code"])
+	require.Equal(t, "undefined", group["state"])
+	require.NotContains(t, group, "wrap")
+}
+
+func TestLogValueStack(t *testing.T) {
+	setLogErrStacks(t, "true")
+	err := mterrors.Wrap(mterrors.New(mtrpcpb.Code_INTERNAL, "boom"), "context")
+
+	group := loggedError(t, err)
+	stack, ok := group["stack"].([]any)
+	require.True(t, ok, "stack is not an array: %v", group["stack"])
+	require.NotEmpty(t, stack)
+	require.True(t, strings.HasPrefix(stack[0].(string), "github.com/multigres/multigres/go/mterrors_test.TestLogValueStack "), stack[0])
+	require.Contains(t, stack[0], "slog_test.go:")
+}
+
+func TestLogValuePGAndAggregate(t *testing.T) {
+	setLogErrStacks(t, "false")
+	pgErr := mterrors.FromErrorResponse(&mterrors.ErrorResponse{
+		Severity: "ERROR",
+		Code:     "23505",
+		Message:  "duplicate key",
+		Detail:   "Key (id)=(1) already exists.",
+	})
+	group := loggedError(t, mterrors.Wrap(pgErr, "inserting"))
+	require.Equal(t, "ALREADY_EXISTS", group["code"])
+	require.Equal(t, "unique_violation", group["state"])
+	require.Equal(t, "23505", group["sqlstate"])
+	require.Equal(t, "Key (id)=(1) already exists.", group["detail"])
+	require.Equal(t, []any{"inserting"}, group["wrap"])
+
+	agg := mterrors.Aggregate([]error{
+		mterrors.New(mtrpcpb.Code_UNAVAILABLE, "zone1 down"),
+		mterrors.New(mtrpcpb.Code_NOT_FOUND, "zone2 missing"),
+	})
+	group = loggedError(t, agg)
+	require.Equal(t, "UNAVAILABLE", group["code"])
+	require.Equal(t, []any{"zone1 down", "zone2 missing"}, group["errors"])
+}
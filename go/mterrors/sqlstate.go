@@ -0,0 +1,292 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors
+
+import (
+	"errors"
+	"fmt"
+
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// This file maps the States to PostgreSQL SQLSTATE codes, and converts
+// errors to and from the fields of a PostgreSQL ErrorResponse message.
+// See https://www.postgresql.org/docs/current/errcodes-appendix.html.
+
+// stateInfo describes a State.
+type stateInfo struct {
+	// name is the PostgreSQL condition name.
+	name string
+	// sqlState is the five-character SQLSTATE code.
+	sqlState string
+	// code is the mtrpc code of errors with that state.
+	code mtrpcpb.Code
+}
+
+// states describes all the States but Undefined.
+var states = map[State]stateInfo{
+	BadFieldError: {"undefined_column", "42703", mtrpcpb.Code_INVALID_ARGUMENT},
+
+	ConnectionException: {"connection_exception", "08000", mtrpcpb.Code_UNAVAILABLE},
+	ConnectionFailure:   {"connection_failure", "08006", mtrpcpb.Code_UNAVAILABLE},
+	ProtocolViolation:   {"protocol_violation", "08P01", mtrpcpb.Code_INVALID_ARGUMENT},
+
+	FeatureNotSupported: {"feature_not_supported", "0A000", mtrpcpb.Code_UNIMPLEMENTED},
+
+	CardinalityViolation: {"cardinality_violation", "21000", mtrpcpb.Code_INVALID_ARGUMENT},
+
+	DataException:             {"data_exception", "22000", mtrpcpb.Code_INVALID_ARGUMENT},
+	StringDataRightTruncation: {"string_data_right_truncation", "22001", mtrpcpb.Code_INVALID_ARGUMENT},
+	NumericValueOutOfRange:    {"numeric_value_out_of_range", "22003", mtrpcpb.Code_OUT_OF_RANGE},
+	DivisionByZero:            {"division_by_zero", "22012", mtrpcpb.Code_INVALID_ARGUMENT},
+	InvalidParameterValue:     {"invalid_parameter_value", "22023", mtrpcpb.Code_INVALID_ARGUMENT},
+	InvalidTextRepresentation: {"invalid_text_representation", "22P02", mtrpcpb.Code_INVALID_ARGUMENT},
+
+	IntegrityConstraintViolation: {"integrity_constraint_violation", "23000", mtrpcpb.Code_FAILED_PRECONDITION},
+	NotNullViolation:             {"not_null_violation", "23502", mtrpcpb.Code_FAILED_PRECONDITION},
+	ForeignKeyViolation:          {"foreign_key_violation", "23503", mtrpcpb.Code_FAILED_PRECONDITION},
+	UniqueViolation:              {"unique_violation", "23505", mtrpcpb.Code_ALREADY_EXISTS},
+	CheckViolation:               {"check_violation", "23514", mtrpcpb.Code_FAILED_PRECONDITION},
+	ExclusionViolation:           {"exclusion_violation", "23P01", mtrpcpb.Code_FAILED_PRECONDITION},
+
+	InvalidTransactionState:         {"invalid_transaction_state", "25000", mtrpcpb.Code_FAILED_PRECONDITION},
+	ActiveSQLTransaction:            {"active_sql_transaction", "25001", mtrpcpb.Code_FAILED_PRECONDITION},
+	ReadOnlySQLTransaction:          {"read_only_sql_transaction", "25006", mtrpcpb.Code_FAILED_PRECONDITION},
+	InFailedSQLTransaction:          {"in_failed_sql_transaction", "25P02", mtrpcpb.Code_FAILED_PRECONDITION},
+	IdleInTransactionSessionTimeout: {"idle_in_transaction_session_timeout", "25P03", mtrpcpb.Code_ABORTED},
+
+	InvalidAuthorizationSpecification: {"invalid_authorization_specification", "28000", mtrpcpb.Code_UNAUTHENTICATED},
+	InvalidPassword:                   {"invalid_password", "28P01", mtrpcpb.Code_UNAUTHENTICATED},
+
+	InvalidCatalogName: {"invalid_catalog_name", "3D000", mtrpcpb.Code_NOT_FOUND},
+
+	InvalidSchemaName: {"invalid_schema_name", "3F000", mtrpcpb.Code_NOT_FOUND},
+
+	TransactionRollback:  {"transaction_rollback", "40000", mtrpcpb.Code_ABORTED},
+	SerializationFailure: {"serialization_failure", "40001", mtrpcpb.Code_ABORTED},
+	DeadlockDetected:     {"deadlock_detected", "40P01", mtrpcpb.Code_ABORTED},
+
+	SyntaxErrorOrAccessRuleViolation: {"syntax_error_or_access_rule_violation", "42000", mtrpcpb.Code_INVALID_ARGUMENT},
+	SyntaxError:                      {"syntax_error", "42601", mtrpcpb.Code_INVALID_ARGUMENT},
+	InsufficientPrivilege:            {"insufficient_privilege", "42501", mtrpcpb.Code_PERMISSION_DENIED},
+	AmbiguousColumn:                  {"ambiguous_column", "42702", mtrpcpb.Code_INVALID_ARGUMENT},
+	UndefinedFunction:                {"undefined_function", "42883", mtrpcpb.Code_INVALID_ARGUMENT},
+	UndefinedObject:                  {"undefined_object", "42704", mtrpcpb.Code_NOT_FOUND},
+	DatatypeMismatch:                 {"datatype_mismatch", "42804", mtrpcpb.Code_INVALID_ARGUMENT},
+	UndefinedTable:                   {"undefined_table", "42P01", mtrpcpb.Code_NOT_FOUND},
+	DuplicateDatabase:                {"duplicate_database", "42P04", mtrpcpb.Code_ALREADY_EXISTS},
+	DuplicateTable:                   {"duplicate_table", "42P07", mtrpcpb.Code_ALREADY_EXISTS},
+	DuplicateObject:                  {"duplicate_object", "42710", mtrpcpb.Code_ALREADY_EXISTS},
+
+	InsufficientResources: {"insufficient_resources", "53000", mtrpcpb.Code_RESOURCE_EXHAUSTED},
+	DiskFull:              {"disk_full", "53100", mtrpcpb.Code_RESOURCE_EXHAUSTED},
+	OutOfMemory:           {"out_of_memory", "53200", mtrpcpb.Code_RESOURCE_EXHAUSTED},
+	TooManyConnections:    {"too_many_connections", "53300", mtrpcpb.Code_RESOURCE_EXHAUSTED},
+
+	ProgramLimitExceeded: {"program_limit_exceeded", "54000", mtrpcpb.Code_RESOURCE_EXHAUSTED},
+
+	ObjectNotInPrerequisiteState: {"object_not_in_prerequisite_state", "55000", mtrpcpb.Code_FAILED_PRECONDITION},
+	LockNotAvailable:             {"lock_not_available", "55P03", mtrpcpb.Code_ABORTED},
+
+	OperatorIntervention: {"operator_intervention", "57000", mtrpcpb.Code_UNAVAILABLE},
+	QueryCanceled:        {"query_canceled", "57014", mtrpcpb.Code_CANCELED},
+	AdminShutdown:        {"admin_shutdown", "57P01", mtrpcpb.Code_UNAVAILABLE},
+	CrashShutdown:        {"crash_shutdown", "57P02", mtrpcpb.Code_UNAVAILABLE},
+	CannotConnectNow:     {"cannot_connect_now", "57P03", mtrpcpb.Code_UNAVAILABLE},
+	DatabaseDropped:      {"database_dropped", "57P04", mtrpcpb.Code_NOT_FOUND},
+
+	SystemError: {"system_error", "58000", mtrpcpb.Code_INTERNAL},
+	IOError:     {"io_error", "58030", mtrpcpb.Code_INTERNAL},
+
+	InternalError: {"internal_error", "XX000", mtrpcpb.Code_INTERNAL},
+	DataCorrupted: {"data_corrupted", "XX001", mtrpcpb.Code_DATA_LOSS},
+}
+
+// sqlStates maps SQLSTATE codes to States.
+var sqlStates = func() map[string]State {
+	m := make(map[string]State, len(states))
+	for state, info := range states {
+		m[info.sqlState] = state
+	}
+	return m
+}()
+
+// codeSQLStates is the SQLSTATE reported for errors with an mtrpc code
+// and no State. Codes that are not listed use internal_error.
+var codeSQLStates = map[mtrpcpb.Code]State{
+	mtrpcpb.Code_CANCELED:            QueryCanceled,
+	mtrpcpb.Code_INVALID_ARGUMENT:    InvalidParameterValue,
+	mtrpcpb.Code_DEADLINE_EXCEEDED:   QueryCanceled,
+	mtrpcpb.Code_NOT_FOUND:           UndefinedObject,
+	mtrpcpb.Code_ALREADY_EXISTS:      DuplicateObject,
+	mtrpcpb.Code_PERMISSION_DENIED:   InsufficientPrivilege,
+	mtrpcpb.Code_RESOURCE_EXHAUSTED:  InsufficientResources,
+	mtrpcpb.Code_FAILED_PRECONDITION: ObjectNotInPrerequisiteState,
+	mtrpcpb.Code_ABORTED:             TransactionRollback,
+	mtrpcpb.Code_OUT_OF_RANGE:        NumericValueOutOfRange,
+	mtrpcpb.Code_UNIMPLEMENTED:       FeatureNotSupported,
+	mtrpcpb.Code_UNAVAILABLE:         CannotConnectNow,
+	mtrpcpb.Code_DATA_LOSS:           DataCorrupted,
+	mtrpcpb.Code_UNAUTHENTICATED:     InvalidAuthorizationSpecification,
+	mtrpcpb.Code_CLUSTER_EVENT:       CannotConnectNow,
+	mtrpcpb.Code_READ_ONLY:           ReadOnlySQLTransaction,
+}
+
+// String returns the PostgreSQL condition name of the state.
+func (s State) String() string {
+	if info, ok := states[s]; ok {
+		return info.name
+	}
+	if s == Undefined {
+		return "undefined"
+	}
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+// SQLState returns the SQLSTATE code of the state, or an empty string
+// for Undefined.
+func (s State) SQLState() string {
+	return states[s].sqlState
+}
+
+// Code returns the mtrpc code of errors with the state, UNKNOWN for
+// Undefined.
+func (s State) Code() mtrpcpb.Code {
+	if info, ok := states[s]; ok {
+		return info.code
+	}
+	return mtrpcpb.Code_UNKNOWN
+}
+
+// StateFromSQLState returns the State of a SQLSTATE code. Unknown codes
+// get the State of their class, like data_exception for 22xxx, or
+// Undefined if the class is unknown too.
+func StateFromSQLState(sqlState string) State {
+	if state, ok := sqlStates[sqlState]; ok {
+		return state
+	}
+	if len(sqlState) == 5 {
+		if state, ok := sqlStates[sqlState[:2]+"000"]; ok {
+			return state
+		}
+	}
+	return Undefined
+}
+
+// Severities of an ErrorResponse.
+const (
+	SeverityError = "ERROR"
+	SeverityFatal = "FATAL"
+	SeverityPanic = "PANIC"
+)
+
+// ErrorResponse holds the fields of a PostgreSQL ErrorResponse message.
+type ErrorResponse struct {
+	// Severity is ERROR, FATAL or PANIC.
+	Severity string
+	// Code is the SQLSTATE code.
+	Code string
+	// Message is the primary human-readable error message.
+	Message string
+	// Detail is an optional secondary message with more details.
+	Detail string
+	// Hint is an optional suggestion about what to do about the problem.
+	Hint string
+}
+
+// PGError is an error received from, or meant to be sent to, a
+// PostgreSQL client or server. It keeps all the ErrorResponse fields,
+// including a SQLSTATE code that may not have a State.
+type PGError struct {
+	ErrorResponse
+	// State is the State of the SQLSTATE code.
+	State State
+}
+
+// Ensure PGError carries a code and a state at compile time.
+var (
+	_ ErrorWithCode  = (*PGError)(nil)
+	_ ErrorWithState = (*PGError)(nil)
+)
+
+// Error is part of the error interface.
+func (e *PGError) Error() string {
+	return e.Message
+}
+
+// ErrorCode is part of the ErrorWithCode interface.
+func (e *PGError) ErrorCode() mtrpcpb.Code {
+	return e.State.Code()
+}
+
+// ErrorState is part of the ErrorWithState interface.
+func (e *PGError) ErrorState() State {
+	return e.State
+}
+
+// NewPGError returns an error with the given state, rendered by
+// ToErrorResponse with the given detail and hint, which may be empty.
+func NewPGError(state State, message, detail, hint string) error {
+	return &PGError{
+		ErrorResponse: ErrorResponse{
+			Severity: SeverityError,
+			Code:     state.SQLState(),
+			Message:  message,
+			Detail:   detail,
+			Hint:     hint,
+		},
+		State: state,
+	}
+}
+
+// FromErrorResponse returns the error described by an ErrorResponse.
+// Its code is derived from the SQLSTATE.
+func FromErrorResponse(resp *ErrorResponse) error {
+	return &PGError{
+		ErrorResponse: *resp,
+		State:         StateFromSQLState(resp.Code),
+	}
+}
+
+// ToErrorResponse renders err as an ErrorResponse. Errors received
+// through FromErrorResponse, even wrapped, are rendered as they were
+// received. Other errors have the SQLSTATE of their State or, if they
+// don't have one, of their code, and their full message.
+func ToErrorResponse(err error) *ErrorResponse {
+	var pgErr *PGError
+	if errors.As(err, &pgErr) {
+		resp := pgErr.ErrorResponse
+		if resp.Severity == "" {
+			resp.Severity = SeverityError
+		}
+		if resp.Code == "" {
+			resp.Code = InternalError.SQLState()
+		}
+		return &resp
+	}
+
+	state := ErrState(err)
+	if state.SQLState() == "" {
+		state = InternalError
+		if codeState, ok := codeSQLStates[Code(err)]; ok {
+			state = codeState
+		}
+	}
+	return &ErrorResponse{
+		Severity: SeverityError,
+		Code:     state.SQLState(),
+		Message:  err.Error(),
+	}
+}
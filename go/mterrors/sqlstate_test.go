@@ -0,0 +1,127 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mterrors_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+func TestStateCatalog(t *testing.T) {
+	seen := make(map[string]mterrors.State)
+	for state := mterrors.BadFieldError; state <= mterrors.DataCorrupted; state++ {
+		sqlState := state.SQLState()
+		require.Len(t, sqlState, 5, "state %d", state)
+		require.NotContains(t, seen, sqlState, "duplicate SQLSTATE for %v", state)
+		seen[sqlState] = state
+		require.Equal(t, state, mterrors.StateFromSQLState(sqlState))
+		require.NotEqual(t, mtrpcpb.Code_UNKNOWN, state.Code(), "state %v", state)
+	}
+
+	require.Empty(t, mterrors.Undefined.SQLState())
+	require.Equal(t, mtrpcpb.Code_UNKNOWN, mterrors.Undefined.Code())
+	require.Equal(t, "unique_violation", mterrors.UniqueViolation.String())
+	require.Equal(t, "undefined_column", mterrors.BadFieldError.String())
+}
+
+func TestStateFromSQLState(t *testing.T) {
+	tests := []struct {
+		sqlState string
+		want     mterrors.State
+		wantCode mtrpcpb.Code
+	}{
+		{"42P01", mterrors.UndefinedTable, mtrpcpb.Code_NOT_FOUND},
+		{"23505", mterrors.UniqueViolation, mtrpcpb.Code_ALREADY_EXISTS},
+		{"40001", mterrors.SerializationFailure, mtrpcpb.Code_ABORTED},
+		{"57014", mterrors.QueryCanceled, mtrpcpb.Code_CANCELED},
+		{"53300", mterrors.TooManyConnections, mtrpcpb.Code_RESOURCE_EXHAUSTED},
+		// Unknown codes fall back to their class.
+		{"22008", mterrors.DataException, mtrpcpb.Code_INVALID_ARGUMENT},
+		{"40003", mterrors.TransactionRollback, mtrpcpb.Code_ABORTED},
+		{"P0001", mterrors.Undefined, mtrpcpb.Code_UNKNOWN},
+		{"", mterrors.Undefined, mtrpcpb.Code_UNKNOWN},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.want, mterrors.StateFromSQLState(tt.sqlState), tt.sqlState)
+		require.Equal(t, tt.wantCode, mterrors.StateFromSQLState(tt.sqlState).Code(), tt.sqlState)
+	}
+}
+
+func TestErrorResponseRoundTrip(t *testing.T) {
+	resp := &mterrors.ErrorResponse{
+		Severity: mterrors.SeverityError,
+		Code:     "23505",
+		Message:  `duplicate key value violates unique constraint "users_pkey"`,
+		Detail:   "Key (id)=(1) already exists.",
+	}
+	err := mterrors.FromErrorResponse(resp)
+	require.Equal(t, resp.Message, err.Error())
+	require.Equal(t, mtrpcpb.Code_ALREADY_EXISTS, mterrors.Code(err))
+	require.Equal(t, mterrors.UniqueViolation, mterrors.ErrState(err))
+
+	// The response is rendered back as received, even once wrapped.
+	wrapped := mterrors.Wrap(err, "executing insert")
+	require.Equal(t, mtrpcpb.Code_ALREADY_EXISTS, mterrors.Code(wrapped))
+	require.Equal(t, resp, mterrors.ToErrorResponse(wrapped))
+
+	// Unknown SQLSTATE codes are kept.
+	custom := &mterrors.ErrorResponse{Severity: mterrors.SeverityError, Code: "P0001", Message: "raised", Hint: "check the trigger"}
+	require.Equal(t, custom, mterrors.ToErrorResponse(mterrors.FromErrorResponse(custom)))
+}
+
+func TestToErrorResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want *mterrors.ErrorResponse
+	}{
+		{
+			name: "state",
+			err:  mterrors.NewErrorf(mtrpcpb.Code_NOT_FOUND, mterrors.UndefinedTable, "relation %q does not exist", "users"),
+			want: &mterrors.ErrorResponse{Severity: "ERROR", Code: "42P01", Message: `relation "users" does not exist`},
+		},
+		{
+			name: "detail and hint",
+			err:  mterrors.NewPGError(mterrors.TooManyConnections, "too many clients", "pool is full", "retry later"),
+			want: &mterrors.ErrorResponse{Severity: "ERROR", Code: "53300", Message: "too many clients", Detail: "pool is full", Hint: "retry later"},
+		},
+		{
+			name: "code only",
+			err:  mterrors.Wrap(mterrors.Errorf(mtrpcpb.Code_DEADLINE_EXCEEDED, "query timed out"), "executing"),
+			want: &mterrors.ErrorResponse{Severity: "ERROR", Code: "57014", Message: "executing: query timed out"},
+		},
+		{
+			name: "context",
+			err:  context.Canceled,
+			want: &mterrors.ErrorResponse{Severity: "ERROR", Code: "57014", Message: "context canceled"},
+		},
+		{
+			name: "plain",
+			err:  errors.New("boom"),
+			want: &mterrors.ErrorResponse{Severity: "ERROR", Code: "XX000", Message: "boom"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, mterrors.ToErrorResponse(tt.err))
+		})
+	}
+}
@@ -16,16 +16,110 @@ package mterrors
 
 import mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
 
-// State represents an error state that we will later use to mimic PostgreSQL errors
+// State represents an error state that we use to mimic PostgreSQL errors.
+// Each State maps to a SQLSTATE code, see sqlstate.go.
+//
+// States are sent as integers in RPCs, so new states must be appended to
+// this list, never inserted.
 type State int
 
 // All the error states
 const (
 	// Undefined is the default error state for errors that don't have a specific state
 	Undefined State = iota
-	// invalid argument
+	// BadFieldError is an unknown column, undefined_column (42703).
 	BadFieldError
-	// We can keep porting errors from Vitess here as we go.
+
+	// Class 08 - Connection Exception
+	ConnectionException
+	ConnectionFailure
+	ProtocolViolation
+
+	// Class 0A - Feature Not Supported
+	FeatureNotSupported
+
+	// Class 21 - Cardinality Violation
+	CardinalityViolation
+
+	// Class 22 - Data Exception
+	DataException
+	StringDataRightTruncation
+	NumericValueOutOfRange
+	DivisionByZero
+	InvalidParameterValue
+	InvalidTextRepresentation
+
+	// Class 23 - Integrity Constraint Violation
+	IntegrityConstraintViolation
+	NotNullViolation
+	ForeignKeyViolation
+	UniqueViolation
+	CheckViolation
+	ExclusionViolation
+
+	// Class 25 - Invalid Transaction State
+	InvalidTransactionState
+	ActiveSQLTransaction
+	ReadOnlySQLTransaction
+	InFailedSQLTransaction
+	IdleInTransactionSessionTimeout
+
+	// Class 28 - Invalid Authorization Specification
+	InvalidAuthorizationSpecification
+	InvalidPassword
+
+	// Class 3D - Invalid Catalog Name
+	InvalidCatalogName
+
+	// Class 3F - Invalid Schema Name
+	InvalidSchemaName
+
+	// Class 40 - Transaction Rollback
+	TransactionRollback
+	SerializationFailure
+	DeadlockDetected
+
+	// Class 42 - Syntax Error or Access Rule Violation
+	SyntaxErrorOrAccessRuleViolation
+	SyntaxError
+	InsufficientPrivilege
+	AmbiguousColumn
+	UndefinedFunction
+	UndefinedObject
+	DatatypeMismatch
+	UndefinedTable
+	DuplicateDatabase
+	DuplicateTable
+	DuplicateObject
+
+	// Class 53 - Insufficient Resources
+	InsufficientResources
+	DiskFull
+	OutOfMemory
+	TooManyConnections
+
+	// Class 54 - Program Limit Exceeded
+	ProgramLimitExceeded
+
+	// Class 55 - Object Not In Prerequisite State
+	ObjectNotInPrerequisiteState
+	LockNotAvailable
+
+	// Class 57 - Operator Intervention
+	OperatorIntervention
+	QueryCanceled
+	AdminShutdown
+	CrashShutdown
+	CannotConnectNow
+	DatabaseDropped
+
+	// Class 58 - System Error
+	SystemError
+	IOError
+
+	// Class XX - Internal Error
+	InternalError
+	DataCorrupted
 )
 
 // ErrorWithCode is an interface for errors that have an associated error code
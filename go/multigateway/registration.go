@@ -0,0 +1,70 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package multigateway contains the components of the multigateway binary.
+package multigateway
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+)
+
+// Registration is the record of a multigateway in the topology of its
+// cell, through which load balancers and tools discover it. Register
+// creates it, and Unregister deletes it on graceful shutdown. A
+// multigateway restarted after a crash, with the same name, updates the
+// record it left.
+type Registration struct {
+	ts     topo.Store
+	record *clustermetadatapb.MultiGateway
+}
+
+// Register creates or updates the record of the multigateway in the
+// topology of its cell.
+func Register(ctx context.Context, ts topo.Store, record *clustermetadatapb.MultiGateway) (*Registration, error) {
+	record = proto.Clone(record).(*clustermetadatapb.MultiGateway)
+	err := mterrors.Retry(ctx, mterrors.DefaultRetryPolicy, func(ctx context.Context) error {
+		return ts.InitMultiGateway(ctx, record, true /* allowUpdate */)
+	})
+	if err != nil {
+		return nil, mterrors.Wrapf(err, "failed to register multigateway %v", topo.MultiGatewayIDString(record.Id))
+	}
+	slog.Info("Registered multigateway in the topology", "id", topo.MultiGatewayIDString(record.Id), "port_map", record.PortMap)
+	return &Registration{ts: ts, record: record}, nil
+}
+
+// Record returns a copy of the record of the multigateway.
+func (r *Registration) Record() *clustermetadatapb.MultiGateway {
+	return proto.Clone(r.record).(*clustermetadatapb.MultiGateway)
+}
+
+// Unregister deletes the record of the multigateway from the topology.
+// It succeeds if the record was already deleted.
+func (r *Registration) Unregister(ctx context.Context) error {
+	err := mterrors.Retry(ctx, mterrors.DefaultRetryPolicy, func(ctx context.Context) error {
+		return r.ts.DeleteMultiGateway(ctx, r.record.Id)
+	})
+	if err != nil && !errors.Is(err, &topo.TopoError{Code: topo.NoNode}) {
+		return mterrors.Wrapf(err, "failed to unregister multigateway %v", topo.MultiGatewayIDString(r.record.Id))
+	}
+	slog.Info("Unregistered multigateway from the topology", "id", topo.MultiGatewayIDString(r.record.Id))
+	return nil
+}
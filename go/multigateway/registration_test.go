@@ -0,0 +1,78 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multigateway_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+	"github.com/multigres/multigres/go/multigateway"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+)
+
+func newRecord(postgresPort int32) *clustermetadatapb.MultiGateway {
+	record := topo.NewMultiGateway("gateway1", "zone1", "host1")
+	record.PortMap["postgres"] = postgresPort
+	record.PortMap["grpc"] = 15000
+	return record
+}
+
+func TestRegistration(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	// The multigateway is discoverable once registered.
+	registration, err := multigateway.Register(ctx, ts, newRecord(5432))
+	require.NoError(t, err)
+	gateways, err := ts.GetMultiGatewaysByCell(ctx, "zone1")
+	require.NoError(t, err)
+	require.Len(t, gateways, 1)
+	require.Equal(t, "host1", gateways[0].Hostname)
+	require.Equal(t, map[string]int32{"postgres": 5432, "grpc": 15000}, gateways[0].PortMap)
+
+	// The record is deleted on shutdown, even twice.
+	require.NoError(t, registration.Unregister(ctx))
+	gateways, err = ts.GetMultiGatewaysByCell(ctx, "zone1")
+	require.NoError(t, err)
+	require.Empty(t, gateways)
+	require.NoError(t, registration.Unregister(ctx))
+
+	// A restarted multigateway registers again, and one restarted after a
+	// crash updates the record it left.
+	_, err = multigateway.Register(ctx, ts, newRecord(5432))
+	require.NoError(t, err)
+	_, err = multigateway.Register(ctx, ts, newRecord(6432))
+	require.NoError(t, err)
+	gateways, err = ts.GetMultiGatewaysByCell(ctx, "zone1")
+	require.NoError(t, err)
+	require.Len(t, gateways, 1)
+	require.Equal(t, int32(6432), gateways[0].PortMap["postgres"])
+}
+
+func TestRegistrationUnknownCell(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	record := newRecord(5432)
+	record.Id.Cell = "zone2"
+	_, err := multigateway.Register(ctx, ts, record)
+	require.ErrorContains(t, err, "failed to register multigateway multigateway-zone2-gateway1")
+}
@@ -0,0 +1,183 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipooler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"path"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	pgctldservicepb "github.com/multigres/multigres/go/pb/pgctldservice"
+)
+
+// backupTarget returns the backup location of the database of the
+// multipooler, and the directory of the backups of its shard.
+func (r *Registration) backupTarget(ctx context.Context) (string, string, error) {
+	record := r.Record()
+	db, err := r.ts.GetDatabase(ctx, record.Database)
+	if err != nil {
+		return "", "", mterrors.Wrapf(err, "failed to get database %v", record.Database)
+	}
+	if db.BackupLocation == "" {
+		return "", "", mterrors.Errorf(mtrpcpb.Code_FAILED_PRECONDITION, "database %v has no backup location", record.Database)
+	}
+	return db.BackupLocation, path.Join(record.Database, record.TableGroup, record.Shard), nil
+}
+
+// withServingStatus runs f with the multipooler in status, and restores
+// its previous status after, unless the multipooler transitioned
+// meanwhile, like to NOT_SERVING on shutdown.
+func (r *Registration) withServingStatus(ctx context.Context, status clustermetadatapb.PoolerServingStatus, f func() error) error {
+	previous := r.Record().ServingStatus
+	if err := r.SetServingStatus(ctx, status); err != nil {
+		return err
+	}
+	err := f()
+	if restoreErr := r.swapServingStatus(context.WithoutCancel(ctx), status, previous); restoreErr != nil {
+		slog.Error("Failed to restore the serving status", "serving_status", previous.String(), "error", restoreErr)
+		if err == nil {
+			err = restoreErr
+		}
+	}
+	return err
+}
+
+// Backup takes a backup of the PostgreSQL server of the multipooler with
+// pgctld, to the backup location of its database. The multipooler is in
+// the BACKUP serving status while the backup runs.
+func Backup(ctx context.Context, r *Registration, client pgctldservicepb.PgCtldClient) (*pgctldatapb.BackupManifest, error) {
+	location, dir, err := r.backupTarget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var manifest *pgctldatapb.BackupManifest
+	err = r.withServingStatus(ctx, clustermetadatapb.PoolerServingStatus_BACKUP, func() error {
+		resp, err := client.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: dir})
+		if err != nil {
+			return mterrors.Wrap(err, "backup failed")
+		}
+		manifest = resp.Manifest
+		return nil
+	})
+	return manifest, err
+}
+
+// Restore restores the PostgreSQL server of the multipooler with pgctld,
+// from the backups of its shard at the backup location of its database.
+// req is completed with the location and the directory. The multipooler
+// is in the RESTORE serving status while the restore runs.
+func Restore(ctx context.Context, r *Registration, client pgctldservicepb.PgCtldClient, req *pgctldatapb.RestoreRequest) (*pgctldatapb.RestoreResponse, error) {
+	location, dir, err := r.backupTarget(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var resp *pgctldatapb.RestoreResponse
+	err = r.withServingStatus(ctx, clustermetadatapb.PoolerServingStatus_RESTORE, func() error {
+		req.Location = location
+		req.Directory = dir
+		resp, err = client.Restore(ctx, req)
+		if err != nil {
+			return mterrors.Wrap(err, "restore failed")
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// HandleBackup returns the HTTP handler taking a backup with Backup, on
+// POST. It responds with the manifest of the backup, in JSON. The backup
+// completes even if the client disconnects. The handler is not
+// authenticated: it must only be served to trusted clients.
+func HandleBackup(r *Registration, client pgctldservicepb.PgCtldClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		manifest, err := Backup(context.WithoutCancel(req.Context()), r, client)
+		writeResponse(w, manifest, err)
+	}
+}
+
+// HandleRestore returns the HTTP handler restoring a backup with Restore,
+// on POST, with the parameters backup_name, and target_lsn or target_time
+// in RFC 3339. It responds with the manifest of the backup, in JSON. The
+// restore completes even if the client disconnects, as cancelling it
+// would roll it back. The handler is not authenticated: it must only be
+// served to trusted clients.
+func HandleRestore(r *Registration, client pgctldservicepb.PgCtldClient) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		restoreReq := &pgctldatapb.RestoreRequest{BackupName: req.FormValue("backup_name")}
+		if lsn := req.FormValue("target_lsn"); lsn != "" {
+			restoreReq.Target = &pgctldatapb.RestoreRequest_TargetLsn{TargetLsn: lsn}
+		}
+		if value := req.FormValue("target_time"); value != "" {
+			targetTime, err := time.Parse(time.RFC3339Nano, value)
+			if err != nil || restoreReq.Target != nil {
+				http.Error(w, "target_time must be an RFC 3339 time, without target_lsn", http.StatusBadRequest)
+				return
+			}
+			restoreReq.Target = &pgctldatapb.RestoreRequest_TargetTime{TargetTime: timestamppb.New(targetTime)}
+		}
+		resp, err := Restore(context.WithoutCancel(req.Context()), r, client, restoreReq)
+		writeResponse(w, resp.GetManifest(), err)
+	}
+}
+
+// httpStatuses are the HTTP statuses of the error codes, 500 by default.
+var httpStatuses = map[mtrpcpb.Code]int{
+	mtrpcpb.Code_INVALID_ARGUMENT:    http.StatusBadRequest,
+	mtrpcpb.Code_NOT_FOUND:           http.StatusNotFound,
+	mtrpcpb.Code_ALREADY_EXISTS:      http.StatusConflict,
+	mtrpcpb.Code_FAILED_PRECONDITION: http.StatusPreconditionFailed,
+	mtrpcpb.Code_UNAVAILABLE:         http.StatusServiceUnavailable,
+	mtrpcpb.Code_DEADLINE_EXCEEDED:   http.StatusGatewayTimeout,
+}
+
+// writeResponse writes message in JSON, or err, redacted.
+func writeResponse(w http.ResponseWriter, message proto.Message, err error) {
+	if err != nil {
+		slog.Error("Request failed", "error", err)
+		status, ok := httpStatuses[mterrors.Code(err)]
+		if !ok {
+			status = http.StatusInternalServerError
+		}
+		http.Error(w, mterrors.Redacted(err).Error(), status)
+		return
+	}
+	data, err := protojson.Marshal(message)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
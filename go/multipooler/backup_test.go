@@ -0,0 +1,194 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipooler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+	"github.com/multigres/multigres/go/mterrors"
+	"github.com/multigres/multigres/go/multipooler"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	pgctldservicepb "github.com/multigres/multigres/go/pb/pgctldservice"
+)
+
+// fakePgCtld is a pgctld client recording the serving status of the
+// multipooler during the backups and the restores.
+type fakePgCtld struct {
+	pgctldservicepb.PgCtldClient
+
+	ts       topo.Store
+	record   *clustermetadatapb.MultiPooler
+	statuses []clustermetadatapb.PoolerServingStatus
+	requests []any
+	err      error
+
+	// during is called during the backups and the restores.
+	during func()
+}
+
+func (c *fakePgCtld) recordStatus(ctx context.Context, req any) error {
+	mpi, err := c.ts.GetMultiPooler(ctx, c.record.Id)
+	if err == nil {
+		c.statuses = append(c.statuses, mpi.ServingStatus)
+	}
+	c.requests = append(c.requests, req)
+	if c.during != nil {
+		c.during()
+	}
+	if c.err != nil {
+		return c.err
+	}
+	return ctx.Err()
+}
+
+func (c *fakePgCtld) Backup(ctx context.Context, req *pgctldatapb.BackupRequest, _ ...grpc.CallOption) (*pgctldatapb.BackupResponse, error) {
+	if err := c.recordStatus(ctx, req); err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.BackupResponse{Manifest: &pgctldatapb.BackupManifest{Name: "backup1"}}, nil
+}
+
+func (c *fakePgCtld) Restore(ctx context.Context, req *pgctldatapb.RestoreRequest, _ ...grpc.CallOption) (*pgctldatapb.RestoreResponse, error) {
+	if err := c.recordStatus(ctx, req); err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.RestoreResponse{Manifest: &pgctldatapb.BackupManifest{Name: "backup1"}}, nil
+}
+
+func TestBackupRestore(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1"}))
+	record := newRecord("0")
+	registration, err := multipooler.Register(ctx, ts, record)
+	require.NoError(t, err)
+	require.NoError(t, registration.SetServingStatus(ctx, clustermetadatapb.PoolerServingStatus_SERVING))
+	client := &fakePgCtld{ts: ts, record: record}
+
+	// The database must have a backup location.
+	_, err = multipooler.Backup(ctx, registration, client)
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+	require.Empty(t, client.requests)
+
+	err = ts.UpdateDatabaseFields(ctx, "db1", func(db *clustermetadatapb.Database) error {
+		db.BackupLocation = "file:///backups"
+		return nil
+	})
+	require.NoError(t, err)
+
+	// The multipooler is in BACKUP during the backup, of the directory of
+	// its shard.
+	manifest, err := multipooler.Backup(ctx, registration, client)
+	require.NoError(t, err)
+	require.Equal(t, "backup1", manifest.Name)
+	require.Equal(t, []clustermetadatapb.PoolerServingStatus{clustermetadatapb.PoolerServingStatus_BACKUP}, client.statuses)
+	backupReq := client.requests[0].(*pgctldatapb.BackupRequest)
+	require.Equal(t, "file:///backups", backupReq.Location)
+	require.Equal(t, "db1/default/0", backupReq.Directory)
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_SERVING, registration.Record().ServingStatus)
+
+	// It is in RESTORE during the restore, and gets its previous status
+	// back, even on error.
+	require.NoError(t, registration.SetServingStatus(ctx, clustermetadatapb.PoolerServingStatus_NOT_SERVING))
+	client.err = mterrors.New(mtrpcpb.Code_NOT_FOUND, "no backup")
+	_, err = multipooler.Restore(ctx, registration, client, &pgctldatapb.RestoreRequest{
+		Target: &pgctldatapb.RestoreRequest_TargetLsn{TargetLsn: "0/3000000"},
+	})
+	require.Equal(t, mtrpcpb.Code_NOT_FOUND, mterrors.Code(err))
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_RESTORE, client.statuses[1])
+	restoreReq := client.requests[1].(*pgctldatapb.RestoreRequest)
+	require.Equal(t, "db1/default/0", restoreReq.Directory)
+	require.Equal(t, "0/3000000", restoreReq.GetTargetLsn())
+	mpi, err := ts.GetMultiPooler(ctx, record.Id)
+	require.NoError(t, err)
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_NOT_SERVING, mpi.ServingStatus)
+
+	// It keeps the status it transitioned to during the backup, like on
+	// shutdown.
+	require.NoError(t, registration.SetServingStatus(ctx, clustermetadatapb.PoolerServingStatus_SERVING))
+	client.err = nil
+	client.during = func() {
+		require.NoError(t, registration.SetServingStatus(ctx, clustermetadatapb.PoolerServingStatus_NOT_SERVING))
+	}
+	_, err = multipooler.Backup(ctx, registration, client)
+	require.NoError(t, err)
+	mpi, err = ts.GetMultiPooler(ctx, record.Id)
+	require.NoError(t, err)
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_NOT_SERVING, mpi.ServingStatus)
+}
+
+func TestBackupHandlers(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+	require.NoError(t, ts.CreateDatabase(ctx, "db1", &clustermetadatapb.Database{Name: "db1", BackupLocation: "file:///backups"}))
+	record := newRecord("0")
+	registration, err := multipooler.Register(ctx, ts, record)
+	require.NoError(t, err)
+	client := &fakePgCtld{ts: ts, record: record}
+	backup := multipooler.HandleBackup(registration, client)
+	restore := multipooler.HandleRestore(registration, client)
+	// The requests are cancelled, as if the clients disconnected: the
+	// backups and the restores complete anyway.
+	serve := func(handler http.HandlerFunc, method string, form url.Values) *httptest.ResponseRecorder {
+		reqCtx, cancel := context.WithCancel(ctx)
+		cancel()
+		req := httptest.NewRequestWithContext(reqCtx, method, "/?"+form.Encode(), nil)
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	w := serve(backup, http.MethodGet, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	w = serve(backup, http.MethodPost, nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.JSONEq(t, `{"name": "backup1"}`, w.Body.String())
+	require.Equal(t, []clustermetadatapb.PoolerServingStatus{clustermetadatapb.PoolerServingStatus_BACKUP}, client.statuses)
+
+	w = serve(restore, http.MethodPost, url.Values{"backup_name": {"backup1"}, "target_time": {"2025-01-02T03:04:05Z"}})
+	require.Equal(t, http.StatusOK, w.Code)
+	restoreReq := client.requests[1].(*pgctldatapb.RestoreRequest)
+	require.Equal(t, "backup1", restoreReq.BackupName)
+	require.Equal(t, int64(1735787045), restoreReq.GetTargetTime().Seconds)
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_RESTORE, client.statuses[1])
+
+	for _, form := range []url.Values{
+		{"target_time": {"yesterday"}},
+		{"target_time": {"2025-01-02T03:04:05Z"}, "target_lsn": {"0/3000000"}},
+	} {
+		w = serve(restore, http.MethodPost, form)
+		require.Equal(t, http.StatusBadRequest, w.Code, "%v", form)
+	}
+	require.Len(t, client.requests, 2)
+
+	// The errors are redacted.
+	client.err = mterrors.Errorf(mtrpcpb.Code_NOT_FOUND, "no backup in %v", mterrors.Sensitive("s3://secret"))
+	w = serve(restore, http.MethodPost, url.Values{"target_lsn": {"0/3000000"}})
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Equal(t, "no backup in <redacted>\n", w.Body.String())
+}
@@ -0,0 +1,110 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package multipooler contains the components of the multipooler binary.
+package multipooler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+)
+
+// Registration is the record of a multipooler in the topology of its
+// cell. Register creates it NOT_SERVING, and the multipooler keeps its
+// serving status up to date with SetServingStatus as it transitions. On
+// graceful shutdown, the multipooler marks itself NOT_SERVING: the record
+// is kept, so that its identity survives restarts.
+type Registration struct {
+	ts topo.Store
+
+	// mu protects record, and serializes the updates.
+	mu     sync.Mutex
+	record *clustermetadatapb.MultiPooler
+}
+
+// Register creates or updates the record of the multipooler in the
+// topology of its cell, NOT_SERVING. A multipooler restarted with the same
+// name updates its record, unless its database or shard changed.
+func Register(ctx context.Context, ts topo.Store, record *clustermetadatapb.MultiPooler) (*Registration, error) {
+	record = proto.Clone(record).(*clustermetadatapb.MultiPooler)
+	record.ServingStatus = clustermetadatapb.PoolerServingStatus_NOT_SERVING
+	err := mterrors.Retry(ctx, mterrors.DefaultRetryPolicy, func(ctx context.Context) error {
+		return ts.InitMultiPooler(ctx, record, false /* allowPrimaryOverride */, true /* allowUpdate */)
+	})
+	if err != nil {
+		return nil, mterrors.Wrapf(err, "failed to register multipooler %v", topo.MultiPoolerIDString(record.Id))
+	}
+	slog.Info("Registered multipooler in the topology", "id", topo.MultiPoolerIDString(record.Id), "database", record.Database, "shard", record.Shard)
+	return &Registration{ts: ts, record: record}, nil
+}
+
+// Record returns a copy of the record of the multipooler.
+func (r *Registration) Record() *clustermetadatapb.MultiPooler {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return proto.Clone(r.record).(*clustermetadatapb.MultiPooler)
+}
+
+// SetServingStatus updates the serving status of the multipooler in the
+// topology. It doesn't write the record if the status is unchanged.
+func (r *Registration) SetServingStatus(ctx context.Context, status clustermetadatapb.PoolerServingStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.setServingStatusLocked(ctx, status)
+}
+
+// swapServingStatus sets the serving status of the multipooler to status,
+// only if it is still old: the multipooler may have transitioned
+// meanwhile, like on shutdown.
+func (r *Registration) swapServingStatus(ctx context.Context, old, status clustermetadatapb.PoolerServingStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.record.ServingStatus != old {
+		return nil
+	}
+	return r.setServingStatusLocked(ctx, status)
+}
+
+// setServingStatusLocked sets the serving status of the multipooler, with
+// mu held.
+func (r *Registration) setServingStatusLocked(ctx context.Context, status clustermetadatapb.PoolerServingStatus) error {
+	var updated *clustermetadatapb.MultiPooler
+	err := mterrors.Retry(ctx, mterrors.DefaultRetryPolicy, func(ctx context.Context) error {
+		var err error
+		updated, err = r.ts.UpdateMultiPoolerFields(ctx, r.record.Id, func(mp *clustermetadatapb.MultiPooler) error {
+			if mp.ServingStatus == status {
+				return &topo.TopoError{Code: topo.NoUpdateNeeded}
+			}
+			mp.ServingStatus = status
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		return mterrors.Wrapf(err, "failed to set the serving status of multipooler %v to %v", topo.MultiPoolerIDString(r.record.Id), status)
+	}
+	if updated != nil {
+		r.record = updated
+	}
+	r.record.ServingStatus = status
+	slog.Info("Updated multipooler serving status", "id", topo.MultiPoolerIDString(r.record.Id), "serving_status", status.String())
+	return nil
+}
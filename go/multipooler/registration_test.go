@@ -0,0 +1,103 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package multipooler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+	"github.com/multigres/multigres/go/multipooler"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+)
+
+func newRecord(shard string) *clustermetadatapb.MultiPooler {
+	record := topo.NewMultiPooler("pooler1", "zone1", "host1")
+	record.Database = "db1"
+	record.TableGroup = "default"
+	record.Shard = shard
+	record.PortMap["grpc"] = 15100
+	return record
+}
+
+func TestRegistration(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	// The multipooler is registered NOT_SERVING.
+	record := newRecord("0")
+	record.ServingStatus = clustermetadatapb.PoolerServingStatus_SERVING
+	registration, err := multipooler.Register(ctx, ts, record)
+	require.NoError(t, err)
+	mpi, err := ts.GetMultiPooler(ctx, record.Id)
+	require.NoError(t, err)
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_NOT_SERVING, mpi.ServingStatus)
+	require.Equal(t, "host1", mpi.Hostname)
+	require.Equal(t, map[string]int32{"grpc": 15100}, mpi.PortMap)
+	require.Equal(t, "default", mpi.TableGroup)
+
+	// Its serving status follows its transitions.
+	require.NoError(t, registration.SetServingStatus(ctx, clustermetadatapb.PoolerServingStatus_SERVING))
+	mpi, err = ts.GetMultiPooler(ctx, record.Id)
+	require.NoError(t, err)
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_SERVING, mpi.ServingStatus)
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_SERVING, registration.Record().ServingStatus)
+
+	// An unchanged status is not written.
+	version := mpi.Version()
+	require.NoError(t, registration.SetServingStatus(ctx, clustermetadatapb.PoolerServingStatus_SERVING))
+	mpi, err = ts.GetMultiPooler(ctx, record.Id)
+	require.NoError(t, err)
+	require.Equal(t, version, mpi.Version())
+
+	// On shutdown, the record is kept NOT_SERVING.
+	require.NoError(t, registration.SetServingStatus(ctx, clustermetadatapb.PoolerServingStatus_NOT_SERVING))
+	mpi, err = ts.GetMultiPooler(ctx, record.Id)
+	require.NoError(t, err)
+	require.Equal(t, clustermetadatapb.PoolerServingStatus_NOT_SERVING, mpi.ServingStatus)
+
+	// A restarted multipooler updates its record.
+	record.Hostname = "host2"
+	_, err = multipooler.Register(ctx, ts, record)
+	require.NoError(t, err)
+	mpi, err = ts.GetMultiPooler(ctx, record.Id)
+	require.NoError(t, err)
+	require.Equal(t, "host2", mpi.Hostname)
+
+	// But can't change its shard.
+	_, err = multipooler.Register(ctx, ts, newRecord("1"))
+	require.ErrorContains(t, err, "failed to register multipooler multipooler-zone1-pooler1")
+}
+
+func TestRegistrationErrors(t *testing.T) {
+	ctx := context.Background()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	defer ts.Close()
+
+	record := newRecord("0")
+	record.Id.Cell = "zone2"
+	_, err := multipooler.Register(ctx, ts, record)
+	require.Error(t, err)
+
+	registration, err := multipooler.Register(ctx, ts, newRecord("0"))
+	require.NoError(t, err)
+	require.NoError(t, ts.DeleteMultiPooler(ctx, registration.Record().Id))
+	err = registration.SetServingStatus(ctx, clustermetadatapb.PoolerServingStatus_SERVING)
+	require.ErrorIs(t, err, &topo.TopoError{Code: topo.NoNode})
+}
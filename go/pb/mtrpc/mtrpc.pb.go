@@ -319,11 +319,17 @@ func (x *CallerID) GetGroups() []string {
 // We use this so the clients don't have to parse the error messages,
 // but instead can depend on the value of the code.
 type RPCError struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
-	Code          Code                   `protobuf:"varint,2,opt,name=code,proto3,enum=mtrpc.Code" json:"code,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Code    Code                   `protobuf:"varint,2,opt,name=code,proto3,enum=mtrpc.Code" json:"code,omitempty"`
+	// state is the mterrors.State of the error, so that errors meant to
+	// be returned to PostgreSQL clients keep their state across RPCs.
+	State int32 `protobuf:"varint,3,opt,name=state,proto3" json:"state,omitempty"`
+	// redacted_message is the message without sensitive values nor
+	// internal context, which can be returned to external clients.
+	RedactedMessage string `protobuf:"bytes,4,opt,name=redacted_message,json=redactedMessage,proto3" json:"redacted_message,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *RPCError) Reset() {
@@ -370,6 +376,20 @@ func (x *RPCError) GetCode() Code {
 	return Code_OK
 }
 
+func (x *RPCError) GetState() int32 {
+	if x != nil {
+		return x.State
+	}
+	return 0
+}
+
+func (x *RPCError) GetRedactedMessage() string {
+	if x != nil {
+		return x.RedactedMessage
+	}
+	return ""
+}
+
 var File_mtrpc_proto protoreflect.FileDescriptor
 
 const file_mtrpc_proto_rawDesc = "" +
@@ -379,10 +399,12 @@ const file_mtrpc_proto_rawDesc = "" +
 	"\tprincipal\x18\x01 \x01(\tR\tprincipal\x12\x1c\n" +
 	"\tcomponent\x18\x02 \x01(\tR\tcomponent\x12\"\n" +
 	"\fsubcomponent\x18\x03 \x01(\tR\fsubcomponent\x12\x16\n" +
-	"\x06groups\x18\x04 \x03(\tR\x06groups\"E\n" +
+	"\x06groups\x18\x04 \x03(\tR\x06groups\"\x86\x01\n" +
 	"\bRPCError\x12\x18\n" +
 	"\amessage\x18\x01 \x01(\tR\amessage\x12\x1f\n" +
-	"\x04code\x18\x02 \x01(\x0e2\v.mtrpc.CodeR\x04code*\xd8\x02\n" +
+	"\x04code\x18\x02 \x01(\x0e2\v.mtrpc.CodeR\x04code\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\x05R\x05state\x12)\n" +
+	"\x10redacted_message\x18\x04 \x01(\tR\x0fredactedMessage*\xd8\x02\n" +
 	"\x04Code\x12\x06\n" +
 	"\x02OK\x10\x00\x12\f\n" +
 	"\bCANCELED\x10\x01\x12\v\n" +
@@ -0,0 +1,2779 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the messages of the pgctld service, which manages the
+// local PostgreSQL server of a multipooler.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v4.25.1
+// source: pgctldata.proto
+
+package pgctldata
+
+import (
+	query "github.com/multigres/multigres/go/pb/query"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ServerState is the state of the PostgreSQL server process.
+type ServerState int32
+
+const (
+	// STOPPED is the state of a server that is not running.
+	ServerState_STOPPED ServerState = 0
+	// RUNNING is the state of a server process that is running. The server
+	// may not accept connections yet, see PostgresStatus.ready.
+	ServerState_RUNNING ServerState = 1
+)
+
+// Enum value maps for ServerState.
+var (
+	ServerState_name = map[int32]string{
+		0: "STOPPED",
+		1: "RUNNING",
+	}
+	ServerState_value = map[string]int32{
+		"STOPPED": 0,
+		"RUNNING": 1,
+	}
+)
+
+func (x ServerState) Enum() *ServerState {
+	p := new(ServerState)
+	*p = x
+	return p
+}
+
+func (x ServerState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ServerState) Descriptor() protoreflect.EnumDescriptor {
+	return file_pgctldata_proto_enumTypes[0].Descriptor()
+}
+
+func (ServerState) Type() protoreflect.EnumType {
+	return &file_pgctldata_proto_enumTypes[0]
+}
+
+func (x ServerState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ServerState.Descriptor instead.
+func (ServerState) EnumDescriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{0}
+}
+
+// StopMode is the PostgreSQL shutdown mode, as in pg_ctl stop --mode.
+type StopMode int32
+
+const (
+	// FAST rolls back the open transactions and disconnects the clients,
+	// then shuts down cleanly.
+	StopMode_FAST StopMode = 0
+	// SMART waits for all the clients to disconnect.
+	StopMode_SMART StopMode = 1
+	// IMMEDIATE aborts all the server processes without a clean shutdown.
+	// The server goes through crash recovery at the next start.
+	StopMode_IMMEDIATE StopMode = 2
+)
+
+// Enum value maps for StopMode.
+var (
+	StopMode_name = map[int32]string{
+		0: "FAST",
+		1: "SMART",
+		2: "IMMEDIATE",
+	}
+	StopMode_value = map[string]int32{
+		"FAST":      0,
+		"SMART":     1,
+		"IMMEDIATE": 2,
+	}
+)
+
+func (x StopMode) Enum() *StopMode {
+	p := new(StopMode)
+	*p = x
+	return p
+}
+
+func (x StopMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StopMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_pgctldata_proto_enumTypes[1].Descriptor()
+}
+
+func (StopMode) Type() protoreflect.EnumType {
+	return &file_pgctldata_proto_enumTypes[1]
+}
+
+func (x StopMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StopMode.Descriptor instead.
+func (StopMode) EnumDescriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{1}
+}
+
+// PostgresStatus describes the local PostgreSQL server.
+type PostgresStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// state is the state of the server process.
+	State ServerState `protobuf:"varint,1,opt,name=state,proto3,enum=pgctldata.ServerState" json:"state,omitempty"`
+	// initialized is true if the data directory was initialized.
+	Initialized bool `protobuf:"varint,2,opt,name=initialized,proto3" json:"initialized,omitempty"`
+	// data_dir is the path of the data directory.
+	DataDir string `protobuf:"bytes,3,opt,name=data_dir,json=dataDir,proto3" json:"data_dir,omitempty"`
+	// version is the major version of the data directory, from PG_VERSION.
+	Version string `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	// pid is the pid of the postmaster, if running.
+	Pid int32 `protobuf:"varint,5,opt,name=pid,proto3" json:"pid,omitempty"`
+	// port is the port the server listens on, if running.
+	Port int32 `protobuf:"varint,6,opt,name=port,proto3" json:"port,omitempty"`
+	// start_time is when the server started, if running.
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	// uptime is the time since the server started, if running.
+	Uptime *durationpb.Duration `protobuf:"bytes,8,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	// ready is true if the server accepts connections.
+	Ready         bool `protobuf:"varint,9,opt,name=ready,proto3" json:"ready,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PostgresStatus) Reset() {
+	*x = PostgresStatus{}
+	mi := &file_pgctldata_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PostgresStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PostgresStatus) ProtoMessage() {}
+
+func (x *PostgresStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PostgresStatus.ProtoReflect.Descriptor instead.
+func (*PostgresStatus) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *PostgresStatus) GetState() ServerState {
+	if x != nil {
+		return x.State
+	}
+	return ServerState_STOPPED
+}
+
+func (x *PostgresStatus) GetInitialized() bool {
+	if x != nil {
+		return x.Initialized
+	}
+	return false
+}
+
+func (x *PostgresStatus) GetDataDir() string {
+	if x != nil {
+		return x.DataDir
+	}
+	return ""
+}
+
+func (x *PostgresStatus) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *PostgresStatus) GetPid() int32 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *PostgresStatus) GetPort() int32 {
+	if x != nil {
+		return x.Port
+	}
+	return 0
+}
+
+func (x *PostgresStatus) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *PostgresStatus) GetUptime() *durationpb.Duration {
+	if x != nil {
+		return x.Uptime
+	}
+	return nil
+}
+
+func (x *PostgresStatus) GetReady() bool {
+	if x != nil {
+		return x.Ready
+	}
+	return false
+}
+
+type InitDataDirRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitDataDirRequest) Reset() {
+	*x = InitDataDirRequest{}
+	mi := &file_pgctldata_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitDataDirRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitDataDirRequest) ProtoMessage() {}
+
+func (x *InitDataDirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitDataDirRequest.ProtoReflect.Descriptor instead.
+func (*InitDataDirRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{1}
+}
+
+type InitDataDirResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *PostgresStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InitDataDirResponse) Reset() {
+	*x = InitDataDirResponse{}
+	mi := &file_pgctldata_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InitDataDirResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InitDataDirResponse) ProtoMessage() {}
+
+func (x *InitDataDirResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InitDataDirResponse.ProtoReflect.Descriptor instead.
+func (*InitDataDirResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *InitDataDirResponse) GetStatus() *PostgresStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type StartRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartRequest) Reset() {
+	*x = StartRequest{}
+	mi := &file_pgctldata_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRequest) ProtoMessage() {}
+
+func (x *StartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRequest.ProtoReflect.Descriptor instead.
+func (*StartRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{3}
+}
+
+type StartResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *PostgresStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartResponse) Reset() {
+	*x = StartResponse{}
+	mi := &file_pgctldata_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartResponse) ProtoMessage() {}
+
+func (x *StartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartResponse.ProtoReflect.Descriptor instead.
+func (*StartResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StartResponse) GetStatus() *PostgresStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type StopRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          StopMode               `protobuf:"varint,1,opt,name=mode,proto3,enum=pgctldata.StopMode" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopRequest) Reset() {
+	*x = StopRequest{}
+	mi := &file_pgctldata_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRequest) ProtoMessage() {}
+
+func (x *StopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRequest.ProtoReflect.Descriptor instead.
+func (*StopRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *StopRequest) GetMode() StopMode {
+	if x != nil {
+		return x.Mode
+	}
+	return StopMode_FAST
+}
+
+type StopResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *PostgresStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopResponse) Reset() {
+	*x = StopResponse{}
+	mi := &file_pgctldata_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopResponse) ProtoMessage() {}
+
+func (x *StopResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopResponse.ProtoReflect.Descriptor instead.
+func (*StopResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *StopResponse) GetStatus() *PostgresStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type RestartRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// mode is the shutdown mode of the server before it starts again.
+	Mode          StopMode `protobuf:"varint,1,opt,name=mode,proto3,enum=pgctldata.StopMode" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartRequest) Reset() {
+	*x = RestartRequest{}
+	mi := &file_pgctldata_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartRequest) ProtoMessage() {}
+
+func (x *RestartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartRequest.ProtoReflect.Descriptor instead.
+func (*RestartRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RestartRequest) GetMode() StopMode {
+	if x != nil {
+		return x.Mode
+	}
+	return StopMode_FAST
+}
+
+type RestartResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *PostgresStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestartResponse) Reset() {
+	*x = RestartResponse{}
+	mi := &file_pgctldata_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestartResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestartResponse) ProtoMessage() {}
+
+func (x *RestartResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestartResponse.ProtoReflect.Descriptor instead.
+func (*RestartResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RestartResponse) GetStatus() *PostgresStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type ReloadConfigRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadConfigRequest) Reset() {
+	*x = ReloadConfigRequest{}
+	mi := &file_pgctldata_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadConfigRequest) ProtoMessage() {}
+
+func (x *ReloadConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadConfigRequest.ProtoReflect.Descriptor instead.
+func (*ReloadConfigRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{9}
+}
+
+type ReloadConfigResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadConfigResponse) Reset() {
+	*x = ReloadConfigResponse{}
+	mi := &file_pgctldata_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadConfigResponse) ProtoMessage() {}
+
+func (x *ReloadConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadConfigResponse.ProtoReflect.Descriptor instead.
+func (*ReloadConfigResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{10}
+}
+
+type StatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusRequest) Reset() {
+	*x = StatusRequest{}
+	mi := &file_pgctldata_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusRequest) ProtoMessage() {}
+
+func (x *StatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusRequest.ProtoReflect.Descriptor instead.
+func (*StatusRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{11}
+}
+
+type StatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *PostgresStatus        `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatusResponse) Reset() {
+	*x = StatusResponse{}
+	mi := &file_pgctldata_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatusResponse) ProtoMessage() {}
+
+func (x *StatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatusResponse.ProtoReflect.Descriptor instead.
+func (*StatusResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *StatusResponse) GetStatus() *PostgresStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type SetConfigRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// settings are the postgresql.conf settings to set, by name. They are
+	// kept across restarts, and take precedence over the overrides file.
+	Settings map[string]string `protobuf:"bytes,1,rep,name=settings,proto3" json:"settings,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// reset_settings are the names of the settings set by previous calls
+	// that go back to their default or overridden value.
+	ResetSettings []string `protobuf:"bytes,2,rep,name=reset_settings,json=resetSettings,proto3" json:"reset_settings,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetConfigRequest) Reset() {
+	*x = SetConfigRequest{}
+	mi := &file_pgctldata_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetConfigRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetConfigRequest) ProtoMessage() {}
+
+func (x *SetConfigRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetConfigRequest.ProtoReflect.Descriptor instead.
+func (*SetConfigRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SetConfigRequest) GetSettings() map[string]string {
+	if x != nil {
+		return x.Settings
+	}
+	return nil
+}
+
+func (x *SetConfigRequest) GetResetSettings() []string {
+	if x != nil {
+		return x.ResetSettings
+	}
+	return nil
+}
+
+type SetConfigResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// changed_settings are the names of the settings whose value changed.
+	ChangedSettings []string `protobuf:"bytes,1,rep,name=changed_settings,json=changedSettings,proto3" json:"changed_settings,omitempty"`
+	// reload_required is true if the running server must reload its
+	// configuration to apply the changes, with ReloadConfig.
+	ReloadRequired bool `protobuf:"varint,2,opt,name=reload_required,json=reloadRequired,proto3" json:"reload_required,omitempty"`
+	// restart_required is true if the running server must restart to
+	// apply the changes, with Restart.
+	RestartRequired bool `protobuf:"varint,3,opt,name=restart_required,json=restartRequired,proto3" json:"restart_required,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SetConfigResponse) Reset() {
+	*x = SetConfigResponse{}
+	mi := &file_pgctldata_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetConfigResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetConfigResponse) ProtoMessage() {}
+
+func (x *SetConfigResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetConfigResponse.ProtoReflect.Descriptor instead.
+func (*SetConfigResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SetConfigResponse) GetChangedSettings() []string {
+	if x != nil {
+		return x.ChangedSettings
+	}
+	return nil
+}
+
+func (x *SetConfigResponse) GetReloadRequired() bool {
+	if x != nil {
+		return x.ReloadRequired
+	}
+	return false
+}
+
+func (x *SetConfigResponse) GetRestartRequired() bool {
+	if x != nil {
+		return x.RestartRequired
+	}
+	return false
+}
+
+// ReplicationStatus describes the replication of the local server, as a
+// primary or as a standby.
+type ReplicationStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// in_recovery is true if the server is a standby, replaying the WAL of
+	// its primary or of the archive.
+	InRecovery bool `protobuf:"varint,1,opt,name=in_recovery,json=inRecovery,proto3" json:"in_recovery,omitempty"`
+	// current_lsn is the current WAL write location of a primary.
+	CurrentLsn string `protobuf:"bytes,2,opt,name=current_lsn,json=currentLsn,proto3" json:"current_lsn,omitempty"`
+	// receive_lsn is the last WAL location received and flushed by a
+	// standby.
+	ReceiveLsn string `protobuf:"bytes,3,opt,name=receive_lsn,json=receiveLsn,proto3" json:"receive_lsn,omitempty"`
+	// replay_lsn is the last WAL location replayed by a standby.
+	ReplayLsn string `protobuf:"bytes,4,opt,name=replay_lsn,json=replayLsn,proto3" json:"replay_lsn,omitempty"`
+	// last_replay_time is the commit time of the last transaction replayed
+	// by a standby, and replay_lag the time since then, or 0 if the standby
+	// replayed all the WAL it received. They are not set before the first
+	// replayed transaction.
+	LastReplayTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_replay_time,json=lastReplayTime,proto3" json:"last_replay_time,omitempty"`
+	ReplayLag      *durationpb.Duration   `protobuf:"bytes,6,opt,name=replay_lag,json=replayLag,proto3" json:"replay_lag,omitempty"`
+	// replay_paused is true if the replay of a standby is paused.
+	ReplayPaused bool `protobuf:"varint,7,opt,name=replay_paused,json=replayPaused,proto3" json:"replay_paused,omitempty"`
+	// wal_receiver is the connection of a standby to its primary, if any.
+	WalReceiver *WalReceiver `protobuf:"bytes,8,opt,name=wal_receiver,json=walReceiver,proto3" json:"wal_receiver,omitempty"`
+	// standbys are the standbys streaming from the server.
+	Standbys []*StandbyStatus `protobuf:"bytes,9,rep,name=standbys,proto3" json:"standbys,omitempty"`
+	// slots are the replication slots of the server.
+	Slots         []*ReplicationSlot `protobuf:"bytes,10,rep,name=slots,proto3" json:"slots,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationStatus) Reset() {
+	*x = ReplicationStatus{}
+	mi := &file_pgctldata_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatus) ProtoMessage() {}
+
+func (x *ReplicationStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatus.ProtoReflect.Descriptor instead.
+func (*ReplicationStatus) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReplicationStatus) GetInRecovery() bool {
+	if x != nil {
+		return x.InRecovery
+	}
+	return false
+}
+
+func (x *ReplicationStatus) GetCurrentLsn() string {
+	if x != nil {
+		return x.CurrentLsn
+	}
+	return ""
+}
+
+func (x *ReplicationStatus) GetReceiveLsn() string {
+	if x != nil {
+		return x.ReceiveLsn
+	}
+	return ""
+}
+
+func (x *ReplicationStatus) GetReplayLsn() string {
+	if x != nil {
+		return x.ReplayLsn
+	}
+	return ""
+}
+
+func (x *ReplicationStatus) GetLastReplayTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastReplayTime
+	}
+	return nil
+}
+
+func (x *ReplicationStatus) GetReplayLag() *durationpb.Duration {
+	if x != nil {
+		return x.ReplayLag
+	}
+	return nil
+}
+
+func (x *ReplicationStatus) GetReplayPaused() bool {
+	if x != nil {
+		return x.ReplayPaused
+	}
+	return false
+}
+
+func (x *ReplicationStatus) GetWalReceiver() *WalReceiver {
+	if x != nil {
+		return x.WalReceiver
+	}
+	return nil
+}
+
+func (x *ReplicationStatus) GetStandbys() []*StandbyStatus {
+	if x != nil {
+		return x.Standbys
+	}
+	return nil
+}
+
+func (x *ReplicationStatus) GetSlots() []*ReplicationSlot {
+	if x != nil {
+		return x.Slots
+	}
+	return nil
+}
+
+// WalReceiver describes the connection of a standby to its primary, from
+// pg_stat_wal_receiver.
+type WalReceiver struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// status is the status of the WAL receiver, like streaming.
+	Status        string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	PrimaryHost   string `protobuf:"bytes,2,opt,name=primary_host,json=primaryHost,proto3" json:"primary_host,omitempty"`
+	PrimaryPort   int32  `protobuf:"varint,3,opt,name=primary_port,json=primaryPort,proto3" json:"primary_port,omitempty"`
+	SlotName      string `protobuf:"bytes,4,opt,name=slot_name,json=slotName,proto3" json:"slot_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WalReceiver) Reset() {
+	*x = WalReceiver{}
+	mi := &file_pgctldata_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WalReceiver) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WalReceiver) ProtoMessage() {}
+
+func (x *WalReceiver) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WalReceiver.ProtoReflect.Descriptor instead.
+func (*WalReceiver) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *WalReceiver) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *WalReceiver) GetPrimaryHost() string {
+	if x != nil {
+		return x.PrimaryHost
+	}
+	return ""
+}
+
+func (x *WalReceiver) GetPrimaryPort() int32 {
+	if x != nil {
+		return x.PrimaryPort
+	}
+	return 0
+}
+
+func (x *WalReceiver) GetSlotName() string {
+	if x != nil {
+		return x.SlotName
+	}
+	return ""
+}
+
+// StandbyStatus describes a standby streaming from the server, from
+// pg_stat_replication.
+type StandbyStatus struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ApplicationName string                 `protobuf:"bytes,1,opt,name=application_name,json=applicationName,proto3" json:"application_name,omitempty"`
+	ClientAddr      string                 `protobuf:"bytes,2,opt,name=client_addr,json=clientAddr,proto3" json:"client_addr,omitempty"`
+	// state is the state of the WAL sender, like streaming.
+	State string `protobuf:"bytes,3,opt,name=state,proto3" json:"state,omitempty"`
+	// The last WAL locations sent to the standby, and written, flushed and
+	// replayed by it.
+	SentLsn   string `protobuf:"bytes,4,opt,name=sent_lsn,json=sentLsn,proto3" json:"sent_lsn,omitempty"`
+	WriteLsn  string `protobuf:"bytes,5,opt,name=write_lsn,json=writeLsn,proto3" json:"write_lsn,omitempty"`
+	FlushLsn  string `protobuf:"bytes,6,opt,name=flush_lsn,json=flushLsn,proto3" json:"flush_lsn,omitempty"`
+	ReplayLsn string `protobuf:"bytes,7,opt,name=replay_lsn,json=replayLsn,proto3" json:"replay_lsn,omitempty"`
+	// replay_lag is the time it took the standby to replay the last WAL
+	// sent to it.
+	ReplayLag *durationpb.Duration `protobuf:"bytes,8,opt,name=replay_lag,json=replayLag,proto3" json:"replay_lag,omitempty"`
+	// sync_state is async, potential, sync or quorum.
+	SyncState     string `protobuf:"bytes,9,opt,name=sync_state,json=syncState,proto3" json:"sync_state,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StandbyStatus) Reset() {
+	*x = StandbyStatus{}
+	mi := &file_pgctldata_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StandbyStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StandbyStatus) ProtoMessage() {}
+
+func (x *StandbyStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StandbyStatus.ProtoReflect.Descriptor instead.
+func (*StandbyStatus) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *StandbyStatus) GetApplicationName() string {
+	if x != nil {
+		return x.ApplicationName
+	}
+	return ""
+}
+
+func (x *StandbyStatus) GetClientAddr() string {
+	if x != nil {
+		return x.ClientAddr
+	}
+	return ""
+}
+
+func (x *StandbyStatus) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *StandbyStatus) GetSentLsn() string {
+	if x != nil {
+		return x.SentLsn
+	}
+	return ""
+}
+
+func (x *StandbyStatus) GetWriteLsn() string {
+	if x != nil {
+		return x.WriteLsn
+	}
+	return ""
+}
+
+func (x *StandbyStatus) GetFlushLsn() string {
+	if x != nil {
+		return x.FlushLsn
+	}
+	return ""
+}
+
+func (x *StandbyStatus) GetReplayLsn() string {
+	if x != nil {
+		return x.ReplayLsn
+	}
+	return ""
+}
+
+func (x *StandbyStatus) GetReplayLag() *durationpb.Duration {
+	if x != nil {
+		return x.ReplayLag
+	}
+	return nil
+}
+
+func (x *StandbyStatus) GetSyncState() string {
+	if x != nil {
+		return x.SyncState
+	}
+	return ""
+}
+
+// ReplicationSlot describes a replication slot of the server.
+type ReplicationSlot struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// type is physical or logical.
+	Type string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	// active is true if a standby is streaming from the slot.
+	Active bool `protobuf:"varint,3,opt,name=active,proto3" json:"active,omitempty"`
+	// restart_lsn is the oldest WAL location kept for the slot.
+	RestartLsn    string `protobuf:"bytes,4,opt,name=restart_lsn,json=restartLsn,proto3" json:"restart_lsn,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationSlot) Reset() {
+	*x = ReplicationSlot{}
+	mi := &file_pgctldata_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationSlot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationSlot) ProtoMessage() {}
+
+func (x *ReplicationSlot) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationSlot.ProtoReflect.Descriptor instead.
+func (*ReplicationSlot) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReplicationSlot) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ReplicationSlot) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ReplicationSlot) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *ReplicationSlot) GetRestartLsn() string {
+	if x != nil {
+		return x.RestartLsn
+	}
+	return ""
+}
+
+type SetPrimaryConnInfoRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// primary_conninfo is the connection string to the primary. It is
+	// required: a standby without a primary stops being one with Promote.
+	PrimaryConninfo string `protobuf:"bytes,1,opt,name=primary_conninfo,json=primaryConninfo,proto3" json:"primary_conninfo,omitempty"`
+	// primary_slot_name is the replication slot to stream from, on the
+	// primary. It may be empty.
+	PrimarySlotName string `protobuf:"bytes,2,opt,name=primary_slot_name,json=primarySlotName,proto3" json:"primary_slot_name,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SetPrimaryConnInfoRequest) Reset() {
+	*x = SetPrimaryConnInfoRequest{}
+	mi := &file_pgctldata_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPrimaryConnInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPrimaryConnInfoRequest) ProtoMessage() {}
+
+func (x *SetPrimaryConnInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPrimaryConnInfoRequest.ProtoReflect.Descriptor instead.
+func (*SetPrimaryConnInfoRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SetPrimaryConnInfoRequest) GetPrimaryConninfo() string {
+	if x != nil {
+		return x.PrimaryConninfo
+	}
+	return ""
+}
+
+func (x *SetPrimaryConnInfoRequest) GetPrimarySlotName() string {
+	if x != nil {
+		return x.PrimarySlotName
+	}
+	return ""
+}
+
+type SetPrimaryConnInfoResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// restart_required is true if the server runs as a primary, and must
+	// restart to become a standby.
+	RestartRequired bool `protobuf:"varint,1,opt,name=restart_required,json=restartRequired,proto3" json:"restart_required,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SetPrimaryConnInfoResponse) Reset() {
+	*x = SetPrimaryConnInfoResponse{}
+	mi := &file_pgctldata_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetPrimaryConnInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetPrimaryConnInfoResponse) ProtoMessage() {}
+
+func (x *SetPrimaryConnInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetPrimaryConnInfoResponse.ProtoReflect.Descriptor instead.
+func (*SetPrimaryConnInfoResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SetPrimaryConnInfoResponse) GetRestartRequired() bool {
+	if x != nil {
+		return x.RestartRequired
+	}
+	return false
+}
+
+type PromoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromoteRequest) Reset() {
+	*x = PromoteRequest{}
+	mi := &file_pgctldata_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromoteRequest) ProtoMessage() {}
+
+func (x *PromoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromoteRequest.ProtoReflect.Descriptor instead.
+func (*PromoteRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{21}
+}
+
+type PromoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *ReplicationStatus     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PromoteResponse) Reset() {
+	*x = PromoteResponse{}
+	mi := &file_pgctldata_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PromoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PromoteResponse) ProtoMessage() {}
+
+func (x *PromoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PromoteResponse.ProtoReflect.Descriptor instead.
+func (*PromoteResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *PromoteResponse) GetStatus() *ReplicationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type CreateReplicationSlotRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is the name of the physical slot to create.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateReplicationSlotRequest) Reset() {
+	*x = CreateReplicationSlotRequest{}
+	mi := &file_pgctldata_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateReplicationSlotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReplicationSlotRequest) ProtoMessage() {}
+
+func (x *CreateReplicationSlotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReplicationSlotRequest.ProtoReflect.Descriptor instead.
+func (*CreateReplicationSlotRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateReplicationSlotRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CreateReplicationSlotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateReplicationSlotResponse) Reset() {
+	*x = CreateReplicationSlotResponse{}
+	mi := &file_pgctldata_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateReplicationSlotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateReplicationSlotResponse) ProtoMessage() {}
+
+func (x *CreateReplicationSlotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateReplicationSlotResponse.ProtoReflect.Descriptor instead.
+func (*CreateReplicationSlotResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{24}
+}
+
+type DropReplicationSlotRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DropReplicationSlotRequest) Reset() {
+	*x = DropReplicationSlotRequest{}
+	mi := &file_pgctldata_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DropReplicationSlotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DropReplicationSlotRequest) ProtoMessage() {}
+
+func (x *DropReplicationSlotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DropReplicationSlotRequest.ProtoReflect.Descriptor instead.
+func (*DropReplicationSlotRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DropReplicationSlotRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DropReplicationSlotResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DropReplicationSlotResponse) Reset() {
+	*x = DropReplicationSlotResponse{}
+	mi := &file_pgctldata_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DropReplicationSlotResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DropReplicationSlotResponse) ProtoMessage() {}
+
+func (x *DropReplicationSlotResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DropReplicationSlotResponse.ProtoReflect.Descriptor instead.
+func (*DropReplicationSlotResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{26}
+}
+
+type PauseReplayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseReplayRequest) Reset() {
+	*x = PauseReplayRequest{}
+	mi := &file_pgctldata_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseReplayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseReplayRequest) ProtoMessage() {}
+
+func (x *PauseReplayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseReplayRequest.ProtoReflect.Descriptor instead.
+func (*PauseReplayRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{27}
+}
+
+type PauseReplayResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *ReplicationStatus     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseReplayResponse) Reset() {
+	*x = PauseReplayResponse{}
+	mi := &file_pgctldata_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseReplayResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseReplayResponse) ProtoMessage() {}
+
+func (x *PauseReplayResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseReplayResponse.ProtoReflect.Descriptor instead.
+func (*PauseReplayResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *PauseReplayResponse) GetStatus() *ReplicationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type ResumeReplayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeReplayRequest) Reset() {
+	*x = ResumeReplayRequest{}
+	mi := &file_pgctldata_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeReplayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeReplayRequest) ProtoMessage() {}
+
+func (x *ResumeReplayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeReplayRequest.ProtoReflect.Descriptor instead.
+func (*ResumeReplayRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{29}
+}
+
+type ResumeReplayResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *ReplicationStatus     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResumeReplayResponse) Reset() {
+	*x = ResumeReplayResponse{}
+	mi := &file_pgctldata_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResumeReplayResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResumeReplayResponse) ProtoMessage() {}
+
+func (x *ResumeReplayResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResumeReplayResponse.ProtoReflect.Descriptor instead.
+func (*ResumeReplayResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ResumeReplayResponse) GetStatus() *ReplicationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type ReplicationStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationStatusRequest) Reset() {
+	*x = ReplicationStatusRequest{}
+	mi := &file_pgctldata_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatusRequest) ProtoMessage() {}
+
+func (x *ReplicationStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatusRequest.ProtoReflect.Descriptor instead.
+func (*ReplicationStatusRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{31}
+}
+
+type ReplicationStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        *ReplicationStatus     `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReplicationStatusResponse) Reset() {
+	*x = ReplicationStatusResponse{}
+	mi := &file_pgctldata_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReplicationStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReplicationStatusResponse) ProtoMessage() {}
+
+func (x *ReplicationStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReplicationStatusResponse.ProtoReflect.Descriptor instead.
+func (*ReplicationStatusResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *ReplicationStatusResponse) GetStatus() *ReplicationStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type ExecuteFetchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// query is a single SQL statement.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// max_rows is the maximum number of rows the query may return. The
+	// query fails if it returns more, and its changes are rolled back. 0
+	// means the default, 10000.
+	MaxRows int64 `protobuf:"varint,2,opt,name=max_rows,json=maxRows,proto3" json:"max_rows,omitempty"`
+	// timeout bounds the execution of the query. It defaults to 30s.
+	Timeout       *durationpb.Duration `protobuf:"bytes,3,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteFetchRequest) Reset() {
+	*x = ExecuteFetchRequest{}
+	mi := &file_pgctldata_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteFetchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteFetchRequest) ProtoMessage() {}
+
+func (x *ExecuteFetchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteFetchRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteFetchRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ExecuteFetchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ExecuteFetchRequest) GetMaxRows() int64 {
+	if x != nil {
+		return x.MaxRows
+	}
+	return 0
+}
+
+func (x *ExecuteFetchRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type ExecuteFetchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *query.QueryResult     `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteFetchResponse) Reset() {
+	*x = ExecuteFetchResponse{}
+	mi := &file_pgctldata_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteFetchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteFetchResponse) ProtoMessage() {}
+
+func (x *ExecuteFetchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteFetchResponse.ProtoReflect.Descriptor instead.
+func (*ExecuteFetchResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ExecuteFetchResponse) GetResult() *query.QueryResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// BackupManifest describes a backup. It is stored with the files of the
+// backup, and written last: a backup without manifest is incomplete.
+type BackupManifest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// name is the name of the backup in its directory, from its start time.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// version is the major version of the server.
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// The backup copies the data directory between start_time and
+	// end_time, and restores it as of a WAL location between start_lsn and
+	// end_lsn.
+	StartTime *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	StartLsn  string                 `protobuf:"bytes,5,opt,name=start_lsn,json=startLsn,proto3" json:"start_lsn,omitempty"`
+	EndLsn    string                 `protobuf:"bytes,6,opt,name=end_lsn,json=endLsn,proto3" json:"end_lsn,omitempty"`
+	// files are the files of the backup: the base backup, and the WAL
+	// segments archived since the previous backup, in wal/.
+	Files         []*BackupFile `protobuf:"bytes,7,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupManifest) Reset() {
+	*x = BackupManifest{}
+	mi := &file_pgctldata_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupManifest) ProtoMessage() {}
+
+func (x *BackupManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupManifest.ProtoReflect.Descriptor instead.
+func (*BackupManifest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *BackupManifest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BackupManifest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *BackupManifest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *BackupManifest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *BackupManifest) GetStartLsn() string {
+	if x != nil {
+		return x.StartLsn
+	}
+	return ""
+}
+
+func (x *BackupManifest) GetEndLsn() string {
+	if x != nil {
+		return x.EndLsn
+	}
+	return ""
+}
+
+func (x *BackupManifest) GetFiles() []*BackupFile {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+// BackupFile describes a file of a backup.
+type BackupFile struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Size  int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// sha256 is the hex SHA-256 digest of the content of the file.
+	Sha256        string `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupFile) Reset() {
+	*x = BackupFile{}
+	mi := &file_pgctldata_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupFile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupFile) ProtoMessage() {}
+
+func (x *BackupFile) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupFile.ProtoReflect.Descriptor instead.
+func (*BackupFile) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *BackupFile) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BackupFile) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *BackupFile) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+type BackupRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// location is the backup storage, usually the backup_location of the
+	// database, like file:///var/backups.
+	Location string `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	// directory is the directory of the backups of the shard in the
+	// storage.
+	Directory     string `protobuf:"bytes,2,opt,name=directory,proto3" json:"directory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupRequest) Reset() {
+	*x = BackupRequest{}
+	mi := &file_pgctldata_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupRequest) ProtoMessage() {}
+
+func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *BackupRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *BackupRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+type BackupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Manifest      *BackupManifest        `protobuf:"bytes,1,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupResponse) Reset() {
+	*x = BackupResponse{}
+	mi := &file_pgctldata_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupResponse) ProtoMessage() {}
+
+func (x *BackupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupResponse.ProtoReflect.Descriptor instead.
+func (*BackupResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *BackupResponse) GetManifest() *BackupManifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+type ListBackupsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Location      string                 `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Directory     string                 `protobuf:"bytes,2,opt,name=directory,proto3" json:"directory,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBackupsRequest) Reset() {
+	*x = ListBackupsRequest{}
+	mi := &file_pgctldata_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBackupsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBackupsRequest) ProtoMessage() {}
+
+func (x *ListBackupsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBackupsRequest.ProtoReflect.Descriptor instead.
+func (*ListBackupsRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ListBackupsRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *ListBackupsRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+type ListBackupsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// manifests are the manifests of the complete backups, oldest first.
+	Manifests     []*BackupManifest `protobuf:"bytes,1,rep,name=manifests,proto3" json:"manifests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBackupsResponse) Reset() {
+	*x = ListBackupsResponse{}
+	mi := &file_pgctldata_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBackupsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBackupsResponse) ProtoMessage() {}
+
+func (x *ListBackupsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBackupsResponse.ProtoReflect.Descriptor instead.
+func (*ListBackupsResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ListBackupsResponse) GetManifests() []*BackupManifest {
+	if x != nil {
+		return x.Manifests
+	}
+	return nil
+}
+
+type RestoreRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Location  string                 `protobuf:"bytes,1,opt,name=location,proto3" json:"location,omitempty"`
+	Directory string                 `protobuf:"bytes,2,opt,name=directory,proto3" json:"directory,omitempty"`
+	// backup_name is the backup to restore. If empty, it is the latest
+	// backup before the target.
+	BackupName string `protobuf:"bytes,3,opt,name=backup_name,json=backupName,proto3" json:"backup_name,omitempty"`
+	// The recovery target, at most one. Without target, the server
+	// replays all the archived WAL.
+	//
+	// Types that are valid to be assigned to Target:
+	//
+	//	*RestoreRequest_TargetLsn
+	//	*RestoreRequest_TargetTime
+	Target        isRestoreRequest_Target `protobuf_oneof:"target"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
+	mi := &file_pgctldata_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRequest) ProtoMessage() {}
+
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *RestoreRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetDirectory() string {
+	if x != nil {
+		return x.Directory
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetBackupName() string {
+	if x != nil {
+		return x.BackupName
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetTarget() isRestoreRequest_Target {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+func (x *RestoreRequest) GetTargetLsn() string {
+	if x != nil {
+		if x, ok := x.Target.(*RestoreRequest_TargetLsn); ok {
+			return x.TargetLsn
+		}
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetTargetTime() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.Target.(*RestoreRequest_TargetTime); ok {
+			return x.TargetTime
+		}
+	}
+	return nil
+}
+
+type isRestoreRequest_Target interface {
+	isRestoreRequest_Target()
+}
+
+type RestoreRequest_TargetLsn struct {
+	// target_lsn is the WAL location to recover to, like 0/3000148.
+	TargetLsn string `protobuf:"bytes,4,opt,name=target_lsn,json=targetLsn,proto3,oneof"`
+}
+
+type RestoreRequest_TargetTime struct {
+	// target_time is the time to recover to.
+	TargetTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=target_time,json=targetTime,proto3,oneof"`
+}
+
+func (*RestoreRequest_TargetLsn) isRestoreRequest_Target() {}
+
+func (*RestoreRequest_TargetTime) isRestoreRequest_Target() {}
+
+type RestoreResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// manifest is the manifest of the restored backup.
+	Manifest *BackupManifest `protobuf:"bytes,1,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	// status is the status of the server, started to recover.
+	Status        *PostgresStatus `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreResponse) Reset() {
+	*x = RestoreResponse{}
+	mi := &file_pgctldata_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreResponse) ProtoMessage() {}
+
+func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_pgctldata_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreResponse.ProtoReflect.Descriptor instead.
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return file_pgctldata_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *RestoreResponse) GetManifest() *BackupManifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+func (x *RestoreResponse) GetStatus() *PostgresStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+var File_pgctldata_proto protoreflect.FileDescriptor
+
+const file_pgctldata_proto_rawDesc = "" +
+	"\n" +
+	"\x0fpgctldata.proto\x12\tpgctldata\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\vquery.proto\"\xbf\x02\n" +
+	"\x0ePostgresStatus\x12,\n" +
+	"\x05state\x18\x01 \x01(\x0e2\x16.pgctldata.ServerStateR\x05state\x12 \n" +
+	"\vinitialized\x18\x02 \x01(\bR\vinitialized\x12\x19\n" +
+	"\bdata_dir\x18\x03 \x01(\tR\adataDir\x12\x18\n" +
+	"\aversion\x18\x04 \x01(\tR\aversion\x12\x10\n" +
+	"\x03pid\x18\x05 \x01(\x05R\x03pid\x12\x12\n" +
+	"\x04port\x18\x06 \x01(\x05R\x04port\x129\n" +
+	"\n" +
+	"start_time\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x121\n" +
+	"\x06uptime\x18\b \x01(\v2\x19.google.protobuf.DurationR\x06uptime\x12\x14\n" +
+	"\x05ready\x18\t \x01(\bR\x05ready\"\x14\n" +
+	"\x12InitDataDirRequest\"H\n" +
+	"\x13InitDataDirResponse\x121\n" +
+	"\x06status\x18\x01 \x01(\v2\x19.pgctldata.PostgresStatusR\x06status\"\x0e\n" +
+	"\fStartRequest\"B\n" +
+	"\rStartResponse\x121\n" +
+	"\x06status\x18\x01 \x01(\v2\x19.pgctldata.PostgresStatusR\x06status\"6\n" +
+	"\vStopRequest\x12'\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x13.pgctldata.StopModeR\x04mode\"A\n" +
+	"\fStopResponse\x121\n" +
+	"\x06status\x18\x01 \x01(\v2\x19.pgctldata.PostgresStatusR\x06status\"9\n" +
+	"\x0eRestartRequest\x12'\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x13.pgctldata.StopModeR\x04mode\"D\n" +
+	"\x0fRestartResponse\x121\n" +
+	"\x06status\x18\x01 \x01(\v2\x19.pgctldata.PostgresStatusR\x06status\"\x15\n" +
+	"\x13ReloadConfigRequest\"\x16\n" +
+	"\x14ReloadConfigResponse\"\x0f\n" +
+	"\rStatusRequest\"C\n" +
+	"\x0eStatusResponse\x121\n" +
+	"\x06status\x18\x01 \x01(\v2\x19.pgctldata.PostgresStatusR\x06status\"\xbd\x01\n" +
+	"\x10SetConfigRequest\x12E\n" +
+	"\bsettings\x18\x01 \x03(\v2).pgctldata.SetConfigRequest.SettingsEntryR\bsettings\x12%\n" +
+	"\x0ereset_settings\x18\x02 \x03(\tR\rresetSettings\x1a;\n" +
+	"\rSettingsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x92\x01\n" +
+	"\x11SetConfigResponse\x12)\n" +
+	"\x10changed_settings\x18\x01 \x03(\tR\x0fchangedSettings\x12'\n" +
+	"\x0freload_required\x18\x02 \x01(\bR\x0ereloadRequired\x12)\n" +
+	"\x10restart_required\x18\x03 \x01(\bR\x0frestartRequired\"\xdd\x03\n" +
+	"\x11ReplicationStatus\x12\x1f\n" +
+	"\vin_recovery\x18\x01 \x01(\bR\n" +
+	"inRecovery\x12\x1f\n" +
+	"\vcurrent_lsn\x18\x02 \x01(\tR\n" +
+	"currentLsn\x12\x1f\n" +
+	"\vreceive_lsn\x18\x03 \x01(\tR\n" +
+	"receiveLsn\x12\x1d\n" +
+	"\n" +
+	"replay_lsn\x18\x04 \x01(\tR\treplayLsn\x12D\n" +
+	"\x10last_replay_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0elastReplayTime\x128\n" +
+	"\n" +
+	"replay_lag\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\treplayLag\x12#\n" +
+	"\rreplay_paused\x18\a \x01(\bR\freplayPaused\x129\n" +
+	"\fwal_receiver\x18\b \x01(\v2\x16.pgctldata.WalReceiverR\vwalReceiver\x124\n" +
+	"\bstandbys\x18\t \x03(\v2\x18.pgctldata.StandbyStatusR\bstandbys\x120\n" +
+	"\x05slots\x18\n" +
+	" \x03(\v2\x1a.pgctldata.ReplicationSlotR\x05slots\"\x88\x01\n" +
+	"\vWalReceiver\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12!\n" +
+	"\fprimary_host\x18\x02 \x01(\tR\vprimaryHost\x12!\n" +
+	"\fprimary_port\x18\x03 \x01(\x05R\vprimaryPort\x12\x1b\n" +
+	"\tslot_name\x18\x04 \x01(\tR\bslotName\"\xbe\x02\n" +
+	"\rStandbyStatus\x12)\n" +
+	"\x10application_name\x18\x01 \x01(\tR\x0fapplicationName\x12\x1f\n" +
+	"\vclient_addr\x18\x02 \x01(\tR\n" +
+	"clientAddr\x12\x14\n" +
+	"\x05state\x18\x03 \x01(\tR\x05state\x12\x19\n" +
+	"\bsent_lsn\x18\x04 \x01(\tR\asentLsn\x12\x1b\n" +
+	"\twrite_lsn\x18\x05 \x01(\tR\bwriteLsn\x12\x1b\n" +
+	"\tflush_lsn\x18\x06 \x01(\tR\bflushLsn\x12\x1d\n" +
+	"\n" +
+	"replay_lsn\x18\a \x01(\tR\treplayLsn\x128\n" +
+	"\n" +
+	"replay_lag\x18\b \x01(\v2\x19.google.protobuf.DurationR\treplayLag\x12\x1d\n" +
+	"\n" +
+	"sync_state\x18\t \x01(\tR\tsyncState\"r\n" +
+	"\x0fReplicationSlot\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06active\x18\x03 \x01(\bR\x06active\x12\x1f\n" +
+	"\vrestart_lsn\x18\x04 \x01(\tR\n" +
+	"restartLsn\"r\n" +
+	"\x19SetPrimaryConnInfoRequest\x12)\n" +
+	"\x10primary_conninfo\x18\x01 \x01(\tR\x0fprimaryConninfo\x12*\n" +
+	"\x11primary_slot_name\x18\x02 \x01(\tR\x0fprimarySlotName\"G\n" +
+	"\x1aSetPrimaryConnInfoResponse\x12)\n" +
+	"\x10restart_required\x18\x01 \x01(\bR\x0frestartRequired\"\x10\n" +
+	"\x0ePromoteRequest\"G\n" +
+	"\x0fPromoteResponse\x124\n" +
+	"\x06status\x18\x01 \x01(\v2\x1c.pgctldata.ReplicationStatusR\x06status\"2\n" +
+	"\x1cCreateReplicationSlotRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x1f\n" +
+	"\x1dCreateReplicationSlotResponse\"0\n" +
+	"\x1aDropReplicationSlotRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x1d\n" +
+	"\x1bDropReplicationSlotResponse\"\x14\n" +
+	"\x12PauseReplayRequest\"K\n" +
+	"\x13PauseReplayResponse\x124\n" +
+	"\x06status\x18\x01 \x01(\v2\x1c.pgctldata.ReplicationStatusR\x06status\"\x15\n" +
+	"\x13ResumeReplayRequest\"L\n" +
+	"\x14ResumeReplayResponse\x124\n" +
+	"\x06status\x18\x01 \x01(\v2\x1c.pgctldata.ReplicationStatusR\x06status\"\x1a\n" +
+	"\x18ReplicationStatusRequest\"Q\n" +
+	"\x19ReplicationStatusResponse\x124\n" +
+	"\x06status\x18\x01 \x01(\v2\x1c.pgctldata.ReplicationStatusR\x06status\"{\n" +
+	"\x13ExecuteFetchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x19\n" +
+	"\bmax_rows\x18\x02 \x01(\x03R\amaxRows\x123\n" +
+	"\atimeout\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"B\n" +
+	"\x14ExecuteFetchResponse\x12*\n" +
+	"\x06result\x18\x01 \x01(\v2\x12.query.QueryResultR\x06result\"\x93\x02\n" +
+	"\x0eBackupManifest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x129\n" +
+	"\n" +
+	"start_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x1b\n" +
+	"\tstart_lsn\x18\x05 \x01(\tR\bstartLsn\x12\x17\n" +
+	"\aend_lsn\x18\x06 \x01(\tR\x06endLsn\x12+\n" +
+	"\x05files\x18\a \x03(\v2\x15.pgctldata.BackupFileR\x05files\"L\n" +
+	"\n" +
+	"BackupFile\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\"I\n" +
+	"\rBackupRequest\x12\x1a\n" +
+	"\blocation\x18\x01 \x01(\tR\blocation\x12\x1c\n" +
+	"\tdirectory\x18\x02 \x01(\tR\tdirectory\"G\n" +
+	"\x0eBackupResponse\x125\n" +
+	"\bmanifest\x18\x01 \x01(\v2\x19.pgctldata.BackupManifestR\bmanifest\"N\n" +
+	"\x12ListBackupsRequest\x12\x1a\n" +
+	"\blocation\x18\x01 \x01(\tR\blocation\x12\x1c\n" +
+	"\tdirectory\x18\x02 \x01(\tR\tdirectory\"N\n" +
+	"\x13ListBackupsResponse\x127\n" +
+	"\tmanifests\x18\x01 \x03(\v2\x19.pgctldata.BackupManifestR\tmanifests\"\xd5\x01\n" +
+	"\x0eRestoreRequest\x12\x1a\n" +
+	"\blocation\x18\x01 \x01(\tR\blocation\x12\x1c\n" +
+	"\tdirectory\x18\x02 \x01(\tR\tdirectory\x12\x1f\n" +
+	"\vbackup_name\x18\x03 \x01(\tR\n" +
+	"backupName\x12\x1f\n" +
+	"\n" +
+	"target_lsn\x18\x04 \x01(\tH\x00R\ttargetLsn\x12=\n" +
+	"\vtarget_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampH\x00R\n" +
+	"targetTimeB\b\n" +
+	"\x06target\"{\n" +
+	"\x0fRestoreResponse\x125\n" +
+	"\bmanifest\x18\x01 \x01(\v2\x19.pgctldata.BackupManifestR\bmanifest\x121\n" +
+	"\x06status\x18\x02 \x01(\v2\x19.pgctldata.PostgresStatusR\x06status*'\n" +
+	"\vServerState\x12\v\n" +
+	"\aSTOPPED\x10\x00\x12\v\n" +
+	"\aRUNNING\x10\x01*.\n" +
+	"\bStopMode\x12\b\n" +
+	"\x04FAST\x10\x00\x12\t\n" +
+	"\x05SMART\x10\x01\x12\r\n" +
+	"\tIMMEDIATE\x10\x02B0Z.github.com/multigres/multigres/go/pb/pgctldatab\x06proto3"
+
+var (
+	file_pgctldata_proto_rawDescOnce sync.Once
+	file_pgctldata_proto_rawDescData []byte
+)
+
+func file_pgctldata_proto_rawDescGZIP() []byte {
+	file_pgctldata_proto_rawDescOnce.Do(func() {
+		file_pgctldata_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_pgctldata_proto_rawDesc), len(file_pgctldata_proto_rawDesc)))
+	})
+	return file_pgctldata_proto_rawDescData
+}
+
+var file_pgctldata_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_pgctldata_proto_msgTypes = make([]protoimpl.MessageInfo, 44)
+var file_pgctldata_proto_goTypes = []any{
+	(ServerState)(0),                      // 0: pgctldata.ServerState
+	(StopMode)(0),                         // 1: pgctldata.StopMode
+	(*PostgresStatus)(nil),                // 2: pgctldata.PostgresStatus
+	(*InitDataDirRequest)(nil),            // 3: pgctldata.InitDataDirRequest
+	(*InitDataDirResponse)(nil),           // 4: pgctldata.InitDataDirResponse
+	(*StartRequest)(nil),                  // 5: pgctldata.StartRequest
+	(*StartResponse)(nil),                 // 6: pgctldata.StartResponse
+	(*StopRequest)(nil),                   // 7: pgctldata.StopRequest
+	(*StopResponse)(nil),                  // 8: pgctldata.StopResponse
+	(*RestartRequest)(nil),                // 9: pgctldata.RestartRequest
+	(*RestartResponse)(nil),               // 10: pgctldata.RestartResponse
+	(*ReloadConfigRequest)(nil),           // 11: pgctldata.ReloadConfigRequest
+	(*ReloadConfigResponse)(nil),          // 12: pgctldata.ReloadConfigResponse
+	(*StatusRequest)(nil),                 // 13: pgctldata.StatusRequest
+	(*StatusResponse)(nil),                // 14: pgctldata.StatusResponse
+	(*SetConfigRequest)(nil),              // 15: pgctldata.SetConfigRequest
+	(*SetConfigResponse)(nil),             // 16: pgctldata.SetConfigResponse
+	(*ReplicationStatus)(nil),             // 17: pgctldata.ReplicationStatus
+	(*WalReceiver)(nil),                   // 18: pgctldata.WalReceiver
+	(*StandbyStatus)(nil),                 // 19: pgctldata.StandbyStatus
+	(*ReplicationSlot)(nil),               // 20: pgctldata.ReplicationSlot
+	(*SetPrimaryConnInfoRequest)(nil),     // 21: pgctldata.SetPrimaryConnInfoRequest
+	(*SetPrimaryConnInfoResponse)(nil),    // 22: pgctldata.SetPrimaryConnInfoResponse
+	(*PromoteRequest)(nil),                // 23: pgctldata.PromoteRequest
+	(*PromoteResponse)(nil),               // 24: pgctldata.PromoteResponse
+	(*CreateReplicationSlotRequest)(nil),  // 25: pgctldata.CreateReplicationSlotRequest
+	(*CreateReplicationSlotResponse)(nil), // 26: pgctldata.CreateReplicationSlotResponse
+	(*DropReplicationSlotRequest)(nil),    // 27: pgctldata.DropReplicationSlotRequest
+	(*DropReplicationSlotResponse)(nil),   // 28: pgctldata.DropReplicationSlotResponse
+	(*PauseReplayRequest)(nil),            // 29: pgctldata.PauseReplayRequest
+	(*PauseReplayResponse)(nil),           // 30: pgctldata.PauseReplayResponse
+	(*ResumeReplayRequest)(nil),           // 31: pgctldata.ResumeReplayRequest
+	(*ResumeReplayResponse)(nil),          // 32: pgctldata.ResumeReplayResponse
+	(*ReplicationStatusRequest)(nil),      // 33: pgctldata.ReplicationStatusRequest
+	(*ReplicationStatusResponse)(nil),     // 34: pgctldata.ReplicationStatusResponse
+	(*ExecuteFetchRequest)(nil),           // 35: pgctldata.ExecuteFetchRequest
+	(*ExecuteFetchResponse)(nil),          // 36: pgctldata.ExecuteFetchResponse
+	(*BackupManifest)(nil),                // 37: pgctldata.BackupManifest
+	(*BackupFile)(nil),                    // 38: pgctldata.BackupFile
+	(*BackupRequest)(nil),                 // 39: pgctldata.BackupRequest
+	(*BackupResponse)(nil),                // 40: pgctldata.BackupResponse
+	(*ListBackupsRequest)(nil),            // 41: pgctldata.ListBackupsRequest
+	(*ListBackupsResponse)(nil),           // 42: pgctldata.ListBackupsResponse
+	(*RestoreRequest)(nil),                // 43: pgctldata.RestoreRequest
+	(*RestoreResponse)(nil),               // 44: pgctldata.RestoreResponse
+	nil,                                   // 45: pgctldata.SetConfigRequest.SettingsEntry
+	(*timestamppb.Timestamp)(nil),         // 46: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),           // 47: google.protobuf.Duration
+	(*query.QueryResult)(nil),             // 48: query.QueryResult
+}
+var file_pgctldata_proto_depIdxs = []int32{
+	0,  // 0: pgctldata.PostgresStatus.state:type_name -> pgctldata.ServerState
+	46, // 1: pgctldata.PostgresStatus.start_time:type_name -> google.protobuf.Timestamp
+	47, // 2: pgctldata.PostgresStatus.uptime:type_name -> google.protobuf.Duration
+	2,  // 3: pgctldata.InitDataDirResponse.status:type_name -> pgctldata.PostgresStatus
+	2,  // 4: pgctldata.StartResponse.status:type_name -> pgctldata.PostgresStatus
+	1,  // 5: pgctldata.StopRequest.mode:type_name -> pgctldata.StopMode
+	2,  // 6: pgctldata.StopResponse.status:type_name -> pgctldata.PostgresStatus
+	1,  // 7: pgctldata.RestartRequest.mode:type_name -> pgctldata.StopMode
+	2,  // 8: pgctldata.RestartResponse.status:type_name -> pgctldata.PostgresStatus
+	2,  // 9: pgctldata.StatusResponse.status:type_name -> pgctldata.PostgresStatus
+	45, // 10: pgctldata.SetConfigRequest.settings:type_name -> pgctldata.SetConfigRequest.SettingsEntry
+	46, // 11: pgctldata.ReplicationStatus.last_replay_time:type_name -> google.protobuf.Timestamp
+	47, // 12: pgctldata.ReplicationStatus.replay_lag:type_name -> google.protobuf.Duration
+	18, // 13: pgctldata.ReplicationStatus.wal_receiver:type_name -> pgctldata.WalReceiver
+	19, // 14: pgctldata.ReplicationStatus.standbys:type_name -> pgctldata.StandbyStatus
+	20, // 15: pgctldata.ReplicationStatus.slots:type_name -> pgctldata.ReplicationSlot
+	47, // 16: pgctldata.StandbyStatus.replay_lag:type_name -> google.protobuf.Duration
+	17, // 17: pgctldata.PromoteResponse.status:type_name -> pgctldata.ReplicationStatus
+	17, // 18: pgctldata.PauseReplayResponse.status:type_name -> pgctldata.ReplicationStatus
+	17, // 19: pgctldata.ResumeReplayResponse.status:type_name -> pgctldata.ReplicationStatus
+	17, // 20: pgctldata.ReplicationStatusResponse.status:type_name -> pgctldata.ReplicationStatus
+	47, // 21: pgctldata.ExecuteFetchRequest.timeout:type_name -> google.protobuf.Duration
+	48, // 22: pgctldata.ExecuteFetchResponse.result:type_name -> query.QueryResult
+	46, // 23: pgctldata.BackupManifest.start_time:type_name -> google.protobuf.Timestamp
+	46, // 24: pgctldata.BackupManifest.end_time:type_name -> google.protobuf.Timestamp
+	38, // 25: pgctldata.BackupManifest.files:type_name -> pgctldata.BackupFile
+	37, // 26: pgctldata.BackupResponse.manifest:type_name -> pgctldata.BackupManifest
+	37, // 27: pgctldata.ListBackupsResponse.manifests:type_name -> pgctldata.BackupManifest
+	46, // 28: pgctldata.RestoreRequest.target_time:type_name -> google.protobuf.Timestamp
+	37, // 29: pgctldata.RestoreResponse.manifest:type_name -> pgctldata.BackupManifest
+	2,  // 30: pgctldata.RestoreResponse.status:type_name -> pgctldata.PostgresStatus
+	31, // [31:31] is the sub-list for method output_type
+	31, // [31:31] is the sub-list for method input_type
+	31, // [31:31] is the sub-list for extension type_name
+	31, // [31:31] is the sub-list for extension extendee
+	0,  // [0:31] is the sub-list for field type_name
+}
+
+func init() { file_pgctldata_proto_init() }
+func file_pgctldata_proto_init() {
+	if File_pgctldata_proto != nil {
+		return
+	}
+	file_pgctldata_proto_msgTypes[41].OneofWrappers = []any{
+		(*RestoreRequest_TargetLsn)(nil),
+		(*RestoreRequest_TargetTime)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pgctldata_proto_rawDesc), len(file_pgctldata_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   44,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_pgctldata_proto_goTypes,
+		DependencyIndexes: file_pgctldata_proto_depIdxs,
+		EnumInfos:         file_pgctldata_proto_enumTypes,
+		MessageInfos:      file_pgctldata_proto_msgTypes,
+	}.Build()
+	File_pgctldata_proto = out.File
+	file_pgctldata_proto_goTypes = nil
+	file_pgctldata_proto_depIdxs = nil
+}
@@ -0,0 +1,168 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the service definition of pgctld.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v4.25.1
+// source: pgctldservice.proto
+
+package pgctldservice
+
+import (
+	pgctldata "github.com/multigres/multigres/go/pb/pgctldata"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_pgctldservice_proto protoreflect.FileDescriptor
+
+const file_pgctldservice_proto_rawDesc = "" +
+	"\n" +
+	"\x13pgctldservice.proto\x12\rpgctldservice\x1a\x0fpgctldata.proto2\x9f\v\n" +
+	"\x06PgCtld\x12N\n" +
+	"\vInitDataDir\x12\x1d.pgctldata.InitDataDirRequest\x1a\x1e.pgctldata.InitDataDirResponse\"\x00\x12<\n" +
+	"\x05Start\x12\x17.pgctldata.StartRequest\x1a\x18.pgctldata.StartResponse\"\x00\x129\n" +
+	"\x04Stop\x12\x16.pgctldata.StopRequest\x1a\x17.pgctldata.StopResponse\"\x00\x12B\n" +
+	"\aRestart\x12\x19.pgctldata.RestartRequest\x1a\x1a.pgctldata.RestartResponse\"\x00\x12Q\n" +
+	"\fReloadConfig\x12\x1e.pgctldata.ReloadConfigRequest\x1a\x1f.pgctldata.ReloadConfigResponse\"\x00\x12H\n" +
+	"\tSetConfig\x12\x1b.pgctldata.SetConfigRequest\x1a\x1c.pgctldata.SetConfigResponse\"\x00\x12?\n" +
+	"\x06Status\x12\x18.pgctldata.StatusRequest\x1a\x19.pgctldata.StatusResponse\"\x00\x12c\n" +
+	"\x12SetPrimaryConnInfo\x12$.pgctldata.SetPrimaryConnInfoRequest\x1a%.pgctldata.SetPrimaryConnInfoResponse\"\x00\x12B\n" +
+	"\aPromote\x12\x19.pgctldata.PromoteRequest\x1a\x1a.pgctldata.PromoteResponse\"\x00\x12l\n" +
+	"\x15CreateReplicationSlot\x12'.pgctldata.CreateReplicationSlotRequest\x1a(.pgctldata.CreateReplicationSlotResponse\"\x00\x12f\n" +
+	"\x13DropReplicationSlot\x12%.pgctldata.DropReplicationSlotRequest\x1a&.pgctldata.DropReplicationSlotResponse\"\x00\x12N\n" +
+	"\vPauseReplay\x12\x1d.pgctldata.PauseReplayRequest\x1a\x1e.pgctldata.PauseReplayResponse\"\x00\x12Q\n" +
+	"\fResumeReplay\x12\x1e.pgctldata.ResumeReplayRequest\x1a\x1f.pgctldata.ResumeReplayResponse\"\x00\x12`\n" +
+	"\x11ReplicationStatus\x12#.pgctldata.ReplicationStatusRequest\x1a$.pgctldata.ReplicationStatusResponse\"\x00\x12Q\n" +
+	"\fExecuteFetch\x12\x1e.pgctldata.ExecuteFetchRequest\x1a\x1f.pgctldata.ExecuteFetchResponse\"\x00\x12?\n" +
+	"\x06Backup\x12\x18.pgctldata.BackupRequest\x1a\x19.pgctldata.BackupResponse\"\x00\x12N\n" +
+	"\vListBackups\x12\x1d.pgctldata.ListBackupsRequest\x1a\x1e.pgctldata.ListBackupsResponse\"\x00\x12B\n" +
+	"\aRestore\x12\x19.pgctldata.RestoreRequest\x1a\x1a.pgctldata.RestoreResponse\"\x00B4Z2github.com/multigres/multigres/go/pb/pgctldserviceb\x06proto3"
+
+var file_pgctldservice_proto_goTypes = []any{
+	(*pgctldata.InitDataDirRequest)(nil),            // 0: pgctldata.InitDataDirRequest
+	(*pgctldata.StartRequest)(nil),                  // 1: pgctldata.StartRequest
+	(*pgctldata.StopRequest)(nil),                   // 2: pgctldata.StopRequest
+	(*pgctldata.RestartRequest)(nil),                // 3: pgctldata.RestartRequest
+	(*pgctldata.ReloadConfigRequest)(nil),           // 4: pgctldata.ReloadConfigRequest
+	(*pgctldata.SetConfigRequest)(nil),              // 5: pgctldata.SetConfigRequest
+	(*pgctldata.StatusRequest)(nil),                 // 6: pgctldata.StatusRequest
+	(*pgctldata.SetPrimaryConnInfoRequest)(nil),     // 7: pgctldata.SetPrimaryConnInfoRequest
+	(*pgctldata.PromoteRequest)(nil),                // 8: pgctldata.PromoteRequest
+	(*pgctldata.CreateReplicationSlotRequest)(nil),  // 9: pgctldata.CreateReplicationSlotRequest
+	(*pgctldata.DropReplicationSlotRequest)(nil),    // 10: pgctldata.DropReplicationSlotRequest
+	(*pgctldata.PauseReplayRequest)(nil),            // 11: pgctldata.PauseReplayRequest
+	(*pgctldata.ResumeReplayRequest)(nil),           // 12: pgctldata.ResumeReplayRequest
+	(*pgctldata.ReplicationStatusRequest)(nil),      // 13: pgctldata.ReplicationStatusRequest
+	(*pgctldata.ExecuteFetchRequest)(nil),           // 14: pgctldata.ExecuteFetchRequest
+	(*pgctldata.BackupRequest)(nil),                 // 15: pgctldata.BackupRequest
+	(*pgctldata.ListBackupsRequest)(nil),            // 16: pgctldata.ListBackupsRequest
+	(*pgctldata.RestoreRequest)(nil),                // 17: pgctldata.RestoreRequest
+	(*pgctldata.InitDataDirResponse)(nil),           // 18: pgctldata.InitDataDirResponse
+	(*pgctldata.StartResponse)(nil),                 // 19: pgctldata.StartResponse
+	(*pgctldata.StopResponse)(nil),                  // 20: pgctldata.StopResponse
+	(*pgctldata.RestartResponse)(nil),               // 21: pgctldata.RestartResponse
+	(*pgctldata.ReloadConfigResponse)(nil),          // 22: pgctldata.ReloadConfigResponse
+	(*pgctldata.SetConfigResponse)(nil),             // 23: pgctldata.SetConfigResponse
+	(*pgctldata.StatusResponse)(nil),                // 24: pgctldata.StatusResponse
+	(*pgctldata.SetPrimaryConnInfoResponse)(nil),    // 25: pgctldata.SetPrimaryConnInfoResponse
+	(*pgctldata.PromoteResponse)(nil),               // 26: pgctldata.PromoteResponse
+	(*pgctldata.CreateReplicationSlotResponse)(nil), // 27: pgctldata.CreateReplicationSlotResponse
+	(*pgctldata.DropReplicationSlotResponse)(nil),   // 28: pgctldata.DropReplicationSlotResponse
+	(*pgctldata.PauseReplayResponse)(nil),           // 29: pgctldata.PauseReplayResponse
+	(*pgctldata.ResumeReplayResponse)(nil),          // 30: pgctldata.ResumeReplayResponse
+	(*pgctldata.ReplicationStatusResponse)(nil),     // 31: pgctldata.ReplicationStatusResponse
+	(*pgctldata.ExecuteFetchResponse)(nil),          // 32: pgctldata.ExecuteFetchResponse
+	(*pgctldata.BackupResponse)(nil),                // 33: pgctldata.BackupResponse
+	(*pgctldata.ListBackupsResponse)(nil),           // 34: pgctldata.ListBackupsResponse
+	(*pgctldata.RestoreResponse)(nil),               // 35: pgctldata.RestoreResponse
+}
+var file_pgctldservice_proto_depIdxs = []int32{
+	0,  // 0: pgctldservice.PgCtld.InitDataDir:input_type -> pgctldata.InitDataDirRequest
+	1,  // 1: pgctldservice.PgCtld.Start:input_type -> pgctldata.StartRequest
+	2,  // 2: pgctldservice.PgCtld.Stop:input_type -> pgctldata.StopRequest
+	3,  // 3: pgctldservice.PgCtld.Restart:input_type -> pgctldata.RestartRequest
+	4,  // 4: pgctldservice.PgCtld.ReloadConfig:input_type -> pgctldata.ReloadConfigRequest
+	5,  // 5: pgctldservice.PgCtld.SetConfig:input_type -> pgctldata.SetConfigRequest
+	6,  // 6: pgctldservice.PgCtld.Status:input_type -> pgctldata.StatusRequest
+	7,  // 7: pgctldservice.PgCtld.SetPrimaryConnInfo:input_type -> pgctldata.SetPrimaryConnInfoRequest
+	8,  // 8: pgctldservice.PgCtld.Promote:input_type -> pgctldata.PromoteRequest
+	9,  // 9: pgctldservice.PgCtld.CreateReplicationSlot:input_type -> pgctldata.CreateReplicationSlotRequest
+	10, // 10: pgctldservice.PgCtld.DropReplicationSlot:input_type -> pgctldata.DropReplicationSlotRequest
+	11, // 11: pgctldservice.PgCtld.PauseReplay:input_type -> pgctldata.PauseReplayRequest
+	12, // 12: pgctldservice.PgCtld.ResumeReplay:input_type -> pgctldata.ResumeReplayRequest
+	13, // 13: pgctldservice.PgCtld.ReplicationStatus:input_type -> pgctldata.ReplicationStatusRequest
+	14, // 14: pgctldservice.PgCtld.ExecuteFetch:input_type -> pgctldata.ExecuteFetchRequest
+	15, // 15: pgctldservice.PgCtld.Backup:input_type -> pgctldata.BackupRequest
+	16, // 16: pgctldservice.PgCtld.ListBackups:input_type -> pgctldata.ListBackupsRequest
+	17, // 17: pgctldservice.PgCtld.Restore:input_type -> pgctldata.RestoreRequest
+	18, // 18: pgctldservice.PgCtld.InitDataDir:output_type -> pgctldata.InitDataDirResponse
+	19, // 19: pgctldservice.PgCtld.Start:output_type -> pgctldata.StartResponse
+	20, // 20: pgctldservice.PgCtld.Stop:output_type -> pgctldata.StopResponse
+	21, // 21: pgctldservice.PgCtld.Restart:output_type -> pgctldata.RestartResponse
+	22, // 22: pgctldservice.PgCtld.ReloadConfig:output_type -> pgctldata.ReloadConfigResponse
+	23, // 23: pgctldservice.PgCtld.SetConfig:output_type -> pgctldata.SetConfigResponse
+	24, // 24: pgctldservice.PgCtld.Status:output_type -> pgctldata.StatusResponse
+	25, // 25: pgctldservice.PgCtld.SetPrimaryConnInfo:output_type -> pgctldata.SetPrimaryConnInfoResponse
+	26, // 26: pgctldservice.PgCtld.Promote:output_type -> pgctldata.PromoteResponse
+	27, // 27: pgctldservice.PgCtld.CreateReplicationSlot:output_type -> pgctldata.CreateReplicationSlotResponse
+	28, // 28: pgctldservice.PgCtld.DropReplicationSlot:output_type -> pgctldata.DropReplicationSlotResponse
+	29, // 29: pgctldservice.PgCtld.PauseReplay:output_type -> pgctldata.PauseReplayResponse
+	30, // 30: pgctldservice.PgCtld.ResumeReplay:output_type -> pgctldata.ResumeReplayResponse
+	31, // 31: pgctldservice.PgCtld.ReplicationStatus:output_type -> pgctldata.ReplicationStatusResponse
+	32, // 32: pgctldservice.PgCtld.ExecuteFetch:output_type -> pgctldata.ExecuteFetchResponse
+	33, // 33: pgctldservice.PgCtld.Backup:output_type -> pgctldata.BackupResponse
+	34, // 34: pgctldservice.PgCtld.ListBackups:output_type -> pgctldata.ListBackupsResponse
+	35, // 35: pgctldservice.PgCtld.Restore:output_type -> pgctldata.RestoreResponse
+	18, // [18:36] is the sub-list for method output_type
+	0,  // [0:18] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_pgctldservice_proto_init() }
+func file_pgctldservice_proto_init() {
+	if File_pgctldservice_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_pgctldservice_proto_rawDesc), len(file_pgctldservice_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_pgctldservice_proto_goTypes,
+		DependencyIndexes: file_pgctldservice_proto_depIdxs,
+	}.Build()
+	File_pgctldservice_proto = out.File
+	file_pgctldservice_proto_goTypes = nil
+	file_pgctldservice_proto_depIdxs = nil
+}
@@ -0,0 +1,825 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the service definition of pgctld.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: pgctldservice.proto
+
+package pgctldservice
+
+import (
+	context "context"
+	pgctldata "github.com/multigres/multigres/go/pb/pgctldata"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PgCtld_InitDataDir_FullMethodName           = "/pgctldservice.PgCtld/InitDataDir"
+	PgCtld_Start_FullMethodName                 = "/pgctldservice.PgCtld/Start"
+	PgCtld_Stop_FullMethodName                  = "/pgctldservice.PgCtld/Stop"
+	PgCtld_Restart_FullMethodName               = "/pgctldservice.PgCtld/Restart"
+	PgCtld_ReloadConfig_FullMethodName          = "/pgctldservice.PgCtld/ReloadConfig"
+	PgCtld_SetConfig_FullMethodName             = "/pgctldservice.PgCtld/SetConfig"
+	PgCtld_Status_FullMethodName                = "/pgctldservice.PgCtld/Status"
+	PgCtld_SetPrimaryConnInfo_FullMethodName    = "/pgctldservice.PgCtld/SetPrimaryConnInfo"
+	PgCtld_Promote_FullMethodName               = "/pgctldservice.PgCtld/Promote"
+	PgCtld_CreateReplicationSlot_FullMethodName = "/pgctldservice.PgCtld/CreateReplicationSlot"
+	PgCtld_DropReplicationSlot_FullMethodName   = "/pgctldservice.PgCtld/DropReplicationSlot"
+	PgCtld_PauseReplay_FullMethodName           = "/pgctldservice.PgCtld/PauseReplay"
+	PgCtld_ResumeReplay_FullMethodName          = "/pgctldservice.PgCtld/ResumeReplay"
+	PgCtld_ReplicationStatus_FullMethodName     = "/pgctldservice.PgCtld/ReplicationStatus"
+	PgCtld_ExecuteFetch_FullMethodName          = "/pgctldservice.PgCtld/ExecuteFetch"
+	PgCtld_Backup_FullMethodName                = "/pgctldservice.PgCtld/Backup"
+	PgCtld_ListBackups_FullMethodName           = "/pgctldservice.PgCtld/ListBackups"
+	PgCtld_Restore_FullMethodName               = "/pgctldservice.PgCtld/Restore"
+)
+
+// PgCtldClient is the client API for PgCtld service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PgCtldClient interface {
+	// InitDataDir initializes the data directory with initdb. It fails if
+	// the data directory is already initialized.
+	InitDataDir(ctx context.Context, in *pgctldata.InitDataDirRequest, opts ...grpc.CallOption) (*pgctldata.InitDataDirResponse, error)
+	// Start starts the server, and waits until it accepts connections. It
+	// stops the server if it doesn't in time, and does nothing if the server
+	// is already running.
+	Start(ctx context.Context, in *pgctldata.StartRequest, opts ...grpc.CallOption) (*pgctldata.StartResponse, error)
+	// Stop stops the server in the requested mode, and waits until it
+	// exits. It does nothing if the server is not running.
+	Stop(ctx context.Context, in *pgctldata.StopRequest, opts ...grpc.CallOption) (*pgctldata.StopResponse, error)
+	// Restart stops the server if it is running, then starts it.
+	Restart(ctx context.Context, in *pgctldata.RestartRequest, opts ...grpc.CallOption) (*pgctldata.RestartResponse, error)
+	// ReloadConfig writes the configuration files again, with the current
+	// overrides, and makes the running server reload them.
+	ReloadConfig(ctx context.Context, in *pgctldata.ReloadConfigRequest, opts ...grpc.CallOption) (*pgctldata.ReloadConfigResponse, error)
+	// SetConfig sets postgresql.conf settings, and writes the configuration
+	// files. The settings are validated, and nothing is changed if any is
+	// invalid. It reports whether the running server must reload its
+	// configuration or restart to apply them.
+	SetConfig(ctx context.Context, in *pgctldata.SetConfigRequest, opts ...grpc.CallOption) (*pgctldata.SetConfigResponse, error)
+	// Status returns the status of the server.
+	Status(ctx context.Context, in *pgctldata.StatusRequest, opts ...grpc.CallOption) (*pgctldata.StatusResponse, error)
+	// SetPrimaryConnInfo sets the primary_conninfo and primary_slot_name
+	// settings, and makes the running server reload them. The server
+	// becomes a standby of the primary, at its next start if it runs as a
+	// primary.
+	SetPrimaryConnInfo(ctx context.Context, in *pgctldata.SetPrimaryConnInfoRequest, opts ...grpc.CallOption) (*pgctldata.SetPrimaryConnInfoResponse, error)
+	// Promote promotes the standby to primary, and waits until it accepts
+	// writes. It does nothing if the server is already a primary.
+	Promote(ctx context.Context, in *pgctldata.PromoteRequest, opts ...grpc.CallOption) (*pgctldata.PromoteResponse, error)
+	// CreateReplicationSlot creates a physical replication slot.
+	CreateReplicationSlot(ctx context.Context, in *pgctldata.CreateReplicationSlotRequest, opts ...grpc.CallOption) (*pgctldata.CreateReplicationSlotResponse, error)
+	// DropReplicationSlot drops a replication slot.
+	DropReplicationSlot(ctx context.Context, in *pgctldata.DropReplicationSlotRequest, opts ...grpc.CallOption) (*pgctldata.DropReplicationSlotResponse, error)
+	// PauseReplay pauses the replay of the WAL on a standby. The standby
+	// still receives the WAL.
+	PauseReplay(ctx context.Context, in *pgctldata.PauseReplayRequest, opts ...grpc.CallOption) (*pgctldata.PauseReplayResponse, error)
+	// ResumeReplay resumes the replay of the WAL on a standby.
+	ResumeReplay(ctx context.Context, in *pgctldata.ResumeReplayRequest, opts ...grpc.CallOption) (*pgctldata.ResumeReplayResponse, error)
+	// ReplicationStatus returns the replication status of the server.
+	ReplicationStatus(ctx context.Context, in *pgctldata.ReplicationStatusRequest, opts ...grpc.CallOption) (*pgctldata.ReplicationStatusResponse, error)
+	// ExecuteFetch runs a SQL statement on the server as the superuser, and
+	// returns its result. It is meant for the administrative queries of the
+	// orchestration and of the tools, not for the queries of the users.
+	ExecuteFetch(ctx context.Context, in *pgctldata.ExecuteFetchRequest, opts ...grpc.CallOption) (*pgctldata.ExecuteFetchResponse, error)
+	// Backup takes a backup of the running server to the backup storage,
+	// with the WAL archived since the previous backup.
+	Backup(ctx context.Context, in *pgctldata.BackupRequest, opts ...grpc.CallOption) (*pgctldata.BackupResponse, error)
+	// ListBackups returns the complete backups of the backup storage.
+	ListBackups(ctx context.Context, in *pgctldata.ListBackupsRequest, opts ...grpc.CallOption) (*pgctldata.ListBackupsResponse, error)
+	// Restore restores a backup in the data directory, which must not be
+	// initialized, and starts the server to recover to the target.
+	Restore(ctx context.Context, in *pgctldata.RestoreRequest, opts ...grpc.CallOption) (*pgctldata.RestoreResponse, error)
+}
+
+type pgCtldClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPgCtldClient(cc grpc.ClientConnInterface) PgCtldClient {
+	return &pgCtldClient{cc}
+}
+
+func (c *pgCtldClient) InitDataDir(ctx context.Context, in *pgctldata.InitDataDirRequest, opts ...grpc.CallOption) (*pgctldata.InitDataDirResponse, error) {
+	out := new(pgctldata.InitDataDirResponse)
+	err := c.cc.Invoke(ctx, PgCtld_InitDataDir_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) Start(ctx context.Context, in *pgctldata.StartRequest, opts ...grpc.CallOption) (*pgctldata.StartResponse, error) {
+	out := new(pgctldata.StartResponse)
+	err := c.cc.Invoke(ctx, PgCtld_Start_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) Stop(ctx context.Context, in *pgctldata.StopRequest, opts ...grpc.CallOption) (*pgctldata.StopResponse, error) {
+	out := new(pgctldata.StopResponse)
+	err := c.cc.Invoke(ctx, PgCtld_Stop_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) Restart(ctx context.Context, in *pgctldata.RestartRequest, opts ...grpc.CallOption) (*pgctldata.RestartResponse, error) {
+	out := new(pgctldata.RestartResponse)
+	err := c.cc.Invoke(ctx, PgCtld_Restart_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) ReloadConfig(ctx context.Context, in *pgctldata.ReloadConfigRequest, opts ...grpc.CallOption) (*pgctldata.ReloadConfigResponse, error) {
+	out := new(pgctldata.ReloadConfigResponse)
+	err := c.cc.Invoke(ctx, PgCtld_ReloadConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) SetConfig(ctx context.Context, in *pgctldata.SetConfigRequest, opts ...grpc.CallOption) (*pgctldata.SetConfigResponse, error) {
+	out := new(pgctldata.SetConfigResponse)
+	err := c.cc.Invoke(ctx, PgCtld_SetConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) Status(ctx context.Context, in *pgctldata.StatusRequest, opts ...grpc.CallOption) (*pgctldata.StatusResponse, error) {
+	out := new(pgctldata.StatusResponse)
+	err := c.cc.Invoke(ctx, PgCtld_Status_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) SetPrimaryConnInfo(ctx context.Context, in *pgctldata.SetPrimaryConnInfoRequest, opts ...grpc.CallOption) (*pgctldata.SetPrimaryConnInfoResponse, error) {
+	out := new(pgctldata.SetPrimaryConnInfoResponse)
+	err := c.cc.Invoke(ctx, PgCtld_SetPrimaryConnInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) Promote(ctx context.Context, in *pgctldata.PromoteRequest, opts ...grpc.CallOption) (*pgctldata.PromoteResponse, error) {
+	out := new(pgctldata.PromoteResponse)
+	err := c.cc.Invoke(ctx, PgCtld_Promote_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) CreateReplicationSlot(ctx context.Context, in *pgctldata.CreateReplicationSlotRequest, opts ...grpc.CallOption) (*pgctldata.CreateReplicationSlotResponse, error) {
+	out := new(pgctldata.CreateReplicationSlotResponse)
+	err := c.cc.Invoke(ctx, PgCtld_CreateReplicationSlot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) DropReplicationSlot(ctx context.Context, in *pgctldata.DropReplicationSlotRequest, opts ...grpc.CallOption) (*pgctldata.DropReplicationSlotResponse, error) {
+	out := new(pgctldata.DropReplicationSlotResponse)
+	err := c.cc.Invoke(ctx, PgCtld_DropReplicationSlot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) PauseReplay(ctx context.Context, in *pgctldata.PauseReplayRequest, opts ...grpc.CallOption) (*pgctldata.PauseReplayResponse, error) {
+	out := new(pgctldata.PauseReplayResponse)
+	err := c.cc.Invoke(ctx, PgCtld_PauseReplay_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) ResumeReplay(ctx context.Context, in *pgctldata.ResumeReplayRequest, opts ...grpc.CallOption) (*pgctldata.ResumeReplayResponse, error) {
+	out := new(pgctldata.ResumeReplayResponse)
+	err := c.cc.Invoke(ctx, PgCtld_ResumeReplay_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) ReplicationStatus(ctx context.Context, in *pgctldata.ReplicationStatusRequest, opts ...grpc.CallOption) (*pgctldata.ReplicationStatusResponse, error) {
+	out := new(pgctldata.ReplicationStatusResponse)
+	err := c.cc.Invoke(ctx, PgCtld_ReplicationStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) ExecuteFetch(ctx context.Context, in *pgctldata.ExecuteFetchRequest, opts ...grpc.CallOption) (*pgctldata.ExecuteFetchResponse, error) {
+	out := new(pgctldata.ExecuteFetchResponse)
+	err := c.cc.Invoke(ctx, PgCtld_ExecuteFetch_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) Backup(ctx context.Context, in *pgctldata.BackupRequest, opts ...grpc.CallOption) (*pgctldata.BackupResponse, error) {
+	out := new(pgctldata.BackupResponse)
+	err := c.cc.Invoke(ctx, PgCtld_Backup_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) ListBackups(ctx context.Context, in *pgctldata.ListBackupsRequest, opts ...grpc.CallOption) (*pgctldata.ListBackupsResponse, error) {
+	out := new(pgctldata.ListBackupsResponse)
+	err := c.cc.Invoke(ctx, PgCtld_ListBackups_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pgCtldClient) Restore(ctx context.Context, in *pgctldata.RestoreRequest, opts ...grpc.CallOption) (*pgctldata.RestoreResponse, error) {
+	out := new(pgctldata.RestoreResponse)
+	err := c.cc.Invoke(ctx, PgCtld_Restore_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PgCtldServer is the server API for PgCtld service.
+// All implementations must embed UnimplementedPgCtldServer
+// for forward compatibility
+type PgCtldServer interface {
+	// InitDataDir initializes the data directory with initdb. It fails if
+	// the data directory is already initialized.
+	InitDataDir(context.Context, *pgctldata.InitDataDirRequest) (*pgctldata.InitDataDirResponse, error)
+	// Start starts the server, and waits until it accepts connections. It
+	// stops the server if it doesn't in time, and does nothing if the server
+	// is already running.
+	Start(context.Context, *pgctldata.StartRequest) (*pgctldata.StartResponse, error)
+	// Stop stops the server in the requested mode, and waits until it
+	// exits. It does nothing if the server is not running.
+	Stop(context.Context, *pgctldata.StopRequest) (*pgctldata.StopResponse, error)
+	// Restart stops the server if it is running, then starts it.
+	Restart(context.Context, *pgctldata.RestartRequest) (*pgctldata.RestartResponse, error)
+	// ReloadConfig writes the configuration files again, with the current
+	// overrides, and makes the running server reload them.
+	ReloadConfig(context.Context, *pgctldata.ReloadConfigRequest) (*pgctldata.ReloadConfigResponse, error)
+	// SetConfig sets postgresql.conf settings, and writes the configuration
+	// files. The settings are validated, and nothing is changed if any is
+	// invalid. It reports whether the running server must reload its
+	// configuration or restart to apply them.
+	SetConfig(context.Context, *pgctldata.SetConfigRequest) (*pgctldata.SetConfigResponse, error)
+	// Status returns the status of the server.
+	Status(context.Context, *pgctldata.StatusRequest) (*pgctldata.StatusResponse, error)
+	// SetPrimaryConnInfo sets the primary_conninfo and primary_slot_name
+	// settings, and makes the running server reload them. The server
+	// becomes a standby of the primary, at its next start if it runs as a
+	// primary.
+	SetPrimaryConnInfo(context.Context, *pgctldata.SetPrimaryConnInfoRequest) (*pgctldata.SetPrimaryConnInfoResponse, error)
+	// Promote promotes the standby to primary, and waits until it accepts
+	// writes. It does nothing if the server is already a primary.
+	Promote(context.Context, *pgctldata.PromoteRequest) (*pgctldata.PromoteResponse, error)
+	// CreateReplicationSlot creates a physical replication slot.
+	CreateReplicationSlot(context.Context, *pgctldata.CreateReplicationSlotRequest) (*pgctldata.CreateReplicationSlotResponse, error)
+	// DropReplicationSlot drops a replication slot.
+	DropReplicationSlot(context.Context, *pgctldata.DropReplicationSlotRequest) (*pgctldata.DropReplicationSlotResponse, error)
+	// PauseReplay pauses the replay of the WAL on a standby. The standby
+	// still receives the WAL.
+	PauseReplay(context.Context, *pgctldata.PauseReplayRequest) (*pgctldata.PauseReplayResponse, error)
+	// ResumeReplay resumes the replay of the WAL on a standby.
+	ResumeReplay(context.Context, *pgctldata.ResumeReplayRequest) (*pgctldata.ResumeReplayResponse, error)
+	// ReplicationStatus returns the replication status of the server.
+	ReplicationStatus(context.Context, *pgctldata.ReplicationStatusRequest) (*pgctldata.ReplicationStatusResponse, error)
+	// ExecuteFetch runs a SQL statement on the server as the superuser, and
+	// returns its result. It is meant for the administrative queries of the
+	// orchestration and of the tools, not for the queries of the users.
+	ExecuteFetch(context.Context, *pgctldata.ExecuteFetchRequest) (*pgctldata.ExecuteFetchResponse, error)
+	// Backup takes a backup of the running server to the backup storage,
+	// with the WAL archived since the previous backup.
+	Backup(context.Context, *pgctldata.BackupRequest) (*pgctldata.BackupResponse, error)
+	// ListBackups returns the complete backups of the backup storage.
+	ListBackups(context.Context, *pgctldata.ListBackupsRequest) (*pgctldata.ListBackupsResponse, error)
+	// Restore restores a backup in the data directory, which must not be
+	// initialized, and starts the server to recover to the target.
+	Restore(context.Context, *pgctldata.RestoreRequest) (*pgctldata.RestoreResponse, error)
+	mustEmbedUnimplementedPgCtldServer()
+}
+
+// UnimplementedPgCtldServer must be embedded to have forward compatible implementations.
+type UnimplementedPgCtldServer struct {
+}
+
+func (UnimplementedPgCtldServer) InitDataDir(context.Context, *pgctldata.InitDataDirRequest) (*pgctldata.InitDataDirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InitDataDir not implemented")
+}
+func (UnimplementedPgCtldServer) Start(context.Context, *pgctldata.StartRequest) (*pgctldata.StartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Start not implemented")
+}
+func (UnimplementedPgCtldServer) Stop(context.Context, *pgctldata.StopRequest) (*pgctldata.StopResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stop not implemented")
+}
+func (UnimplementedPgCtldServer) Restart(context.Context, *pgctldata.RestartRequest) (*pgctldata.RestartResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restart not implemented")
+}
+func (UnimplementedPgCtldServer) ReloadConfig(context.Context, *pgctldata.ReloadConfigRequest) (*pgctldata.ReloadConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedPgCtldServer) SetConfig(context.Context, *pgctldata.SetConfigRequest) (*pgctldata.SetConfigResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConfig not implemented")
+}
+func (UnimplementedPgCtldServer) Status(context.Context, *pgctldata.StatusRequest) (*pgctldata.StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Status not implemented")
+}
+func (UnimplementedPgCtldServer) SetPrimaryConnInfo(context.Context, *pgctldata.SetPrimaryConnInfoRequest) (*pgctldata.SetPrimaryConnInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetPrimaryConnInfo not implemented")
+}
+func (UnimplementedPgCtldServer) Promote(context.Context, *pgctldata.PromoteRequest) (*pgctldata.PromoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Promote not implemented")
+}
+func (UnimplementedPgCtldServer) CreateReplicationSlot(context.Context, *pgctldata.CreateReplicationSlotRequest) (*pgctldata.CreateReplicationSlotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateReplicationSlot not implemented")
+}
+func (UnimplementedPgCtldServer) DropReplicationSlot(context.Context, *pgctldata.DropReplicationSlotRequest) (*pgctldata.DropReplicationSlotResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DropReplicationSlot not implemented")
+}
+func (UnimplementedPgCtldServer) PauseReplay(context.Context, *pgctldata.PauseReplayRequest) (*pgctldata.PauseReplayResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseReplay not implemented")
+}
+func (UnimplementedPgCtldServer) ResumeReplay(context.Context, *pgctldata.ResumeReplayRequest) (*pgctldata.ResumeReplayResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeReplay not implemented")
+}
+func (UnimplementedPgCtldServer) ReplicationStatus(context.Context, *pgctldata.ReplicationStatusRequest) (*pgctldata.ReplicationStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReplicationStatus not implemented")
+}
+func (UnimplementedPgCtldServer) ExecuteFetch(context.Context, *pgctldata.ExecuteFetchRequest) (*pgctldata.ExecuteFetchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteFetch not implemented")
+}
+func (UnimplementedPgCtldServer) Backup(context.Context, *pgctldata.BackupRequest) (*pgctldata.BackupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedPgCtldServer) ListBackups(context.Context, *pgctldata.ListBackupsRequest) (*pgctldata.ListBackupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBackups not implemented")
+}
+func (UnimplementedPgCtldServer) Restore(context.Context, *pgctldata.RestoreRequest) (*pgctldata.RestoreResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (UnimplementedPgCtldServer) mustEmbedUnimplementedPgCtldServer() {}
+
+// UnsafePgCtldServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PgCtldServer will
+// result in compilation errors.
+type UnsafePgCtldServer interface {
+	mustEmbedUnimplementedPgCtldServer()
+}
+
+func RegisterPgCtldServer(s grpc.ServiceRegistrar, srv PgCtldServer) {
+	s.RegisterService(&PgCtld_ServiceDesc, srv)
+}
+
+func _PgCtld_InitDataDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.InitDataDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).InitDataDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_InitDataDir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).InitDataDir(ctx, req.(*pgctldata.InitDataDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_Start_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.StartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).Start(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_Start_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).Start(ctx, req.(*pgctldata.StartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_Stop_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.StopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).Stop(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_Stop_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).Stop(ctx, req.(*pgctldata.StopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_Restart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.RestartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).Restart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_Restart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).Restart(ctx, req.(*pgctldata.RestartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.ReloadConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).ReloadConfig(ctx, req.(*pgctldata.ReloadConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_SetConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.SetConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).SetConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_SetConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).SetConfig(ctx, req.(*pgctldata.SetConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_Status_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).Status(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_Status_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).Status(ctx, req.(*pgctldata.StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_SetPrimaryConnInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.SetPrimaryConnInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).SetPrimaryConnInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_SetPrimaryConnInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).SetPrimaryConnInfo(ctx, req.(*pgctldata.SetPrimaryConnInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_Promote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.PromoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).Promote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_Promote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).Promote(ctx, req.(*pgctldata.PromoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_CreateReplicationSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.CreateReplicationSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).CreateReplicationSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_CreateReplicationSlot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).CreateReplicationSlot(ctx, req.(*pgctldata.CreateReplicationSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_DropReplicationSlot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.DropReplicationSlotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).DropReplicationSlot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_DropReplicationSlot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).DropReplicationSlot(ctx, req.(*pgctldata.DropReplicationSlotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_PauseReplay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.PauseReplayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).PauseReplay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_PauseReplay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).PauseReplay(ctx, req.(*pgctldata.PauseReplayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_ResumeReplay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.ResumeReplayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).ResumeReplay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_ResumeReplay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).ResumeReplay(ctx, req.(*pgctldata.ResumeReplayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_ReplicationStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.ReplicationStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).ReplicationStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_ReplicationStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).ReplicationStatus(ctx, req.(*pgctldata.ReplicationStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_ExecuteFetch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.ExecuteFetchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).ExecuteFetch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_ExecuteFetch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).ExecuteFetch(ctx, req.(*pgctldata.ExecuteFetchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_Backup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.BackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).Backup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_Backup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).Backup(ctx, req.(*pgctldata.BackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_ListBackups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.ListBackupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).ListBackups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_ListBackups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).ListBackups(ctx, req.(*pgctldata.ListBackupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PgCtld_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(pgctldata.RestoreRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PgCtldServer).Restore(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PgCtld_Restore_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PgCtldServer).Restore(ctx, req.(*pgctldata.RestoreRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PgCtld_ServiceDesc is the grpc.ServiceDesc for PgCtld service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PgCtld_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "pgctldservice.PgCtld",
+	HandlerType: (*PgCtldServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "InitDataDir",
+			Handler:    _PgCtld_InitDataDir_Handler,
+		},
+		{
+			MethodName: "Start",
+			Handler:    _PgCtld_Start_Handler,
+		},
+		{
+			MethodName: "Stop",
+			Handler:    _PgCtld_Stop_Handler,
+		},
+		{
+			MethodName: "Restart",
+			Handler:    _PgCtld_Restart_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _PgCtld_ReloadConfig_Handler,
+		},
+		{
+			MethodName: "SetConfig",
+			Handler:    _PgCtld_SetConfig_Handler,
+		},
+		{
+			MethodName: "Status",
+			Handler:    _PgCtld_Status_Handler,
+		},
+		{
+			MethodName: "SetPrimaryConnInfo",
+			Handler:    _PgCtld_SetPrimaryConnInfo_Handler,
+		},
+		{
+			MethodName: "Promote",
+			Handler:    _PgCtld_Promote_Handler,
+		},
+		{
+			MethodName: "CreateReplicationSlot",
+			Handler:    _PgCtld_CreateReplicationSlot_Handler,
+		},
+		{
+			MethodName: "DropReplicationSlot",
+			Handler:    _PgCtld_DropReplicationSlot_Handler,
+		},
+		{
+			MethodName: "PauseReplay",
+			Handler:    _PgCtld_PauseReplay_Handler,
+		},
+		{
+			MethodName: "ResumeReplay",
+			Handler:    _PgCtld_ResumeReplay_Handler,
+		},
+		{
+			MethodName: "ReplicationStatus",
+			Handler:    _PgCtld_ReplicationStatus_Handler,
+		},
+		{
+			MethodName: "ExecuteFetch",
+			Handler:    _PgCtld_ExecuteFetch_Handler,
+		},
+		{
+			MethodName: "Backup",
+			Handler:    _PgCtld_Backup_Handler,
+		},
+		{
+			MethodName: "ListBackups",
+			Handler:    _PgCtld_ListBackups_Handler,
+		},
+		{
+			MethodName: "Restore",
+			Handler:    _PgCtld_Restore_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pgctldservice.proto",
+}
@@ -0,0 +1,454 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file contains the messages describing queries and their results.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        v4.25.1
+// source: query.proto
+
+package query
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Type is the type of a column, from its PostgreSQL type.
+type Type int32
+
+const (
+	// UNKNOWN is the type of the columns whose PostgreSQL type has no Type,
+	// like the arrays and the user-defined types. Use Field.type_oid.
+	Type_UNKNOWN Type = 0
+	Type_BOOL    Type = 1
+	Type_INT2    Type = 2
+	Type_INT4    Type = 3
+	Type_INT8    Type = 4
+	Type_FLOAT4  Type = 5
+	Type_FLOAT8  Type = 6
+	Type_NUMERIC Type = 7
+	Type_OID     Type = 8
+	// CHAR is the single-byte "char" type, BPCHAR is char(n).
+	Type_CHAR        Type = 9
+	Type_BPCHAR      Type = 10
+	Type_VARCHAR     Type = 11
+	Type_TEXT        Type = 12
+	Type_NAME        Type = 13
+	Type_BYTEA       Type = 14
+	Type_DATE        Type = 15
+	Type_TIME        Type = 16
+	Type_TIMETZ      Type = 17
+	Type_TIMESTAMP   Type = 18
+	Type_TIMESTAMPTZ Type = 19
+	Type_INTERVAL    Type = 20
+	Type_UUID        Type = 21
+	Type_JSON        Type = 22
+	Type_JSONB       Type = 23
+	Type_XML         Type = 24
+	Type_INET        Type = 25
+	Type_CIDR        Type = 26
+	Type_PG_LSN      Type = 27
+)
+
+// Enum value maps for Type.
+var (
+	Type_name = map[int32]string{
+		0:  "UNKNOWN",
+		1:  "BOOL",
+		2:  "INT2",
+		3:  "INT4",
+		4:  "INT8",
+		5:  "FLOAT4",
+		6:  "FLOAT8",
+		7:  "NUMERIC",
+		8:  "OID",
+		9:  "CHAR",
+		10: "BPCHAR",
+		11: "VARCHAR",
+		12: "TEXT",
+		13: "NAME",
+		14: "BYTEA",
+		15: "DATE",
+		16: "TIME",
+		17: "TIMETZ",
+		18: "TIMESTAMP",
+		19: "TIMESTAMPTZ",
+		20: "INTERVAL",
+		21: "UUID",
+		22: "JSON",
+		23: "JSONB",
+		24: "XML",
+		25: "INET",
+		26: "CIDR",
+		27: "PG_LSN",
+	}
+	Type_value = map[string]int32{
+		"UNKNOWN":     0,
+		"BOOL":        1,
+		"INT2":        2,
+		"INT4":        3,
+		"INT8":        4,
+		"FLOAT4":      5,
+		"FLOAT8":      6,
+		"NUMERIC":     7,
+		"OID":         8,
+		"CHAR":        9,
+		"BPCHAR":      10,
+		"VARCHAR":     11,
+		"TEXT":        12,
+		"NAME":        13,
+		"BYTEA":       14,
+		"DATE":        15,
+		"TIME":        16,
+		"TIMETZ":      17,
+		"TIMESTAMP":   18,
+		"TIMESTAMPTZ": 19,
+		"INTERVAL":    20,
+		"UUID":        21,
+		"JSON":        22,
+		"JSONB":       23,
+		"XML":         24,
+		"INET":        25,
+		"CIDR":        26,
+		"PG_LSN":      27,
+	}
+)
+
+func (x Type) Enum() *Type {
+	p := new(Type)
+	*p = x
+	return p
+}
+
+func (x Type) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Type) Descriptor() protoreflect.EnumDescriptor {
+	return file_query_proto_enumTypes[0].Descriptor()
+}
+
+func (Type) Type() protoreflect.EnumType {
+	return &file_query_proto_enumTypes[0]
+}
+
+func (x Type) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Type.Descriptor instead.
+func (Type) EnumDescriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{0}
+}
+
+// Field describes a column of a QueryResult.
+type Field struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Type  Type                   `protobuf:"varint,2,opt,name=type,proto3,enum=query.Type" json:"type,omitempty"`
+	// type_oid is the OID of the PostgreSQL type of the column.
+	TypeOid       uint32 `protobuf:"varint,3,opt,name=type_oid,json=typeOid,proto3" json:"type_oid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Field) Reset() {
+	*x = Field{}
+	mi := &file_query_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Field) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Field) ProtoMessage() {}
+
+func (x *Field) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Field.ProtoReflect.Descriptor instead.
+func (*Field) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Field) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Field) GetType() Type {
+	if x != nil {
+		return x.Type
+	}
+	return Type_UNKNOWN
+}
+
+func (x *Field) GetTypeOid() uint32 {
+	if x != nil {
+		return x.TypeOid
+	}
+	return 0
+}
+
+// Row is a row of a QueryResult. The values are in the text format of
+// their PostgreSQL type, concatenated in values. lengths are the lengths
+// of the values, -1 for NULL.
+type Row struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lengths       []int64                `protobuf:"zigzag64,1,rep,packed,name=lengths,proto3" json:"lengths,omitempty"`
+	Values        []byte                 `protobuf:"bytes,2,opt,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	mi := &file_query_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Row) GetLengths() []int64 {
+	if x != nil {
+		return x.Lengths
+	}
+	return nil
+}
+
+func (x *Row) GetValues() []byte {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// QueryResult is the result of a query.
+type QueryResult struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Fields []*Field               `protobuf:"bytes,1,rep,name=fields,proto3" json:"fields,omitempty"`
+	// rows_affected is the number of rows returned or changed by the query.
+	RowsAffected  uint64 `protobuf:"varint,2,opt,name=rows_affected,json=rowsAffected,proto3" json:"rows_affected,omitempty"`
+	Rows          []*Row `protobuf:"bytes,3,rep,name=rows,proto3" json:"rows,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryResult) Reset() {
+	*x = QueryResult{}
+	mi := &file_query_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryResult) ProtoMessage() {}
+
+func (x *QueryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryResult.ProtoReflect.Descriptor instead.
+func (*QueryResult) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *QueryResult) GetFields() []*Field {
+	if x != nil {
+		return x.Fields
+	}
+	return nil
+}
+
+func (x *QueryResult) GetRowsAffected() uint64 {
+	if x != nil {
+		return x.RowsAffected
+	}
+	return 0
+}
+
+func (x *QueryResult) GetRows() []*Row {
+	if x != nil {
+		return x.Rows
+	}
+	return nil
+}
+
+var File_query_proto protoreflect.FileDescriptor
+
+const file_query_proto_rawDesc = "" +
+	"\n" +
+	"\vquery.proto\x12\x05query\"W\n" +
+	"\x05Field\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1f\n" +
+	"\x04type\x18\x02 \x01(\x0e2\v.query.TypeR\x04type\x12\x19\n" +
+	"\btype_oid\x18\x03 \x01(\rR\atypeOid\"7\n" +
+	"\x03Row\x12\x18\n" +
+	"\alengths\x18\x01 \x03(\x12R\alengths\x12\x16\n" +
+	"\x06values\x18\x02 \x01(\fR\x06values\"x\n" +
+	"\vQueryResult\x12$\n" +
+	"\x06fields\x18\x01 \x03(\v2\f.query.FieldR\x06fields\x12#\n" +
+	"\rrows_affected\x18\x02 \x01(\x04R\frowsAffected\x12\x1e\n" +
+	"\x04rows\x18\x03 \x03(\v2\n" +
+	".query.RowR\x04rows*\xc1\x02\n" +
+	"\x04Type\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\b\n" +
+	"\x04BOOL\x10\x01\x12\b\n" +
+	"\x04INT2\x10\x02\x12\b\n" +
+	"\x04INT4\x10\x03\x12\b\n" +
+	"\x04INT8\x10\x04\x12\n" +
+	"\n" +
+	"\x06FLOAT4\x10\x05\x12\n" +
+	"\n" +
+	"\x06FLOAT8\x10\x06\x12\v\n" +
+	"\aNUMERIC\x10\a\x12\a\n" +
+	"\x03OID\x10\b\x12\b\n" +
+	"\x04CHAR\x10\t\x12\n" +
+	"\n" +
+	"\x06BPCHAR\x10\n" +
+	"\x12\v\n" +
+	"\aVARCHAR\x10\v\x12\b\n" +
+	"\x04TEXT\x10\f\x12\b\n" +
+	"\x04NAME\x10\r\x12\t\n" +
+	"\x05BYTEA\x10\x0e\x12\b\n" +
+	"\x04DATE\x10\x0f\x12\b\n" +
+	"\x04TIME\x10\x10\x12\n" +
+	"\n" +
+	"\x06TIMETZ\x10\x11\x12\r\n" +
+	"\tTIMESTAMP\x10\x12\x12\x0f\n" +
+	"\vTIMESTAMPTZ\x10\x13\x12\f\n" +
+	"\bINTERVAL\x10\x14\x12\b\n" +
+	"\x04UUID\x10\x15\x12\b\n" +
+	"\x04JSON\x10\x16\x12\t\n" +
+	"\x05JSONB\x10\x17\x12\a\n" +
+	"\x03XML\x10\x18\x12\b\n" +
+	"\x04INET\x10\x19\x12\b\n" +
+	"\x04CIDR\x10\x1a\x12\n" +
+	"\n" +
+	"\x06PG_LSN\x10\x1bB,Z*github.com/multigres/multigres/go/pb/queryb\x06proto3"
+
+var (
+	file_query_proto_rawDescOnce sync.Once
+	file_query_proto_rawDescData []byte
+)
+
+func file_query_proto_rawDescGZIP() []byte {
+	file_query_proto_rawDescOnce.Do(func() {
+		file_query_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_query_proto_rawDesc), len(file_query_proto_rawDesc)))
+	})
+	return file_query_proto_rawDescData
+}
+
+var file_query_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_query_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_query_proto_goTypes = []any{
+	(Type)(0),           // 0: query.Type
+	(*Field)(nil),       // 1: query.Field
+	(*Row)(nil),         // 2: query.Row
+	(*QueryResult)(nil), // 3: query.QueryResult
+}
+var file_query_proto_depIdxs = []int32{
+	0, // 0: query.Field.type:type_name -> query.Type
+	1, // 1: query.QueryResult.fields:type_name -> query.Field
+	2, // 2: query.QueryResult.rows:type_name -> query.Row
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_query_proto_init() }
+func file_query_proto_init() {
+	if File_query_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_query_proto_rawDesc), len(file_query_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_query_proto_goTypes,
+		DependencyIndexes: file_query_proto_depIdxs,
+		EnumInfos:         file_query_proto_enumTypes,
+		MessageInfos:      file_query_proto_msgTypes,
+	}.Build()
+	File_query_proto = out.File
+	file_query_proto_goTypes = nil
+	file_query_proto_depIdxs = nil
+}
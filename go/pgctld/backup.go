@@ -0,0 +1,764 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	"github.com/multigres/multigres/go/pgctld/backupstorage"
+)
+
+// This file implements the backups and the restores.
+//
+// A backup copies the data directory with pg_basebackup, then writes its
+// manifest. The WAL archived after it starts is shipped to it, see wal.go.
+// The files of pgctld are in the state directory, out of the backups.
+//
+// A restore extracts the base backup of a backup in an empty data
+// directory, with the WAL shipped to the backup and to the later ones in
+// walRestoreDir, where the restore_command finds them. The server starts
+// in recovery, and replays the WAL up to the recovery target, or all of
+// it.
+
+const (
+	// walArchiveDir holds the WAL segments archived by the server, in the
+	// state directory.
+	walArchiveDir = "wal_archive"
+
+	// walRestoreDir holds the WAL segments replayed by a restored server.
+	walRestoreDir = "wal_restore"
+
+	// recoverySignalFile makes the server start in targeted recovery.
+	recoverySignalFile = "recovery.signal"
+
+	// manifestFile is the manifest of a backup, written last.
+	manifestFile = "MANIFEST"
+
+	// backupLabelFile is written in the base backup by pg_basebackup, with
+	// the WAL location the replay starts from.
+	backupLabelFile = "backup_label"
+
+	// baseBackupFile is the tar of the data directory, from pg_basebackup.
+	// The tablespaces have their own tar, named after their OID.
+	baseBackupFile = "base.tar"
+
+	// tablespaceDir holds the links to the tablespaces, named after their
+	// OID, in the data directory.
+	tablespaceDir = "pg_tblspc"
+
+	// walPrefix is the prefix of the WAL segments of a backup.
+	walPrefix = "wal/"
+
+	// backupNameFormat is the format of the names of the backups, from
+	// their start time. The names sort in the order of the backups.
+	backupNameFormat = "2006-01-02.150405.000"
+)
+
+// parseLSN returns the value of a WAL location, like 0/3000148.
+func parseLSN(lsn string) (uint64, error) {
+	high, low, found := strings.Cut(lsn, "/")
+	if found {
+		h, err := strconv.ParseUint(high, 16, 32)
+		if err == nil {
+			l, err := strconv.ParseUint(low, 16, 32)
+			if err == nil {
+				return h<<32 | l, nil
+			}
+		}
+	}
+	return 0, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "invalid WAL location %q", lsn)
+}
+
+// currentLSN returns the current WAL location of the server: the write
+// location of a primary, the replay location of a standby.
+func (m *Manager) currentLSN(ctx context.Context) (string, error) {
+	row, err := m.queryRow(ctx, "SELECT CASE WHEN pg_is_in_recovery() THEN pg_last_wal_replay_lsn() ELSE pg_current_wal_lsn() END")
+	if err != nil {
+		return "", err
+	}
+	return string(row[0]), nil
+}
+
+// openStorage opens the backup storage at location, and validates dir.
+func openStorage(location, dir string) (backupstorage.Storage, error) {
+	if err := backupstorage.ValidatePath(dir); err != nil {
+		return nil, err
+	}
+	return backupstorage.Open(location)
+}
+
+// Backup takes a backup of the running server to the directory dir of
+// the backup storage at location, and returns its manifest.
+func (m *Manager) Backup(ctx context.Context, location, dir string) (manifest *pgctldatapb.BackupManifest, err error) {
+	if !m.backupMu.TryLock() {
+		return nil, mterrors.New(mtrpcpb.Code_FAILED_PRECONDITION, "a backup or a restore is already running")
+	}
+	defer m.backupMu.Unlock()
+	m.mu.Lock()
+	status, err := m.status()
+	if err == nil {
+		err = m.checkReady()
+	}
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	storage, err := openStorage(location, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = storage.Close() }()
+
+	startTime := time.Now().UTC()
+	manifest = &pgctldatapb.BackupManifest{
+		Name:      startTime.Format(backupNameFormat),
+		Version:   status.Version,
+		StartTime: timestamppb.New(startTime),
+	}
+	names, err := storage.ListBackups(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(names, manifest.Name) {
+		return nil, mterrors.Errorf(mtrpcpb.Code_ALREADY_EXISTS, "backup %v already exists", manifest.Name)
+	}
+	slog.Info("Starting backup", "location", location, "dir", dir, "backup", manifest.Name)
+	defer func() {
+		if err != nil {
+			slog.Error("Backup failed", "backup", manifest.Name, "error", err)
+			if removeErr := storage.RemoveBackup(context.Background(), dir, manifest.Name); removeErr != nil {
+				slog.Error("Failed to remove the incomplete backup", "backup", manifest.Name, "error", removeErr)
+			}
+		}
+	}()
+
+	// Ship the WAL archived before the backup to the previous one. The WAL
+	// archived from now on is kept, to be shipped to this backup.
+	m.walMu.Lock()
+	defer m.walMu.Unlock()
+	if err := m.shipWALLocked(ctx); err != nil {
+		return nil, err
+	}
+
+	// Copy the data directory, with the WAL needed to make it consistent.
+	tmpDir, err := os.MkdirTemp("", "pgctld-backup")
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to create the backup directory")
+	}
+	defer os.RemoveAll(tmpDir)
+	cmd := exec.CommandContext(ctx, m.binary("pg_basebackup"),
+		"-D", tmpDir, "--format=tar", "--wal-method=fetch", "--checkpoint=fast", "--no-password",
+		"-d", connString(m.config))
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, mterrors.Wrapf(err, "pg_basebackup failed: %s", strings.TrimSpace(string(output)))
+	}
+	if manifest.StartLsn, err = backupStartLSN(filepath.Join(tmpDir, baseBackupFile)); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to list the base backup")
+	}
+	for _, entry := range entries {
+		file, err := uploadFile(ctx, storage, dir, manifest.Name, entry.Name(), filepath.Join(tmpDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	if manifest.EndLsn, err = m.currentLSN(ctx); err != nil {
+		return nil, err
+	}
+	manifest.EndTime = timestamppb.Now()
+	data, err := protojson.Marshal(manifest)
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to marshal the backup manifest")
+	}
+	w, err := storage.AddFile(ctx, dir, manifest.Name, manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return nil, mterrors.Wrap(err, "failed to write the backup manifest")
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	// The WAL archived since the start of the backup is shipped to it.
+	if err := m.writeWALTarget(&walTarget{Location: location, Dir: dir, Backup: manifest.Name}); err != nil {
+		return nil, err
+	}
+	slog.Info("Backup complete", "backup", manifest.Name, "files", len(manifest.Files), "end_lsn", manifest.EndLsn)
+	return manifest, nil
+}
+
+// backupStartLSN returns the WAL location the replay of the base backup
+// at p starts from, in its backup_label file.
+func backupStartLSN(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", mterrors.Wrap(err, "failed to open the base backup")
+	}
+	defer f.Close()
+	reader := tar.NewReader(f)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return "", mterrors.Errorf(mtrpcpb.Code_INTERNAL, "the base backup has no %v", backupLabelFile)
+		}
+		if err != nil {
+			return "", mterrors.Wrap(err, "failed to read the base backup")
+		}
+		if path.Clean(header.Name) != backupLabelFile {
+			continue
+		}
+		label, err := io.ReadAll(reader)
+		if err != nil {
+			return "", mterrors.Wrapf(err, "failed to read %v", backupLabelFile)
+		}
+		// START WAL LOCATION: 0/2000028 (file 000000010000000000000002)
+		for _, line := range strings.Split(string(label), "\n") {
+			if value, ok := strings.CutPrefix(line, "START WAL LOCATION: "); ok {
+				lsn, _, _ := strings.Cut(value, " ")
+				if _, err := parseLSN(lsn); err != nil {
+					return "", mterrors.Errorf(mtrpcpb.Code_INTERNAL, "invalid %v: %v", backupLabelFile, err)
+				}
+				return lsn, nil
+			}
+		}
+		return "", mterrors.Errorf(mtrpcpb.Code_INTERNAL, "%v has no start WAL location", backupLabelFile)
+	}
+}
+
+// archivedSegments returns the names of the WAL segments archived in dir,
+// sorted, without the partially copied ones.
+func archivedSegments(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to list the archived WAL")
+	}
+	var segments []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasSuffix(entry.Name(), ".tmp") {
+			segments = append(segments, entry.Name())
+		}
+	}
+	return segments, nil
+}
+
+// uploadFile copies the local file at p to the file name of the backup
+// backup in dir, and returns its description.
+func uploadFile(ctx context.Context, storage backupstorage.Storage, dir, backup, name, p string) (*pgctldatapb.BackupFile, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, mterrors.Wrapf(err, "failed to open %v", p)
+	}
+	defer f.Close()
+	w, err := storage.AddFile(ctx, dir, backup, name)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(w, hash), f)
+	if err != nil {
+		_ = w.Close()
+		return nil, mterrors.Wrapf(err, "failed to copy %v to the backup", name)
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.BackupFile{
+		Name:   name,
+		Size:   size,
+		Sha256: hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// ListBackups returns the manifests of the complete backups in the
+// directory dir of the backup storage at location, oldest first.
+func (m *Manager) ListBackups(ctx context.Context, location, dir string) ([]*pgctldatapb.BackupManifest, error) {
+	storage, err := openStorage(location, dir)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = storage.Close() }()
+	return listManifests(ctx, storage, dir)
+}
+
+// listManifests returns the manifests of the complete backups in dir.
+func listManifests(ctx context.Context, storage backupstorage.Storage, dir string) ([]*pgctldatapb.BackupManifest, error) {
+	names, err := storage.ListBackups(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+	var manifests []*pgctldatapb.BackupManifest
+	for _, name := range names {
+		r, err := storage.ReadFile(ctx, dir, name, manifestFile)
+		if mterrors.Code(err) == mtrpcpb.Code_NOT_FOUND {
+			// An incomplete backup.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(r)
+		_ = r.Close()
+		if err != nil {
+			return nil, mterrors.Wrapf(err, "failed to read the manifest of backup %v", name)
+		}
+		manifest := &pgctldatapb.BackupManifest{}
+		if err := protojson.Unmarshal(data, manifest); err != nil {
+			return nil, mterrors.Wrapf(err, "failed to parse the manifest of backup %v", name)
+		}
+		manifests = append(manifests, manifest)
+	}
+	return manifests, nil
+}
+
+// RecoveryTarget is the point in time a restored server recovers to: a
+// WAL location or a time, at most one. The zero RecoveryTarget recovers
+// all the archived WAL.
+type RecoveryTarget struct {
+	LSN  string
+	Time time.Time
+}
+
+// before returns true if the target is before the end of the backup of
+// manifest, which can't be restored to the target.
+func (t RecoveryTarget) before(manifest *pgctldatapb.BackupManifest) (bool, error) {
+	switch {
+	case t.LSN != "":
+		target, err := parseLSN(t.LSN)
+		if err != nil {
+			return false, err
+		}
+		end, err := parseLSN(manifest.EndLsn)
+		if err != nil {
+			return false, err
+		}
+		return target < end, nil
+	case !t.Time.IsZero():
+		return t.Time.Before(manifest.EndTime.AsTime()), nil
+	}
+	return false, nil
+}
+
+// after returns true if the target is after end, the WAL location and the
+// time at the end of the WAL.
+func (t RecoveryTarget) after(end uint64, endTime time.Time) (bool, error) {
+	switch {
+	case t.LSN != "":
+		target, err := parseLSN(t.LSN)
+		if err != nil {
+			return false, err
+		}
+		return target > end, nil
+	case !t.Time.IsZero():
+		return t.Time.After(endTime), nil
+	}
+	return false, nil
+}
+
+// walEnd returns the WAL location and the time at the end of the WAL
+// that can be replayed after the first backup of manifests: the end of
+// the last backup, or of the WAL shipped to the backups, in indexes.
+func walEnd(manifests []*pgctldatapb.BackupManifest, indexes []*walIndex) (uint64, time.Time, error) {
+	var end uint64
+	var endTime time.Time
+	advance := func(lsn string, t time.Time) error {
+		value, err := parseLSN(lsn)
+		if err != nil {
+			return err
+		}
+		end = max(end, value)
+		if t.After(endTime) {
+			endTime = t
+		}
+		return nil
+	}
+	for _, manifest := range manifests {
+		if err := advance(manifest.EndLsn, manifest.EndTime.AsTime()); err != nil {
+			return 0, time.Time{}, err
+		}
+	}
+	for _, index := range indexes {
+		for _, segment := range index.Segments {
+			if segment.EndLSN == "" {
+				continue
+			}
+			if err := advance(segment.EndLSN, segment.EndTime); err != nil {
+				return 0, time.Time{}, err
+			}
+		}
+	}
+	return end, endTime, nil
+}
+
+// Restore restores the backup name, or the latest backup before target
+// if name is empty, from the directory dir of the backup storage at
+// location. The data directory must be empty, and can't be initialized
+// or started meanwhile. The server starts in recovery, and replays the
+// shipped WAL up to target, then it is promoted. Restore returns the
+// manifest of the backup, and the status of the server. The replay is
+// only bounded by ctx, not by the start timeout: if ctx is done first, the
+// server keeps recovering.
+func (m *Manager) Restore(ctx context.Context, location, dir, name string, target RecoveryTarget) (*pgctldatapb.BackupManifest, *pgctldatapb.PostgresStatus, error) {
+	if target.LSN != "" && !target.Time.IsZero() {
+		return nil, nil, mterrors.New(mtrpcpb.Code_INVALID_ARGUMENT, "the recovery target is either a WAL location or a time")
+	}
+	if target.LSN != "" {
+		if _, err := parseLSN(target.LSN); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !m.backupMu.TryLock() {
+		return nil, nil, mterrors.New(mtrpcpb.Code_FAILED_PRECONDITION, "a backup or a restore is already running")
+	}
+	defer m.backupMu.Unlock()
+	if err := m.beginRestore(); err != nil {
+		return nil, nil, err
+	}
+	defer m.endRestore()
+	storage, err := openStorage(location, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = storage.Close() }()
+
+	manifests, err := listManifests(ctx, storage, dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	index := -1
+	for i, manifest := range manifests {
+		before, err := target.before(manifest)
+		if err != nil {
+			return nil, nil, err
+		}
+		if name == "" && !before {
+			index = i
+		}
+		if name != "" && manifest.Name == name {
+			if before {
+				return nil, nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "the recovery target is before the end of backup %v", name)
+			}
+			index = i
+		}
+	}
+	switch {
+	case index < 0 && name != "":
+		return nil, nil, mterrors.Errorf(mtrpcpb.Code_NOT_FOUND, "backup %v doesn't exist", name)
+	case index < 0:
+		return nil, nil, mterrors.Errorf(mtrpcpb.Code_NOT_FOUND, "no backup in %v before the recovery target", dir)
+	}
+	manifest := manifests[index]
+
+	// The WAL shipped since the backup must reach the target.
+	indexes := make([]*walIndex, 0, len(manifests)-index)
+	for _, later := range manifests[index:] {
+		walIndex, err := readWALIndex(ctx, storage, dir, later.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		indexes = append(indexes, walIndex)
+	}
+	endLSN, endTime, err := walEnd(manifests[index:], indexes)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := target.after(endLSN, endTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	if after {
+		return nil, nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "the recovery target is after the end of the shipped WAL, %v at %v", formatLSN(endLSN), endTime.Format(time.RFC3339))
+	}
+
+	exited, err := m.restore(ctx, storage, dir, manifest, indexes, target)
+	var status *pgctldatapb.PostgresStatus
+	if err == nil {
+		status, err = m.waitReady(ctx, exited)
+		if err != nil && ctx.Err() != nil {
+			return nil, nil, mterrors.Wrapf(err, "the server restored from backup %v is still recovering", manifest.Name)
+		}
+	}
+	if err != nil {
+		// Leave the data directory uninitialized, to restore again.
+		m.mu.Lock()
+		if _, stopErr := m.stop(context.WithoutCancel(ctx), pgctldatapb.StopMode_IMMEDIATE); stopErr != nil {
+			slog.Error("Failed to stop the partially restored server", "error", stopErr)
+		} else if removeErr := os.RemoveAll(m.config.DataDir); removeErr != nil {
+			slog.Error("Failed to remove the partially restored data directory", "error", removeErr)
+		}
+		m.mu.Unlock()
+		return nil, nil, err
+	}
+	return manifest, status, nil
+}
+
+// beginRestore checks that the data directory is empty, and prevents its
+// initialization and the start of the server until endRestore.
+func (m *Manager) beginRestore() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, err := m.status()
+	if err != nil {
+		return err
+	}
+	if status.Initialized {
+		return mterrors.Errorf(mtrpcpb.Code_FAILED_PRECONDITION, "data directory %v is already initialized", m.config.DataDir)
+	}
+	entries, err := os.ReadDir(m.config.DataDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return mterrors.Wrap(err, "failed to read the data directory")
+	}
+	if len(entries) > 0 {
+		return mterrors.Errorf(mtrpcpb.Code_FAILED_PRECONDITION, "data directory %v is not empty", m.config.DataDir)
+	}
+	m.restoring = true
+	return nil
+}
+
+// endRestore ends beginRestore.
+func (m *Manager) endRestore() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.restoring = false
+}
+
+// checkNotRestoring returns an error if a restore is running. mu must be
+// held.
+func (m *Manager) checkNotRestoring() error {
+	if m.restoring {
+		return mterrors.New(mtrpcpb.Code_FAILED_PRECONDITION, "a restore is running")
+	}
+	return nil
+}
+
+// restore restores the backup of manifest in the empty data directory,
+// with the WAL of indexes, and launches the server in recovery up to
+// target. It returns the channel receiving the exit of the server. mu is
+// only held to launch the server.
+func (m *Manager) restore(ctx context.Context, storage backupstorage.Storage, dir string, manifest *pgctldatapb.BackupManifest, indexes []*walIndex, target RecoveryTarget) (<-chan error, error) {
+	// The WAL target of the source server is removed with its files.
+	m.walMu.Lock()
+	err := m.restoreFiles(ctx, storage, dir, manifest, indexes)
+	m.walMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	settings := map[string]string{}
+	reset := []string{"recovery_target_lsn", "recovery_target_time", "recovery_target_action"}
+	switch {
+	case target.LSN != "":
+		settings["recovery_target_lsn"] = target.LSN
+	case !target.Time.IsZero():
+		settings["recovery_target_time"] = target.Time.UTC().Format("2006-01-02 15:04:05.999999+00")
+	}
+	if len(settings) > 0 {
+		settings["recovery_target_action"] = "promote"
+	}
+	status, err := m.status()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.setConfig(status, settings, reset); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(m.config.DataDir, recoverySignalFile), nil, 0o600); err != nil {
+		return nil, mterrors.Wrap(err, "failed to write the recovery signal file")
+	}
+	slog.Info("Restored backup", "backup", manifest.Name, "target_lsn", target.LSN, "target_time", target.Time)
+
+	_, exited, err := m.launch()
+	if err == nil && exited == nil {
+		err = mterrors.New(mtrpcpb.Code_INTERNAL, "a server is already running on the restored data directory")
+	}
+	return exited, err
+}
+
+// restoreFiles extracts the base backup of manifest in the data
+// directory, and copies the WAL segments of indexes in walRestoreDir.
+func (m *Manager) restoreFiles(ctx context.Context, storage backupstorage.Storage, dir string, manifest *pgctldatapb.BackupManifest, indexes []*walIndex) error {
+	if err := os.MkdirAll(m.config.DataDir, 0o700); err != nil {
+		return mterrors.Wrap(err, "failed to create the data directory")
+	}
+	i := slices.IndexFunc(manifest.Files, func(file *pgctldatapb.BackupFile) bool {
+		return file.Name == baseBackupFile
+	})
+	if i < 0 {
+		return mterrors.Errorf(mtrpcpb.Code_DATA_LOSS, "backup %v has no file %v", manifest.Name, baseBackupFile)
+	}
+	// The tablespaces are restored in the data directory, instead of the
+	// links to their locations on the source server.
+	tablespaces := map[string]bool{}
+	var tablespaceFiles []*pgctldatapb.BackupFile
+	for _, file := range manifest.Files {
+		oid, ok := strings.CutSuffix(file.Name, ".tar")
+		if _, err := strconv.ParseUint(oid, 10, 32); ok && err == nil {
+			tablespaces[oid] = true
+			tablespaceFiles = append(tablespaceFiles, file)
+		}
+	}
+	err := downloadFile(ctx, storage, dir, manifest.Name, baseBackupFile, manifest.Files[i].Sha256, func(r io.Reader) error {
+		return extractTar(r, m.config.DataDir, tablespaces)
+	})
+	if err != nil {
+		return err
+	}
+	for _, file := range tablespaceFiles {
+		tablespace := filepath.Join(m.config.DataDir, tablespaceDir, strings.TrimSuffix(file.Name, ".tar"))
+		err := downloadFile(ctx, storage, dir, manifest.Name, file.Name, file.Sha256, func(r io.Reader) error {
+			return extractTar(r, tablespace, nil)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	// The state of the source server isn't restored.
+	for _, name := range []string{pidFile, standbySignalFile, recoverySignalFile, walTargetFile} {
+		if err := os.RemoveAll(filepath.Join(m.config.DataDir, name)); err != nil {
+			return mterrors.Wrapf(err, "failed to remove %v", name)
+		}
+	}
+
+	restoreDir := filepath.Join(m.config.DataDir, walRestoreDir)
+	if err := os.MkdirAll(restoreDir, 0o700); err != nil {
+		return mterrors.Wrap(err, "failed to create the WAL restore directory")
+	}
+	for _, walIndex := range indexes {
+		for _, segment := range walIndex.Segments {
+			err := downloadFile(ctx, storage, dir, walIndex.backup, segment.Name, segment.Sha256, func(r io.Reader) error {
+				return writeFile(filepath.Join(restoreDir, path.Base(segment.Name)), r)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// downloadFile reads the file name of the backup backup in dir with read,
+// and checks its SHA-256 digest.
+func downloadFile(ctx context.Context, storage backupstorage.Storage, dir, backup, name, digest string, read func(io.Reader) error) error {
+	r, err := storage.ReadFile(ctx, dir, backup, name)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	hash := sha256.New()
+	tee := io.TeeReader(r, hash)
+	readErr := read(tee)
+	// Read what read left, to check the digest of the whole file: a
+	// corrupted file may also fail read.
+	if _, err := io.Copy(io.Discard, tee); err != nil {
+		return mterrors.Wrapf(err, "failed to read %v of backup %v", name, backup)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != digest {
+		return mterrors.Errorf(mtrpcpb.Code_DATA_LOSS, "file %v of backup %v is corrupted: its SHA-256 is %v, expected %v", name, backup, actual, digest)
+	}
+	if readErr != nil {
+		return mterrors.Wrapf(readErr, "failed to restore %v of backup %v", name, backup)
+	}
+	return nil
+}
+
+// extractTar extracts the tar archive of r in dir. The only symbolic
+// links are the links of pg_tblspc to the tablespaces, which must be in
+// tablespaces, by OID: they are not extracted, as the tablespaces are
+// restored in their place. There are no other links.
+func extractTar(r io.Reader, dir string, tablespaces map[string]bool) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !filepath.IsLocal(header.Name) {
+			return mterrors.Errorf(mtrpcpb.Code_DATA_LOSS, "invalid path %q in the archive", header.Name)
+		}
+		name := filepath.Clean(header.Name)
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o700)
+		case tar.TypeReg:
+			err = writeFile(target, tr)
+		case tar.TypeSymlink:
+			if filepath.Dir(name) != tablespaceDir {
+				return mterrors.Errorf(mtrpcpb.Code_DATA_LOSS, "invalid symbolic link %q in the archive, outside of %v", header.Name, tablespaceDir)
+			}
+			if !tablespaces[filepath.Base(name)] {
+				return mterrors.Errorf(mtrpcpb.Code_DATA_LOSS, "invalid symbolic link %q in the archive, without the archive of its tablespace", header.Name)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// writeFile writes the content of r in the new file at p.
+func writeFile(p string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,571 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	pgctldservicepb "github.com/multigres/multigres/go/pb/pgctldservice"
+	"github.com/multigres/multigres/go/pgctld"
+	"github.com/multigres/multigres/go/pgctld/backupstorage"
+	"github.com/multigres/multigres/go/pgctld/backupstorage/filebackupstorage"
+)
+
+// gatedStorage is a file storage registered under the "gated" scheme,
+// whose reads and writes of base.tar wait for storageGate.
+type gatedStorage struct {
+	backupstorage.Storage
+}
+
+// storageGate receives a value when a gatedStorage reads or writes a
+// base.tar, then the read or the write waits until it is closed.
+var storageGate chan struct{}
+
+func init() {
+	backupstorage.RegisterFactory("gated", func(root string) (backupstorage.Storage, error) {
+		storage, err := filebackupstorage.New(root)
+		return &gatedStorage{Storage: storage}, err
+	})
+}
+
+// ReadFile is part of the backupstorage.Storage interface.
+func (s *gatedStorage) ReadFile(ctx context.Context, dir, name, file string) (io.ReadCloser, error) {
+	if file == "base.tar" {
+		storageGate <- struct{}{}
+		<-storageGate
+	}
+	return s.Storage.ReadFile(ctx, dir, name, file)
+}
+
+// AddFile is part of the backupstorage.Storage interface.
+func (s *gatedStorage) AddFile(ctx context.Context, dir, name, file string) (io.WriteCloser, error) {
+	if file == "base.tar" {
+		storageGate <- struct{}{}
+		<-storageGate
+	}
+	return s.Storage.AddFile(ctx, dir, name, file)
+}
+
+// newBackupClient returns a client of a running primary, like
+// newReplicationClient, with its config. Its archived WAL is shipped
+// every 10ms.
+func newBackupClient(t *testing.T) (*fakeExecutor, pgctldservicepb.PgCtldClient, pgctld.Config) {
+	t.Helper()
+	executor := &fakeExecutor{}
+	executor.on("CASE WHEN pg_is_in_recovery()", []string{"0/3000148"})
+	config := newConfig(t)
+	config.Executor = executor
+	manager := newManager(t, config)
+	client := newClient(t, manager)
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go manager.ShipWAL(ctx, 10*time.Millisecond)
+	_, err := client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.NoError(t, err)
+	_, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	return executor, client, config
+}
+
+// archive writes a WAL segment of 16MB in the archive of the server of
+// config, like its archive_command.
+func archive(t *testing.T, config pgctld.Config, segment string) {
+	t.Helper()
+	p := filepath.Join(stateDir(config), "wal_archive", segment)
+	require.NoError(t, os.WriteFile(p+".tmp", []byte(segment), 0o600))
+	require.NoError(t, os.Truncate(p+".tmp", 16<<20))
+	require.NoError(t, os.Rename(p+".tmp", p))
+}
+
+// waitShipped waits until the WAL archive of the server of config is
+// shipped.
+func waitShipped(t *testing.T, config pgctld.Config) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		entries, err := os.ReadDir(filepath.Join(stateDir(config), "wal_archive"))
+		require.NoError(t, err)
+		return len(entries) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// fileNames returns the names of the files of manifest.
+func fileNames(manifest *pgctldatapb.BackupManifest) []string {
+	var names []string
+	for _, file := range manifest.Files {
+		names = append(names, file.Name)
+	}
+	return names
+}
+
+func TestBackup(t *testing.T) {
+	executor, client, config := newBackupClient(t)
+	location := t.TempDir()
+	ctx := context.Background()
+
+	// The server archives its WAL in the state directory, out of the data
+	// directory.
+	conf := readFile(t, config.DataDir, "postgresql.conf")
+	require.Contains(t, conf, "archive_mode = 'on'\n")
+	require.Contains(t, conf, filepath.Join(stateDir(config), "wal_archive"))
+	require.DirExists(t, filepath.Join(stateDir(config), "wal_archive"))
+
+	// The WAL archived before the first backup can't be replayed: it is
+	// dropped, but not the partially copied segments.
+	archive(t, config, "000000010000000000000001")
+	partial := filepath.Join(stateDir(config), "wal_archive", "000000010000000000000002.tmp")
+	require.NoError(t, os.WriteFile(partial, nil, 0o600))
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(filepath.Join(stateDir(config), "wal_archive", "000000010000000000000001"))
+		return os.IsNotExist(err)
+	}, 5*time.Second, 10*time.Millisecond)
+	// The backup starts at the WAL location of its backup_label.
+	require.NoError(t, os.WriteFile(filepath.Join(config.DataDir, "start_lsn"), []byte("0/3000028"), 0o600))
+	resp, err := client.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db/default/0"})
+	require.NoError(t, err)
+	first := resp.Manifest
+	require.Equal(t, "17", first.Version)
+	require.Equal(t, "0/3000028", first.StartLsn)
+	require.Equal(t, "0/3000148", first.EndLsn)
+	require.False(t, first.EndTime.AsTime().Before(first.StartTime.AsTime()))
+	require.Equal(t, []string{"backup_manifest", "base.tar"}, fileNames(first))
+	require.Len(t, first.Files[1].Sha256, 64)
+	require.FileExists(t, filepath.Join(location, "db/default/0", first.Name, "MANIFEST"))
+	require.FileExists(t, partial)
+	require.NoError(t, os.Remove(partial))
+	// The files of pgctld are not backed up.
+	f, err := os.Open(filepath.Join(location, "db/default/0", first.Name, "base.tar"))
+	require.NoError(t, err)
+	defer f.Close()
+	reader := tar.NewReader(f)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.NotContains(t, []string{"./postgres.log", "./wal_archive/", "./config_backups/"}, header.Name)
+	}
+
+	// The WAL archived after a backup is shipped to it as it is archived.
+	firstDir := filepath.Join(location, "db/default/0", first.Name)
+	archive(t, config, "000000010000000000000003")
+	waitShipped(t, config)
+	require.FileExists(t, filepath.Join(firstDir, "wal/000000010000000000000003"))
+	require.FileExists(t, filepath.Join(firstDir, "WAL"))
+
+	// The next backup ships the WAL archived before it to the previous one.
+	time.Sleep(10 * time.Millisecond)
+	archive(t, config, "000000010000000000000004")
+	executor.on("CASE WHEN pg_is_in_recovery()", []string{"0/5000000"})
+	resp, err = client.Backup(ctx, &pgctldatapb.BackupRequest{Location: "file://" + location, Directory: "db/default/0"})
+	require.NoError(t, err)
+	second := resp.Manifest
+	require.Equal(t, []string{"backup_manifest", "base.tar"}, fileNames(second))
+	require.FileExists(t, filepath.Join(firstDir, "wal/000000010000000000000004"))
+	archive(t, config, "000000010000000000000005")
+	waitShipped(t, config)
+	require.FileExists(t, filepath.Join(location, "db/default/0", second.Name, "wal/000000010000000000000005"))
+
+	// The WAL archived during a backup is shipped to it.
+	time.Sleep(10 * time.Millisecond)
+	storageGate = make(chan struct{})
+	type result struct {
+		resp *pgctldatapb.BackupResponse
+		err  error
+	}
+	done := make(chan result)
+	go func() {
+		resp, err := client.Backup(ctx, &pgctldatapb.BackupRequest{Location: "gated://" + location, Directory: "db/default/0"})
+		done <- result{resp, err}
+	}()
+	<-storageGate
+	archive(t, config, "000000010000000000000006")
+	time.Sleep(50 * time.Millisecond)
+	require.FileExists(t, filepath.Join(stateDir(config), "wal_archive", "000000010000000000000006"))
+	close(storageGate)
+	third := <-done
+	require.NoError(t, third.err)
+	waitShipped(t, config)
+	require.FileExists(t, filepath.Join(location, "db/default/0", third.resp.Manifest.Name, "wal/000000010000000000000006"))
+	require.NoFileExists(t, filepath.Join(location, "db/default/0", second.Name, "wal/000000010000000000000006"))
+
+	// The incomplete backups are not listed.
+	require.NoError(t, os.MkdirAll(filepath.Join(location, "db/default/0", "9999-01-01.000000.000"), 0o755))
+	listResp, err := client.ListBackups(ctx, &pgctldatapb.ListBackupsRequest{Location: location, Directory: "db/default/0"})
+	require.NoError(t, err)
+	require.Len(t, listResp.Manifests, 3)
+	require.Equal(t, first.Name, listResp.Manifests[0].Name)
+	require.Equal(t, second.Name, listResp.Manifests[1].Name)
+	listResp, err = client.ListBackups(ctx, &pgctldatapb.ListBackupsRequest{Location: location, Directory: "db/default/1"})
+	require.NoError(t, err)
+	require.Empty(t, listResp.Manifests)
+
+	for _, req := range []*pgctldatapb.BackupRequest{
+		{Location: "", Directory: "db/default/0"},
+		{Location: "s3://bucket", Directory: "db/default/0"},
+		{Location: "relative/path", Directory: "db/default/0"},
+		{Location: location, Directory: "../0"},
+		{Location: location, Directory: ""},
+	} {
+		_, err = client.Backup(ctx, req)
+		require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err), "%v", req)
+	}
+
+	_, err = client.Stop(ctx, &pgctldatapb.StopRequest{})
+	require.NoError(t, err)
+	_, err = client.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db/default/0"})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+}
+
+// newRestoreClient returns a client of a server with an empty data
+// directory, and its config. The server is in recovery once started.
+func newRestoreClient(t *testing.T) (pgctldservicepb.PgCtldClient, pgctld.Config) {
+	t.Helper()
+	config := newConfig(t)
+	config.Port = 5434
+	executor := &fakeExecutor{}
+	executor.on("pg_is_in_recovery", []string{"t"})
+	config.Executor = executor
+	return newClient(t, newManager(t, config)), config
+}
+
+func TestRestore(t *testing.T) {
+	executor, source, sourceConfig := newBackupClient(t)
+	location := t.TempDir()
+	ctx := context.Background()
+	// Each backup is followed by a WAL segment, shipped to it.
+	backup := func(lsn string, segment string) *pgctldatapb.BackupManifest {
+		time.Sleep(10 * time.Millisecond)
+		executor.on("CASE WHEN pg_is_in_recovery()", []string{lsn})
+		resp, err := source.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db"})
+		require.NoError(t, err)
+		archive(t, sourceConfig, segment)
+		waitShipped(t, sourceConfig)
+		return resp.Manifest
+	}
+	first := backup("0/3000000", "000000010000000000000003")
+	second := backup("0/4000000", "000000010000000000000004")
+	third := backup("0/5000000", "000000010000000000000005")
+
+	// The latest backup before the target WAL location is restored, with
+	// the WAL shipped since, and the server recovers up to the target.
+	client, config := newRestoreClient(t)
+	resp, err := client.Restore(ctx, &pgctldatapb.RestoreRequest{
+		Location:  location,
+		Directory: "db",
+		Target:    &pgctldatapb.RestoreRequest_TargetLsn{TargetLsn: "0/3800000"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, first.Name, resp.Manifest.Name)
+	require.Equal(t, pgctldatapb.ServerState_RUNNING, resp.Status.State)
+	require.Equal(t, int32(5434), resp.Status.Port)
+	require.FileExists(t, filepath.Join(config.DataDir, "recovery.signal"))
+	for _, segment := range []string{"000000010000000000000003", "000000010000000000000004", "000000010000000000000005"} {
+		require.FileExists(t, filepath.Join(config.DataDir, "wal_restore", segment))
+	}
+	// The state of the source server isn't restored.
+	require.NoFileExists(t, filepath.Join(config.DataDir, "pgctld_wal_target.yaml"))
+	conf := readFile(t, config.DataDir, "postgresql.conf")
+	require.Contains(t, conf, "recovery_target_lsn = '0/3800000'\n")
+	require.Contains(t, conf, "recovery_target_action = 'promote'\n")
+	require.Contains(t, conf, "port = '5434'\n")
+
+	// The data directory must be empty.
+	_, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db"})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+
+	// Without target, the latest backup is restored, and all the WAL
+	// replayed.
+	client, config = newRestoreClient(t)
+	resp, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+	require.Equal(t, third.Name, resp.Manifest.Name)
+	require.NotContains(t, readFile(t, config.DataDir, "postgresql.conf"), "recovery_target")
+	entries, err := os.ReadDir(filepath.Join(config.DataDir, "wal_restore"))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// A target time selects the backups by their end.
+	client, config = newRestoreClient(t)
+	target := second.EndTime.AsTime().Add(time.Millisecond)
+	resp, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{
+		Location:  location,
+		Directory: "db",
+		Target:    &pgctldatapb.RestoreRequest_TargetTime{TargetTime: timestamppb.New(target)},
+	})
+	require.NoError(t, err)
+	require.Equal(t, second.Name, resp.Manifest.Name)
+	require.Contains(t, readFile(t, config.DataDir, "postgresql.conf"),
+		"recovery_target_time = '"+target.UTC().Format("2006-01-02 15:04:05.999999+00")+"'\n")
+
+	// A backup is restored by name.
+	client, _ = newRestoreClient(t)
+	resp, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db", BackupName: second.Name})
+	require.NoError(t, err)
+	require.Equal(t, second.Name, resp.Manifest.Name)
+
+	client, config = newRestoreClient(t)
+	for _, tc := range []struct {
+		req  *pgctldatapb.RestoreRequest
+		code mtrpcpb.Code
+	}{
+		{&pgctldatapb.RestoreRequest{Location: location, Directory: "db", BackupName: "no-such-backup"}, mtrpcpb.Code_NOT_FOUND},
+		{&pgctldatapb.RestoreRequest{Location: location, Directory: "db", Target: &pgctldatapb.RestoreRequest_TargetLsn{TargetLsn: "0/1000000"}}, mtrpcpb.Code_NOT_FOUND},
+		{&pgctldatapb.RestoreRequest{Location: location, Directory: "db", Target: &pgctldatapb.RestoreRequest_TargetLsn{TargetLsn: "latest"}}, mtrpcpb.Code_INVALID_ARGUMENT},
+		{&pgctldatapb.RestoreRequest{Location: location, Directory: "db", BackupName: third.Name, Target: &pgctldatapb.RestoreRequest_TargetLsn{TargetLsn: "0/4800000"}}, mtrpcpb.Code_INVALID_ARGUMENT},
+		// The targets after the end of the shipped WAL can't be reached.
+		{&pgctldatapb.RestoreRequest{Location: location, Directory: "db", Target: &pgctldatapb.RestoreRequest_TargetLsn{TargetLsn: "0/6000001"}}, mtrpcpb.Code_INVALID_ARGUMENT},
+		{&pgctldatapb.RestoreRequest{Location: location, Directory: "db", Target: &pgctldatapb.RestoreRequest_TargetTime{TargetTime: timestamppb.New(time.Now().Add(time.Hour))}}, mtrpcpb.Code_INVALID_ARGUMENT},
+		{&pgctldatapb.RestoreRequest{Location: location, Directory: "other"}, mtrpcpb.Code_NOT_FOUND},
+	} {
+		_, err = client.Restore(ctx, tc.req)
+		require.Equal(t, tc.code, mterrors.Code(err), "%v", tc.req)
+	}
+
+	// A corrupted backup is not restored, and the data directory is left
+	// uninitialized.
+	base := filepath.Join(location, "db", third.Name, "base.tar")
+	data, err := os.ReadFile(base)
+	require.NoError(t, err)
+	data[len(data)-1] ^= 1
+	require.NoError(t, os.WriteFile(base, data, 0o600))
+	_, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db"})
+	require.Equal(t, mtrpcpb.Code_DATA_LOSS, mterrors.Code(err), "%v", err)
+	status, err := client.Status(ctx, &pgctldatapb.StatusRequest{})
+	require.NoError(t, err)
+	require.False(t, status.Status.Initialized)
+	require.NoDirExists(t, config.DataDir)
+}
+
+func TestRestoreRollback(t *testing.T) {
+	_, source, sourceConfig := newBackupClient(t)
+	location := t.TempDir()
+	ctx := context.Background()
+	resp, err := source.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+	first := resp.Manifest
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(sourceConfig.DataDir, "fail_start"), nil, 0o600))
+	_, err = source.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+
+	// The server restored from the second backup fails to start: the data
+	// directory is removed, to restore again.
+	client, config := newRestoreClient(t)
+	_, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db"})
+	require.Equal(t, mtrpcpb.Code_INTERNAL, mterrors.Code(err), "%v", err)
+	require.NoDirExists(t, config.DataDir)
+	restoreResp, err := client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db", BackupName: first.Name})
+	require.NoError(t, err)
+	require.Equal(t, first.Name, restoreResp.Manifest.Name)
+	require.Equal(t, pgctldatapb.ServerState_RUNNING, restoreResp.Status.State)
+
+	// A data directory that isn't empty is left alone.
+	client, config = newRestoreClient(t)
+	require.NoError(t, os.MkdirAll(config.DataDir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(config.DataDir, "file"), nil, 0o600))
+	_, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db", BackupName: first.Name})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+	require.FileExists(t, filepath.Join(config.DataDir, "file"))
+}
+
+func TestRestoreLongRecovery(t *testing.T) {
+	_, source, sourceConfig := newBackupClient(t)
+	location := t.TempDir()
+	ctx := context.Background()
+	// The restored servers replay the WAL for 0.5s.
+	require.NoError(t, os.WriteFile(filepath.Join(sourceConfig.DataDir, "start_delay"), []byte("0.5"), 0o600))
+	_, err := source.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+	restoreClient := func() (pgctldservicepb.PgCtldClient, pgctld.Config) {
+		config := newConfig(t)
+		config.Port = 5434
+		config.Timeout = 100 * time.Millisecond
+		return newClient(t, newManager(t, config)), config
+	}
+
+	// The replay isn't bounded by the start timeout.
+	client, _ := restoreClient()
+	resp, err := client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+	require.True(t, resp.Status.Ready)
+
+	// It is bounded by the call, after which the server keeps recovering.
+	client, config := restoreClient()
+	shortCtx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	_, err = client.Restore(shortCtx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db"})
+	require.Equal(t, mtrpcpb.Code_DEADLINE_EXCEEDED, mterrors.Code(err), "%v", err)
+	require.FileExists(t, filepath.Join(config.DataDir, "PG_VERSION"))
+	require.Eventually(t, func() bool {
+		status, err := client.Status(ctx, &pgctldatapb.StatusRequest{})
+		require.NoError(t, err)
+		return status.Status.Ready
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRestoreLock(t *testing.T) {
+	_, source, _ := newBackupClient(t)
+	location := t.TempDir()
+	ctx := context.Background()
+	_, err := source.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+
+	storageGate = make(chan struct{})
+	client, _ := newRestoreClient(t)
+	done := make(chan error)
+	go func() {
+		_, err := client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: "gated://" + location, Directory: "db"})
+		done <- err
+	}()
+	<-storageGate
+
+	// The status is served while the backup downloads, but the data
+	// directory can't be initialized, or restored again, and the server
+	// can't start.
+	statusCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	_, err = client.Status(statusCtx, &pgctldatapb.StatusRequest{})
+	require.NoError(t, err)
+	_, err = client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+	_, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+	_, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db"})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+
+	close(storageGate)
+	require.NoError(t, <-done)
+	status, err := client.Status(ctx, &pgctldatapb.StatusRequest{})
+	require.NoError(t, err)
+	require.Equal(t, pgctldatapb.ServerState_RUNNING, status.Status.State)
+}
+
+func TestRestoreTablespaces(t *testing.T) {
+	_, source, sourceConfig := newBackupClient(t)
+	location := t.TempDir()
+	ctx := context.Background()
+	tablespace := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tablespace, "PG_17/5"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(tablespace, "PG_17/5/16385"), []byte("table"), 0o600))
+	require.NoError(t, os.MkdirAll(filepath.Join(sourceConfig.DataDir, "pg_tblspc"), 0o700))
+	require.NoError(t, os.Symlink(tablespace, filepath.Join(sourceConfig.DataDir, "pg_tblspc/16384")))
+	resp, err := source.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+	require.Contains(t, fileNames(resp.Manifest), "16384.tar")
+
+	// The tablespace is restored in the data directory, not at its
+	// location on the source server.
+	client, config := newRestoreClient(t)
+	_, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+	info, err := os.Lstat(filepath.Join(config.DataDir, "pg_tblspc/16384"))
+	require.NoError(t, err)
+	require.True(t, info.IsDir())
+	require.Equal(t, "table", readFile(t, config.DataDir, "pg_tblspc/16384/PG_17/5/16385"))
+}
+
+// writeBackup writes the backup name in the directory dir of the file
+// storage at location, with a base.tar of entries.
+func writeBackup(t *testing.T, location, dir, name string, entries ...*tar.Header) {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, header := range entries {
+		require.NoError(t, tw.WriteHeader(header))
+		_, err := tw.Write(make([]byte, header.Size))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	backupDir := filepath.Join(location, dir, name)
+	require.NoError(t, os.MkdirAll(backupDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "base.tar"), buf.Bytes(), 0o600))
+	digest := sha256.Sum256(buf.Bytes())
+	manifest, err := protojson.Marshal(&pgctldatapb.BackupManifest{
+		Name:    name,
+		EndLsn:  "0/3000000",
+		EndTime: timestamppb.Now(),
+		Files:   []*pgctldatapb.BackupFile{{Name: "base.tar", Size: int64(buf.Len()), Sha256: hex.EncodeToString(digest[:])}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(backupDir, "MANIFEST"), manifest, 0o600))
+}
+
+func TestRestoreInvalidArchive(t *testing.T) {
+	location := t.TempDir()
+	outside := t.TempDir()
+	ctx := context.Background()
+	for name, entries := range map[string][]*tar.Header{
+		"escape": {
+			{Name: "../file", Typeflag: tar.TypeReg, Mode: 0o600},
+		},
+		"link": {
+			{Name: "base", Typeflag: tar.TypeSymlink, Linkname: outside},
+		},
+		"through-link": {
+			{Name: "pg_tblspc/16384", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "pg_tblspc/16384/file", Typeflag: tar.TypeReg, Mode: 0o600, Size: 4},
+		},
+		"tablespace-without-archive": {
+			{Name: "pg_tblspc/16384", Typeflag: tar.TypeSymlink, Linkname: outside},
+			{Name: "PG_VERSION", Typeflag: tar.TypeReg, Mode: 0o600, Size: 4},
+		},
+	} {
+		writeBackup(t, location, name, "backup1", entries...)
+		client, config := newRestoreClient(t)
+		_, err := client.Restore(ctx, &pgctldatapb.RestoreRequest{Location: location, Directory: name})
+		require.Equal(t, mtrpcpb.Code_DATA_LOSS, mterrors.Code(err), "%v: %v", name, err)
+		require.NoDirExists(t, config.DataDir)
+	}
+	entries, err := os.ReadDir(outside)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestSetPrimaryConnInfoResetsRecoveryTarget(t *testing.T) {
+	_, source, _ := newBackupClient(t)
+	location := t.TempDir()
+	ctx := context.Background()
+	_, err := source.Backup(ctx, &pgctldatapb.BackupRequest{Location: location, Directory: "db"})
+	require.NoError(t, err)
+
+	// A standby restored from a backup replays all the WAL of its primary.
+	client, config := newRestoreClient(t)
+	_, err = client.Restore(ctx, &pgctldatapb.RestoreRequest{
+		Location:  location,
+		Directory: "db",
+		Target:    &pgctldatapb.RestoreRequest_TargetLsn{TargetLsn: "0/3000148"},
+	})
+	require.NoError(t, err)
+	_, err = client.SetPrimaryConnInfo(ctx, &pgctldatapb.SetPrimaryConnInfoRequest{PrimaryConninfo: "host=primary port=5432"})
+	require.NoError(t, err)
+	require.NotContains(t, readFile(t, config.DataDir, "postgresql.conf"), "recovery_target")
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backupstorage defines the storage of the backups taken by
+// pgctld, and the registry of its implementations.
+//
+// A storage holds directories of backups, usually one directory per shard.
+// A backup is a set of files, identified by its name in its directory.
+// The implementations register a Factory under the scheme of their
+// locations, like "file" for "file:///var/backups". Locations without a
+// scheme are paths of the file implementation.
+package backupstorage
+
+import (
+	"context"
+	"io"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// Storage stores backups.
+type Storage interface {
+	// ListBackups returns the names of the backups in dir, sorted. It
+	// returns an empty list if dir doesn't exist.
+	ListBackups(ctx context.Context, dir string) ([]string, error)
+
+	// AddFile returns a writer of the file of the backup name in dir. The
+	// backup is created with its first file. The file is stored when the
+	// writer is closed without error.
+	AddFile(ctx context.Context, dir, name, file string) (io.WriteCloser, error)
+
+	// ReadFile returns a reader of the file of the backup name in dir. It
+	// returns a NOT_FOUND error if the file doesn't exist.
+	ReadFile(ctx context.Context, dir, name, file string) (io.ReadCloser, error)
+
+	// RemoveBackup removes the backup name in dir, with all its files.
+	RemoveBackup(ctx context.Context, dir, name string) error
+
+	// Close releases the resources of the storage.
+	Close() error
+}
+
+// Factory returns the Storage at location, the part of the location after
+// the scheme.
+type Factory func(location string) (Storage, error)
+
+// DefaultScheme is the scheme of the locations without scheme.
+const DefaultScheme = "file"
+
+var factories = make(map[string]Factory)
+
+// RegisterFactory registers the Factory of the locations with scheme. If a
+// Factory is already registered for scheme, it will log.Fatal and exit.
+// Call this function in the 'init' function of the implementation.
+func RegisterFactory(scheme string, factory Factory) {
+	if factories[scheme] != nil {
+		log.Fatalf("Duplicate backupstorage.Factory registration for %v", scheme)
+	}
+	factories[scheme] = factory
+}
+
+// Open returns the Storage at location, like "file:///var/backups".
+func Open(location string) (Storage, error) {
+	if location == "" {
+		return nil, mterrors.New(mtrpcpb.Code_INVALID_ARGUMENT, "empty backup location")
+	}
+	scheme, rest, found := strings.Cut(location, "://")
+	if !found {
+		scheme, rest = DefaultScheme, location
+	}
+	factory, ok := factories[scheme]
+	if !ok {
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "no backup storage implementation for %q", scheme)
+	}
+	return factory(rest)
+}
+
+// ValidatePath returns an error if p, a directory, a backup name or a file
+// name, is not a relative path within the storage.
+func ValidatePath(p string) error {
+	if p == "" || path.IsAbs(p) || path.Clean(p) != p || p == ".." || strings.HasPrefix(p, "../") {
+		return mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "invalid backup path %q", p)
+	}
+	return nil
+}
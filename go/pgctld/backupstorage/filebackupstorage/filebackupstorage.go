@@ -0,0 +1,158 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filebackupstorage implements the backup storage in a directory
+// of the local filesystem, usually a network filesystem shared by the
+// servers. It is registered under the "file" scheme: the location is the
+// path of the root directory, like "file:///var/backups".
+//
+// The files of a backup are stored in <root>/<dir>/<name>.
+package filebackupstorage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	"github.com/multigres/multigres/go/pgctld/backupstorage"
+)
+
+func init() {
+	backupstorage.RegisterFactory("file", New)
+}
+
+// Storage is a backupstorage.Storage in a local directory.
+type Storage struct {
+	root string
+}
+
+var _ backupstorage.Storage = (*Storage)(nil)
+
+// New returns the Storage in the directory root.
+func New(root string) (backupstorage.Storage, error) {
+	if !filepath.IsAbs(root) {
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "backup root %q is not an absolute path", root)
+	}
+	return &Storage{root: root}, nil
+}
+
+// path returns the path of elems in the storage, after validating them.
+func (s *Storage) path(elems ...string) (string, error) {
+	for _, elem := range elems {
+		if err := backupstorage.ValidatePath(elem); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(append([]string{s.root}, elems...)...), nil
+}
+
+// ListBackups is part of the backupstorage.Storage interface.
+func (s *Storage) ListBackups(ctx context.Context, dir string) ([]string, error) {
+	p, err := s.path(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, mterrors.Wrapf(err, "failed to list the backups in %v", dir)
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// AddFile is part of the backupstorage.Storage interface. The file is
+// written next to its path, and renamed when the writer is closed.
+func (s *Storage) AddFile(ctx context.Context, dir, name, file string) (io.WriteCloser, error) {
+	p, err := s.path(dir, name, file)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return nil, mterrors.Wrapf(err, "failed to create the backup %v", name)
+	}
+	f, err := os.Create(p + ".tmp")
+	if err != nil {
+		return nil, mterrors.Wrapf(err, "failed to create file %v of backup %v", file, name)
+	}
+	return &fileWriter{File: f, path: p}, nil
+}
+
+// fileWriter renames the file to its path when it is closed.
+type fileWriter struct {
+	*os.File
+	path string
+}
+
+// Close syncs and closes the file, and renames it.
+func (w *fileWriter) Close() error {
+	err := w.Sync()
+	if closeErr := w.File.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(w.Name(), w.path)
+	}
+	if err != nil {
+		_ = os.Remove(w.Name())
+		return mterrors.Wrapf(err, "failed to write %v", w.path)
+	}
+	return nil
+}
+
+// ReadFile is part of the backupstorage.Storage interface.
+func (s *Storage) ReadFile(ctx context.Context, dir, name, file string) (io.ReadCloser, error) {
+	p, err := s.path(dir, name, file)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, mterrors.Errorf(mtrpcpb.Code_NOT_FOUND, "file %v of backup %v doesn't exist", file, name)
+	}
+	if err != nil {
+		return nil, mterrors.Wrapf(err, "failed to open file %v of backup %v", file, name)
+	}
+	return f, nil
+}
+
+// RemoveBackup is part of the backupstorage.Storage interface.
+func (s *Storage) RemoveBackup(ctx context.Context, dir, name string) error {
+	p, err := s.path(dir, name)
+	if err != nil {
+		return err
+	}
+	if err := os.RemoveAll(p); err != nil {
+		return mterrors.Wrapf(err, "failed to remove backup %v", name)
+	}
+	return nil
+}
+
+// Close is part of the backupstorage.Storage interface.
+func (s *Storage) Close() error {
+	return nil
+}
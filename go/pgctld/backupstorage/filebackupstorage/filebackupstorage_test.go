@@ -0,0 +1,78 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filebackupstorage_test
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	"github.com/multigres/multigres/go/pgctld/backupstorage"
+	_ "github.com/multigres/multigres/go/pgctld/backupstorage/filebackupstorage"
+)
+
+func TestStorage(t *testing.T) {
+	root := t.TempDir()
+	storage, err := backupstorage.Open("file://" + root)
+	require.NoError(t, err)
+	defer storage.Close()
+	ctx := context.Background()
+
+	names, err := storage.ListBackups(ctx, "db/0")
+	require.NoError(t, err)
+	require.Empty(t, names)
+
+	// A file is stored when its writer is closed.
+	w, err := storage.AddFile(ctx, "db/0", "backup2", "wal/segment")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("data"))
+	require.NoError(t, err)
+	_, err = storage.ReadFile(ctx, "db/0", "backup2", "wal/segment")
+	require.Equal(t, mtrpcpb.Code_NOT_FOUND, mterrors.Code(err))
+	require.NoError(t, w.Close())
+	require.FileExists(t, filepath.Join(root, "db/0/backup2/wal/segment"))
+	r, err := storage.ReadFile(ctx, "db/0", "backup2", "wal/segment")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "data", string(data))
+
+	// The backups are listed sorted.
+	w, err = storage.AddFile(ctx, "db/0", "backup1", "base.tar")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	names, err = storage.ListBackups(ctx, "db/0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"backup1", "backup2"}, names)
+
+	require.NoError(t, storage.RemoveBackup(ctx, "db/0", "backup2"))
+	names, err = storage.ListBackups(ctx, "db/0")
+	require.NoError(t, err)
+	require.Equal(t, []string{"backup1"}, names)
+
+	// The paths stay within the root.
+	for _, p := range []string{"", "/db", "../db", "db/../../x", "./db"} {
+		_, err = storage.AddFile(ctx, p, "backup1", "base.tar")
+		require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err), "%q", p)
+	}
+	_, err = backupstorage.Open("relative")
+	require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+}
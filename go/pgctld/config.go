@@ -0,0 +1,413 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+)
+
+// This file generates the postgresql.conf and pg_hba.conf files of the
+// server. They are rendered from the templates, with the settings and the
+// rules in this order of precedence:
+//
+//   - the settings set through SetConfig, kept in settingsFile in the
+//     data directory;
+//   - the overrides file, a YAML file with a settings map and an hba
+//     list, maintained by the operators;
+//   - the defaults of pgctld.
+//
+// The files are written before the server starts, when the server reloads
+// its configuration, and by SetConfig. The previous versions are kept in
+// backupDir in the state directory.
+
+const (
+	// settingsFile holds the settings set through SetConfig.
+	settingsFile = "pgctld_settings.yaml"
+
+	// backupDir holds the previous versions of the configuration files.
+	backupDir = "config_backups"
+
+	// maxBackups is the number of previous versions kept for each file.
+	maxBackups = 10
+)
+
+var (
+	//go:embed templates/*.tmpl
+	templatesFS embed.FS
+
+	templates = template.Must(template.ParseFS(templatesFS, "templates/*.tmpl"))
+)
+
+// HBARule is a rule of pg_hba.conf.
+type HBARule struct {
+	// Type is the connection type: local, host, hostssl or hostnossl.
+	Type string `yaml:"type"`
+
+	// Database and User are the databases and the users the rule matches.
+	Database string `yaml:"database"`
+	User     string `yaml:"user"`
+
+	// Address is the client address of the host rules, usually a CIDR.
+	Address string `yaml:"address"`
+
+	// Method is the authentication method, like scram-sha-256.
+	Method string `yaml:"method"`
+}
+
+var (
+	hbaTypes   = []string{"local", "host", "hostssl", "hostnossl"}
+	hbaMethods = []string{"trust", "reject", "scram-sha-256", "md5", "password", "peer", "ident", "cert", "ldap", "radius", "pam", "gss", "sspi"}
+)
+
+// validate returns an error if the rule is invalid.
+func (r HBARule) validate() error {
+	var err error
+	switch {
+	case !slices.Contains(hbaTypes, r.Type):
+		err = errors.New("unknown type")
+	case !slices.Contains(hbaMethods, r.Method):
+		err = errors.New("unknown method")
+	case r.Database == "" || r.User == "" || strings.ContainsAny(r.Database+r.User+r.Address, " \t\n\r"):
+		err = errors.New("invalid database or user")
+	case r.Type == "local" && r.Address != "":
+		err = errors.New("local rules have no address")
+	case r.Type != "local" && r.Address == "":
+		err = errors.New("missing address")
+	case strings.Contains(r.Address, "/"):
+		_, _, err = net.ParseCIDR(r.Address)
+	}
+	if err != nil {
+		return mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "invalid pg_hba.conf rule %+v: %v", r, err)
+	}
+	return nil
+}
+
+// configOverrides is the content of the overrides file.
+type configOverrides struct {
+	Settings map[string]string `yaml:"settings"`
+	HBA      []HBARule         `yaml:"hba"`
+}
+
+// serverConfig is the configuration rendered in the files.
+type serverConfig struct {
+	settings map[string]string
+	hba      []HBARule
+}
+
+// defaultConfig returns the configuration of the server before the
+// overrides.
+func (m *Manager) defaultConfig() *serverConfig {
+	archiveDir := filepath.Join(m.config.StateDir, walArchiveDir)
+	restoreDir := filepath.Join(m.config.DataDir, walRestoreDir)
+	return &serverConfig{
+		settings: map[string]string{
+			"listen_addresses":        "*",
+			"port":                    strconv.Itoa(m.config.Port),
+			"unix_socket_directories": m.config.DataDir,
+			"max_connections":         "100",
+			"shared_buffers":          "128MB",
+			"password_encryption":     "scram-sha-256",
+			"wal_level":               "replica",
+			"wal_log_hints":           "on",
+			"max_wal_senders":         "10",
+			"max_replication_slots":   "10",
+			"hot_standby":             "on",
+			"log_line_prefix":         "%m [%p] ",
+			// The WAL is archived in the state directory, with the time of
+			// its last write, and shipped to the last backup. See wal.go.
+			"archive_mode":    "on",
+			"archive_command": fmt.Sprintf(`test ! -f "%[1]v/%%f" && cp -p %%p "%[1]v/%%f.tmp" && mv "%[1]v/%%f.tmp" "%[1]v/%%f"`, archiveDir),
+			"restore_command": fmt.Sprintf(`cp "%v/%%f" %%p`, restoreDir),
+		},
+		hba: []HBARule{
+			{Type: "host", Database: "all", User: "all", Address: "127.0.0.1/32", Method: "scram-sha-256"},
+			{Type: "host", Database: "all", User: "all", Address: "::1/128", Method: "scram-sha-256"},
+			{Type: "host", Database: "replication", User: "all", Address: "127.0.0.1/32", Method: "scram-sha-256"},
+		},
+	}
+}
+
+// readSettings returns the settings set through SetConfig.
+func (m *Manager) readSettings() (map[string]string, error) {
+	settings := map[string]string{}
+	data, err := os.ReadFile(filepath.Join(m.config.DataDir, settingsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to read the settings")
+	}
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return nil, mterrors.Wrapf(err, "failed to parse %v", settingsFile)
+	}
+	return settings, nil
+}
+
+// loadConfig returns the configuration of the server, with the overrides
+// file and the settings set through SetConfig. It returns all the invalid
+// settings and rules.
+func (m *Manager) loadConfig() (*serverConfig, error) {
+	settings, err := m.readSettings()
+	if err != nil {
+		return nil, err
+	}
+	return m.configWith(settings)
+}
+
+// configWith returns the configuration of the server, with the overrides
+// file and settings, like loadConfig.
+func (m *Manager) configWith(settings map[string]string) (*serverConfig, error) {
+	config := m.defaultConfig()
+	var overrides configOverrides
+	if m.config.OverridesFile != "" {
+		data, err := os.ReadFile(m.config.OverridesFile)
+		if err != nil {
+			return nil, mterrors.Wrap(err, "failed to read the config overrides")
+		}
+		if err := yaml.Unmarshal(data, &overrides); err != nil {
+			return nil, mterrors.Wrapf(err, "failed to parse the config overrides %v", m.config.OverridesFile)
+		}
+	}
+
+	var errs []error
+	for _, s := range []map[string]string{overrides.Settings, settings} {
+		for name, value := range s {
+			if err := validateSetting(name, value); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			config.settings[name] = value
+		}
+	}
+	for _, rule := range overrides.HBA {
+		if err := rule.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := mterrors.Aggregate(errs); err != nil {
+		return nil, mterrors.Wrap(err, "invalid configuration")
+	}
+	// The rules of the overrides take precedence over the defaults, and
+	// over the trust of the local connections: only those of pgctld, as
+	// its user, come first.
+	config.hba = append(overrides.HBA, config.hba...)
+	return config, nil
+}
+
+// writeConfig renders config in the configuration files of the data
+// directory. It returns true if pg_hba.conf changed.
+func (m *Manager) writeConfig(config *serverConfig) (hbaChanged bool, err error) {
+	type setting struct{ Name, Value string }
+	data := struct {
+		BackupDir string
+		User      string
+		Settings  []setting
+		HBA       []HBARule
+	}{
+		BackupDir: filepath.Join(m.config.StateDir, backupDir),
+		User:      strings.ReplaceAll(m.config.User, `"`, `""`),
+		HBA:       config.hba,
+	}
+	for _, name := range slices.Sorted(maps.Keys(config.settings)) {
+		data.Settings = append(data.Settings, setting{Name: name, Value: quoteSetting(config.settings[name])})
+	}
+
+	if _, err := m.writeFile("postgresql.conf", data); err != nil {
+		return false, err
+	}
+	return m.writeFile("pg_hba.conf", data)
+}
+
+// writeFile renders the template of the configuration file name with data.
+// If the file changed, it keeps its previous version and returns true.
+func (m *Manager) writeFile(name string, data any) (bool, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name+".tmpl", data); err != nil {
+		return false, mterrors.Wrapf(err, "failed to render %v", name)
+	}
+	path := filepath.Join(m.config.DataDir, name)
+	previous, err := os.ReadFile(path)
+	switch {
+	case err == nil && bytes.Equal(previous, buf.Bytes()):
+		return false, nil
+	case err == nil:
+		if err := m.backupFile(name, previous); err != nil {
+			return false, err
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return false, mterrors.Wrapf(err, "failed to read %v", name)
+	}
+
+	if err := writeFileAtomic(path, buf.Bytes()); err != nil {
+		return false, mterrors.Wrapf(err, "failed to write %v", name)
+	}
+	slog.Info("Wrote configuration file", "file", path)
+	return true, nil
+}
+
+// backupFile keeps content, the previous version of the configuration
+// file name, and deletes the oldest versions.
+func (m *Manager) backupFile(name string, content []byte) error {
+	dir := filepath.Join(m.config.StateDir, backupDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return mterrors.Wrap(err, "failed to create the backup directory")
+	}
+	backup := filepath.Join(dir, name+"."+time.Now().UTC().Format("20060102T150405.000000000Z"))
+	if err := os.WriteFile(backup, content, 0o600); err != nil {
+		return mterrors.Wrapf(err, "failed to back up %v", name)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, name+".*"))
+	if err != nil {
+		return mterrors.Wrap(err, "failed to list the backups")
+	}
+	sort.Strings(backups)
+	for len(backups) > maxBackups {
+		if err := os.Remove(backups[0]); err != nil {
+			return mterrors.Wrap(err, "failed to delete an old backup")
+		}
+		backups = backups[1:]
+	}
+	return nil
+}
+
+// writeFileAtomic replaces the file at path with content.
+func writeFileAtomic(path string, content []byte) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// updateConfig renders the current configuration in the configuration
+// files. It returns true if pg_hba.conf changed.
+func (m *Manager) updateConfig() (bool, error) {
+	config, err := m.loadConfig()
+	if err != nil {
+		return false, err
+	}
+	return m.writeConfig(config)
+}
+
+// SetConfig sets the postgresql.conf settings, and resets the settings
+// named in reset to their default or overridden value. The settings are
+// kept across restarts. It returns the names of the settings whose value
+// changed, and whether the running server must reload its configuration
+// or restart to apply them. Nothing is changed if any setting is invalid.
+func (m *Manager) SetConfig(ctx context.Context, settings map[string]string, reset []string) (*pgctldatapb.SetConfigResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, err := m.status()
+	if err != nil {
+		return nil, err
+	}
+	if !status.Initialized {
+		return nil, mterrors.Errorf(mtrpcpb.Code_FAILED_PRECONDITION, "data directory %v is not initialized", m.config.DataDir)
+	}
+	return m.setConfig(status, settings, reset)
+}
+
+// setConfig implements SetConfig for the server of status.
+func (m *Manager) setConfig(status *pgctldatapb.PostgresStatus, settings map[string]string, reset []string) (*pgctldatapb.SetConfigResponse, error) {
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(settings)) {
+		if err := validateSetting(name, settings[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, name := range reset {
+		if _, ok := knownSettings[name]; !ok {
+			errs = append(errs, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "unknown setting %v", name))
+		}
+	}
+	if err := mterrors.Aggregate(errs); err != nil {
+		return nil, err
+	}
+
+	oldConfig, err := m.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	saved, err := m.readSettings()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range reset {
+		delete(saved, name)
+	}
+	maps.Copy(saved, settings)
+
+	// The settings are only saved once rendered: the files are rendered
+	// from the saved settings again before the server starts.
+	newConfig, err := m.configWith(saved)
+	if err != nil {
+		return nil, err
+	}
+	hbaChanged, err := m.writeConfig(newConfig)
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(saved)
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to marshal the settings")
+	}
+	if err := writeFileAtomic(filepath.Join(m.config.DataDir, settingsFile), data); err != nil {
+		return nil, mterrors.Wrap(err, "failed to write the settings")
+	}
+
+	resp := &pgctldatapb.SetConfigResponse{}
+	for _, name := range slices.Sorted(maps.Keys(newConfig.settings)) {
+		if oldValue, ok := oldConfig.settings[name]; !ok || oldValue != newConfig.settings[name] {
+			resp.ChangedSettings = append(resp.ChangedSettings, name)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(oldConfig.settings)) {
+		if _, ok := newConfig.settings[name]; !ok {
+			resp.ChangedSettings = append(resp.ChangedSettings, name)
+		}
+	}
+	if status.State == pgctldatapb.ServerState_RUNNING {
+		for _, name := range resp.ChangedSettings {
+			if requiresRestart(name) {
+				resp.RestartRequired = true
+			}
+		}
+		resp.ReloadRequired = !resp.RestartRequired && (len(resp.ChangedSettings) > 0 || hbaChanged)
+	}
+	slog.Info("Set configuration", "changed", resp.ChangedSettings, "reload_required", resp.ReloadRequired, "restart_required", resp.RestartRequired)
+	return resp, nil
+}
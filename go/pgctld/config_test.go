@@ -0,0 +1,242 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	"github.com/multigres/multigres/go/pgctld"
+)
+
+// readFile returns the content of the file name of the data directory.
+func readFile(t *testing.T, dataDir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dataDir, name))
+	require.NoError(t, err)
+	return string(data)
+}
+
+// writeOverrides writes content in the overrides file of config.
+func writeOverrides(t *testing.T, config pgctld.Config, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(config.OverridesFile, []byte(content), 0o644))
+}
+
+func TestConfigFiles(t *testing.T) {
+	config := newConfig(t)
+	config.OverridesFile = filepath.Join(t.TempDir(), "overrides.yaml")
+	writeOverrides(t, config, `
+settings:
+  work_mem: 8MB
+  max_connections: "200"
+hba:
+  - type: host
+    database: all
+    user: app
+    address: 10.0.0.0/8
+    method: scram-sha-256
+  - type: local
+    database: all
+    user: all
+    method: scram-sha-256
+`)
+	client := newClient(t, newManager(t, config))
+	ctx := context.Background()
+	_, err := client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.NoError(t, err)
+
+	conf := readFile(t, config.DataDir, "postgresql.conf")
+	require.Contains(t, conf, "port = '5433'\n")
+	require.Contains(t, conf, "unix_socket_directories = '"+config.DataDir+"'\n")
+	require.Contains(t, conf, "work_mem = '8MB'\n")
+	require.Contains(t, conf, "max_connections = '200'\n")
+	require.Contains(t, conf, "shared_buffers = '128MB'\n")
+	hba := readFile(t, config.DataDir, "pg_hba.conf")
+	require.Contains(t, hba, "local  all  \"postgres\"  trust\n")
+	require.NotContains(t, hba, "local  all  all  trust")
+	require.Contains(t, hba, "host  all  app  10.0.0.0/8  scram-sha-256\n")
+	require.Less(t,
+		strings.Index(hba, "host  all  app  10.0.0.0/8"),
+		strings.Index(hba, "host  all  all  127.0.0.1/32"),
+		"the rules of the overrides come first")
+	// Even the local ones, after the trust of pgctld only.
+	require.Less(t,
+		strings.Index(hba, "local  all  \"postgres\"  trust"),
+		strings.Index(hba, "local  all  all  scram-sha-256"))
+
+	// The server listens on the port of postgresql.conf.
+	startResp, err := client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(5433), startResp.Status.Port)
+
+	// ReloadConfig writes the files again with the new overrides, and
+	// keeps the previous versions.
+	writeOverrides(t, config, "settings:\n  work_mem: 16MB\n")
+	_, err = client.ReloadConfig(ctx, &pgctldatapb.ReloadConfigRequest{})
+	require.NoError(t, err)
+	require.Contains(t, readFile(t, config.DataDir, "postgresql.conf"), "work_mem = '16MB'\n")
+	require.NotContains(t, readFile(t, config.DataDir, "pg_hba.conf"), "app")
+	// The version of initdb is kept too.
+	backups, err := filepath.Glob(filepath.Join(stateDir(config), "config_backups", "postgresql.conf.*"))
+	require.NoError(t, err)
+	require.Len(t, backups, 2)
+	backups, err = filepath.Glob(filepath.Join(stateDir(config), "config_backups", "pg_hba.conf.*"))
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+
+	// Invalid overrides are all reported, and change nothing.
+	writeOverrides(t, config, `
+settings:
+  no_such_setting: "1"
+  work_mem: lots
+  port: "6000"
+hba:
+  - type: host
+    database: all
+    user: all
+    address: 10.0.0.0/99
+    method: trust
+`)
+	_, err = client.ReloadConfig(ctx, &pgctldatapb.ReloadConfigRequest{})
+	require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+	require.ErrorContains(t, err, "unknown setting no_such_setting")
+	require.ErrorContains(t, err, `invalid value "lots" for setting work_mem`)
+	require.ErrorContains(t, err, "setting port is managed by pgctld")
+	require.ErrorContains(t, err, "invalid pg_hba.conf rule")
+	require.Contains(t, readFile(t, config.DataDir, "postgresql.conf"), "work_mem = '16MB'\n")
+	require.Equal(t, []string{"reload"}, readLines(t, config, "reloads"))
+}
+
+func TestSetConfig(t *testing.T) {
+	config := newConfig(t)
+	client := newClient(t, newManager(t, config))
+	ctx := context.Background()
+
+	_, err := client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{Settings: map[string]string{"work_mem": "8MB"}})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+	_, err = client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.NoError(t, err)
+
+	// Nothing is required while the server is stopped.
+	resp, err := client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{Settings: map[string]string{
+		"work_mem":          "8MB",
+		"statement_timeout": "30s",
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"statement_timeout", "work_mem"}, resp.ChangedSettings)
+	require.False(t, resp.ReloadRequired)
+	require.False(t, resp.RestartRequired)
+	require.Contains(t, readFile(t, config.DataDir, "postgresql.conf"), "work_mem = '8MB'\n")
+
+	_, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+
+	// A reload applies work_mem, a restart shared_buffers.
+	resp, err = client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{Settings: map[string]string{"work_mem": "16MB"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"work_mem"}, resp.ChangedSettings)
+	require.True(t, resp.ReloadRequired)
+	require.False(t, resp.RestartRequired)
+
+	resp, err = client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{Settings: map[string]string{
+		"work_mem":       "16MB",
+		"shared_buffers": "1GB",
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"shared_buffers"}, resp.ChangedSettings)
+	require.False(t, resp.ReloadRequired)
+	require.True(t, resp.RestartRequired)
+
+	// Setting the same values changes nothing.
+	resp, err = client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{Settings: map[string]string{"work_mem": "16MB"}})
+	require.NoError(t, err)
+	require.Empty(t, resp.ChangedSettings)
+	require.False(t, resp.ReloadRequired)
+
+	// Invalid settings change nothing.
+	_, err = client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{
+		Settings: map[string]string{
+			"work_mem":    "32MB",
+			"hot_standby": "maybe",
+		},
+		ResetSettings: []string{"no_such_setting"},
+	})
+	require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+	require.ErrorContains(t, err, "hot_standby")
+	require.ErrorContains(t, err, "no_such_setting")
+	require.Contains(t, readFile(t, config.DataDir, "postgresql.conf"), "work_mem = '16MB'\n")
+
+	// The settings of the WAL archive, which the backups need, are pinned.
+	for name, value := range map[string]string{"archive_mode": "off", "archive_command": "true", "wal_level": "minimal"} {
+		_, err = client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{Settings: map[string]string{name: value}})
+		require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err), "%v", name)
+	}
+
+	// The settings are only saved once the files are rendered.
+	settings := readFile(t, config.DataDir, "pgctld_settings.yaml")
+	hba := filepath.Join(config.DataDir, "pg_hba.conf")
+	require.NoError(t, os.Rename(hba, hba+".saved"))
+	require.NoError(t, os.Mkdir(hba, 0o700))
+	_, err = client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{Settings: map[string]string{"work_mem": "64MB"}})
+	require.Error(t, err)
+	require.Equal(t, settings, readFile(t, config.DataDir, "pgctld_settings.yaml"))
+	require.NoError(t, os.Remove(hba))
+	require.NoError(t, os.Rename(hba+".saved", hba))
+
+	// The settings are kept across restarts, until they are reset.
+	_, err = client.Restart(ctx, &pgctldatapb.RestartRequest{})
+	require.NoError(t, err)
+	require.Contains(t, readFile(t, config.DataDir, "postgresql.conf"), "shared_buffers = '1GB'\n")
+	resp, err = client.SetConfig(ctx, &pgctldatapb.SetConfigRequest{ResetSettings: []string{"shared_buffers", "statement_timeout"}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"shared_buffers", "statement_timeout"}, resp.ChangedSettings)
+	require.True(t, resp.RestartRequired)
+	conf := readFile(t, config.DataDir, "postgresql.conf")
+	require.Contains(t, conf, "shared_buffers = '128MB'\n")
+	require.NotContains(t, conf, "statement_timeout")
+}
+
+func TestConfigBackups(t *testing.T) {
+	config := newConfig(t)
+	manager := newManager(t, config)
+	ctx := context.Background()
+	_, err := manager.InitDataDir(ctx)
+	require.NoError(t, err)
+
+	for i := range 15 {
+		_, err := manager.SetConfig(ctx, map[string]string{"work_mem": strconv.Itoa(i+1) + "MB"}, nil)
+		require.NoError(t, err)
+	}
+	backups, err := filepath.Glob(filepath.Join(stateDir(config), "config_backups", "postgresql.conf.*"))
+	require.NoError(t, err)
+	require.Len(t, backups, 10)
+	// The newest backup is the version before the last change.
+	require.Contains(t, readFile(t, filepath.Dir(backups[9]), filepath.Base(backups[9])), "work_mem = '14MB'\n")
+
+	// pg_hba.conf didn't change.
+	backups, err = filepath.Glob(filepath.Join(stateDir(config), "config_backups", "pg_hba.conf.*"))
+	require.NoError(t, err)
+	require.Empty(t, backups)
+}
@@ -0,0 +1,159 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// Executor runs SQL on the local server.
+type Executor interface {
+	// ExecuteFetch runs query, with args as the text values of its
+	// parameters $1, $2..., and returns its result. It fails if the query
+	// returns more than maxRows rows, and its changes are rolled back.
+	ExecuteFetch(ctx context.Context, query string, maxRows int, args ...string) (*Result, error)
+}
+
+// Field describes a column of a Result.
+type Field struct {
+	Name string
+
+	// TypeOID is the OID of the type of the column, in pg_type.
+	TypeOID uint32
+}
+
+// Result is the result of a query.
+type Result struct {
+	Fields []Field
+
+	// Rows are the values of the rows, in the text format of PostgreSQL.
+	// NULL values are nil.
+	Rows [][][]byte
+
+	// RowsAffected is the number of rows returned or changed by the query.
+	RowsAffected int64
+}
+
+// socketExecutor runs SQL as the superuser, through the Unix socket of the
+// server in its data directory. pg_hba.conf trusts these connections.
+type socketExecutor struct {
+	config Config
+}
+
+// connString returns the connection string of the server of config,
+// through its Unix socket.
+func connString(config Config) string {
+	quote := func(s string) string {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+	}
+	return fmt.Sprintf("host=%v port=%d user=%v dbname=postgres sslmode=disable application_name=pgctld",
+		quote(config.DataDir), config.Port, quote(config.User))
+}
+
+// ExecuteFetch is part of the Executor interface. It opens a connection for
+// each query: pgctld runs few queries. The query runs in a transaction,
+// committed unless the query fails or returns more than maxRows rows: the
+// changes of the queries that fail are always rolled back. The statements
+// that can't run in a transaction, which return no rows, run alone.
+func (e *socketExecutor) ExecuteFetch(ctx context.Context, query string, maxRows int, args ...string) (*Result, error) {
+	conn, err := pgconn.Connect(ctx, connString(e.config))
+	if err != nil {
+		return nil, mterrors.Errorf(mtrpcpb.Code_UNAVAILABLE, "failed to connect to postgres: %v", err)
+	}
+	// Closing the connection rolls back the transaction, if still open.
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	if err := conn.Exec(ctx, "BEGIN").Close(); err != nil {
+		return nil, convertError(err)
+	}
+	result, err := fetch(ctx, conn, query, maxRows, args)
+	if mterrors.ErrState(err) == mterrors.ActiveSQLTransaction {
+		if err := conn.Exec(ctx, "ROLLBACK").Close(); err != nil {
+			return nil, convertError(err)
+		}
+		return fetch(ctx, conn, query, maxRows, args)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.Exec(ctx, "COMMIT").Close(); err != nil {
+		return nil, convertError(err)
+	}
+	return result, nil
+}
+
+// fetch runs query on conn, and returns its result. If the query returns
+// more than maxRows rows, it is cancelled, and its changes may have been
+// made.
+func fetch(ctx context.Context, conn *pgconn.PgConn, query string, maxRows int, args []string) (*Result, error) {
+	params := make([][]byte, len(args))
+	for i, arg := range args {
+		params[i] = []byte(arg)
+	}
+	reader := conn.ExecParams(ctx, query, params, nil, nil, nil)
+	result := &Result{}
+	for _, field := range reader.FieldDescriptions() {
+		result.Fields = append(result.Fields, Field{Name: field.Name, TypeOID: field.DataTypeOID})
+	}
+	for reader.NextRow() {
+		if len(result.Rows) >= maxRows {
+			// Cancel the query, rather than read the rest of its rows.
+			_ = conn.CancelRequest(ctx)
+			_, _ = reader.Close()
+			return nil, mterrors.Errorf(mtrpcpb.Code_RESOURCE_EXHAUSTED, "query returned more than %v rows", maxRows)
+		}
+		// The values are only valid until the next row.
+		row := make([][]byte, len(reader.Values()))
+		for i, value := range reader.Values() {
+			if value != nil {
+				row[i] = append([]byte{}, value...)
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	tag, err := reader.Close()
+	if err != nil {
+		return nil, convertError(err)
+	}
+	result.RowsAffected = tag.RowsAffected()
+	return result, nil
+}
+
+// convertError returns err with the code and the state of its SQLSTATE, if
+// it is an error of the server.
+func convertError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return mterrors.FromErrorResponse(&mterrors.ErrorResponse{
+			Severity: pgErr.Severity,
+			Code:     pgErr.Code,
+			Message:  pgErr.Message,
+			Detail:   pgErr.Detail,
+			Hint:     pgErr.Hint,
+		})
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return mterrors.Errorf(mtrpcpb.Code_DEADLINE_EXCEEDED, "query timed out: %v", err)
+	}
+	return mterrors.Wrap(err, "query failed")
+}
@@ -0,0 +1,478 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pgctld manages the local PostgreSQL server of a multipooler, and
+// exposes it as the PgCtld gRPC service.
+//
+// The Manager runs initdb and postgres directly, like pg_ctl does: the
+// stop modes are the shutdown signals of the postmaster, and the state of
+// the server is read from its postmaster.pid file. The server keeps
+// running if pgctld restarts, and a restarted pgctld manages it again.
+package pgctld
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+)
+
+const (
+	// pidFile is the lock file written by the postmaster in the data
+	// directory. See src/include/miscadmin.h for its format.
+	pidFile = "postmaster.pid"
+
+	// versionFile holds the major version of the data directory.
+	versionFile = "PG_VERSION"
+
+	// logFile receives the output of the server, in the state directory.
+	logFile = "postgres.log"
+
+	// stateDirSuffix is appended to the data directory to name the default
+	// state directory.
+	stateDirSuffix = ".pgctld"
+
+	// pollInterval is the interval at which the state of the server is
+	// checked while it starts or stops.
+	pollInterval = 50 * time.Millisecond
+)
+
+// stopSignals are the signals requesting the shutdown modes of the
+// postmaster.
+var stopSignals = map[pgctldatapb.StopMode]syscall.Signal{
+	pgctldatapb.StopMode_SMART:     syscall.SIGTERM,
+	pgctldatapb.StopMode_FAST:      syscall.SIGINT,
+	pgctldatapb.StopMode_IMMEDIATE: syscall.SIGQUIT,
+}
+
+// Config configures a Manager.
+type Config struct {
+	// BinDir is the directory of the PostgreSQL binaries. They are looked
+	// up in the PATH if empty.
+	BinDir string
+
+	// DataDir is the data directory of the server.
+	DataDir string
+
+	// StateDir holds the files of pgctld about the server: the WAL archive,
+	// the server log and the previous versions of the configuration files.
+	// It is out of the data directory, so that the backups don't copy them.
+	// <DataDir>.pgctld if empty.
+	StateDir string
+
+	// Port is the port the server listens on.
+	Port int
+
+	// User is the name of the superuser created by InitDataDir.
+	User string
+
+	// Password is the password of the superuser. If empty, the superuser
+	// has no password.
+	Password string
+
+	// OverridesFile is the path of the YAML file overriding the settings
+	// of postgresql.conf and adding rules to pg_hba.conf. See config.go.
+	OverridesFile string
+
+	// Executor runs the SQL of the replication operations. If nil, the
+	// Manager connects to the server through its Unix socket.
+	Executor Executor
+
+	// Timeout bounds the wait for the server to start or stop, if the
+	// context of the call has no earlier deadline.
+	Timeout time.Duration
+}
+
+// Manager manages the lifecycle of a local PostgreSQL server.
+type Manager struct {
+	config   Config
+	executor Executor
+
+	// mu serializes the lifecycle operations.
+	mu sync.Mutex
+
+	// backupMu is held while a backup or a restore runs. mu isn't: the
+	// server may be stopped during a backup, which then fails, and a
+	// restore sets restoring instead, to keep the data directory while it
+	// downloads the backup.
+	backupMu  sync.Mutex
+	restoring bool
+
+	// walMu serializes the shipping of the archived WAL, and the changes
+	// of its target.
+	walMu sync.Mutex
+}
+
+// NewManager returns a Manager of the server configured by config.
+func NewManager(config Config) *Manager {
+	if config.StateDir == "" && config.DataDir != "" {
+		config.StateDir = filepath.Clean(config.DataDir) + stateDirSuffix
+	}
+	m := &Manager{
+		config:   config,
+		executor: config.Executor,
+	}
+	if m.executor == nil {
+		m.executor = &socketExecutor{config: config}
+	}
+	return m
+}
+
+// binary returns the path of the PostgreSQL binary name.
+func (m *Manager) binary(name string) string {
+	if m.config.BinDir == "" {
+		return name
+	}
+	return filepath.Join(m.config.BinDir, name)
+}
+
+// checkDataDir returns an error if the data directory is not configured.
+func (m *Manager) checkDataDir() error {
+	if m.config.DataDir == "" {
+		return mterrors.New(mtrpcpb.Code_FAILED_PRECONDITION, "the data directory is not configured")
+	}
+	return nil
+}
+
+// InitDataDir initializes the data directory with initdb.
+func (m *Manager) InitDataDir(ctx context.Context) (*pgctldatapb.PostgresStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkNotRestoring(); err != nil {
+		return nil, err
+	}
+	status, err := m.status()
+	if err != nil {
+		return nil, err
+	}
+	if status.Initialized {
+		return nil, mterrors.Errorf(mtrpcpb.Code_ALREADY_EXISTS, "data directory %v is already initialized", m.config.DataDir)
+	}
+
+	args := []string{"-D", m.config.DataDir, "-U", m.config.User}
+	if m.config.Password != "" {
+		pwfile, err := os.CreateTemp("", "pgctld-pwfile")
+		if err != nil {
+			return nil, mterrors.Wrap(err, "failed to create the password file")
+		}
+		defer os.Remove(pwfile.Name())
+		_, err = pwfile.WriteString(m.config.Password + "\n")
+		if closeErr := pwfile.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, mterrors.Wrap(err, "failed to write the password file")
+		}
+		args = append(args, "--pwfile", pwfile.Name())
+	}
+
+	slog.Info("Initializing the data directory", "data_dir", m.config.DataDir)
+	cmd := exec.CommandContext(ctx, m.binary("initdb"), args...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return nil, mterrors.Wrapf(err, "initdb failed: %s", strings.TrimSpace(string(output)))
+	}
+	if _, err := m.updateConfig(); err != nil {
+		return nil, err
+	}
+	return m.status()
+}
+
+// Start starts the server, and waits until it accepts connections. It
+// stops the server if it doesn't in time, and does nothing if the server
+// is already running.
+func (m *Manager) Start(ctx context.Context) (*pgctldatapb.PostgresStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkNotRestoring(); err != nil {
+		return nil, err
+	}
+	return m.start(ctx)
+}
+
+// start starts the server like Start, with mu held.
+func (m *Manager) start(ctx context.Context) (*pgctldatapb.PostgresStatus, error) {
+	status, exited, err := m.launch()
+	if err != nil || exited == nil {
+		return status, err
+	}
+	waitCtx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	status, err = m.waitReady(waitCtx, exited)
+	if mterrors.Code(err) == mtrpcpb.Code_DEADLINE_EXCEEDED {
+		if _, stopErr := m.stop(context.WithoutCancel(ctx), pgctldatapb.StopMode_FAST); stopErr != nil {
+			return nil, mterrors.Wrapf(err, "failed to stop postgres after the start timeout: %v", stopErr)
+		}
+		return nil, mterrors.Wrap(err, "stopped postgres")
+	}
+	return status, err
+}
+
+// launch starts the postmaster, and returns the channel receiving its
+// exit. It returns the status of the server and a nil channel if the
+// server is already running. mu must be held.
+func (m *Manager) launch() (*pgctldatapb.PostgresStatus, <-chan error, error) {
+	status, err := m.status()
+	if err != nil {
+		return nil, nil, err
+	}
+	if !status.Initialized {
+		return nil, nil, mterrors.Errorf(mtrpcpb.Code_FAILED_PRECONDITION, "data directory %v is not initialized", m.config.DataDir)
+	}
+	if status.State == pgctldatapb.ServerState_RUNNING {
+		return status, nil, nil
+	}
+	if _, err := m.updateConfig(); err != nil {
+		return nil, nil, err
+	}
+	// The archive_command copies the WAL there.
+	if err := os.MkdirAll(filepath.Join(m.config.StateDir, walArchiveDir), 0o700); err != nil {
+		return nil, nil, mterrors.Wrap(err, "failed to create the WAL archive directory")
+	}
+
+	log, err := os.OpenFile(filepath.Join(m.config.StateDir, logFile), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, nil, mterrors.Wrap(err, "failed to open the server log")
+	}
+	defer log.Close()
+
+	slog.Info("Starting postgres", "data_dir", m.config.DataDir, "port", m.config.Port)
+	// The server must outlive the call, and must not receive the signals
+	// sent to the process group of pgctld.
+	cmd := exec.Command(m.binary("postgres"), "-D", m.config.DataDir)
+	cmd.Stdout = log
+	cmd.Stderr = log
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, mterrors.Wrap(err, "failed to start postgres")
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	return nil, exited, nil
+}
+
+// waitReady waits until the server started by launch accepts
+// connections, or exits, or ctx is done.
+func (m *Manager) waitReady(ctx context.Context, exited <-chan error) (*pgctldatapb.PostgresStatus, error) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-exited:
+			return nil, mterrors.Errorf(mtrpcpb.Code_INTERNAL, "postgres exited during startup (%v), see %v", err, filepath.Join(m.config.StateDir, logFile))
+		case <-ctx.Done():
+			return nil, mterrors.Errorf(mtrpcpb.Code_DEADLINE_EXCEEDED, "postgres did not accept connections in time: %v", ctx.Err())
+		case <-ticker.C:
+			status, err := m.status()
+			if err != nil {
+				return nil, err
+			}
+			if status.Ready {
+				slog.Info("Started postgres", "pid", status.Pid)
+				return status, nil
+			}
+		}
+	}
+}
+
+// Stop stops the server in mode, and waits until it exits. It does
+// nothing if the server is not running.
+func (m *Manager) Stop(ctx context.Context, mode pgctldatapb.StopMode) (*pgctldatapb.PostgresStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stop(ctx, mode)
+}
+
+func (m *Manager) stop(ctx context.Context, mode pgctldatapb.StopMode) (*pgctldatapb.PostgresStatus, error) {
+	signal, ok := stopSignals[mode]
+	if !ok {
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "unknown stop mode %v", mode)
+	}
+	status, err := m.status()
+	if err != nil {
+		return nil, err
+	}
+	if status.State != pgctldatapb.ServerState_RUNNING {
+		return status, nil
+	}
+
+	slog.Info("Stopping postgres", "pid", status.Pid, "mode", mode.String())
+	if err := syscall.Kill(int(status.Pid), signal); err != nil && !errors.Is(err, syscall.ESRCH) {
+		return nil, mterrors.Wrapf(err, "failed to signal postgres (pid %v)", status.Pid)
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, mterrors.Errorf(mtrpcpb.Code_DEADLINE_EXCEEDED, "postgres (pid %v) did not stop in time: %v", status.Pid, ctx.Err())
+		case <-ticker.C:
+			status, err := m.status()
+			if err != nil {
+				return nil, err
+			}
+			if status.State == pgctldatapb.ServerState_STOPPED {
+				slog.Info("Stopped postgres")
+				return status, nil
+			}
+		}
+	}
+}
+
+// Restart stops the server in mode if it is running, then starts it.
+func (m *Manager) Restart(ctx context.Context, mode pgctldatapb.StopMode) (*pgctldatapb.PostgresStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkNotRestoring(); err != nil {
+		return nil, err
+	}
+	if _, err := m.stop(ctx, mode); err != nil {
+		return nil, err
+	}
+	return m.start(ctx)
+}
+
+// ReloadConfig writes the configuration files again, with the current
+// overrides, and makes the running server reload them.
+func (m *Manager) ReloadConfig(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, err := m.status()
+	if err != nil {
+		return err
+	}
+	if status.State != pgctldatapb.ServerState_RUNNING {
+		return mterrors.New(mtrpcpb.Code_FAILED_PRECONDITION, "postgres is not running")
+	}
+	if _, err := m.updateConfig(); err != nil {
+		return err
+	}
+	return reload(status)
+}
+
+// reload makes the running server of status reload its configuration.
+func reload(status *pgctldatapb.PostgresStatus) error {
+	slog.Info("Reloading the postgres configuration", "pid", status.Pid)
+	if err := syscall.Kill(int(status.Pid), syscall.SIGHUP); err != nil {
+		return mterrors.Wrapf(err, "failed to signal postgres (pid %v)", status.Pid)
+	}
+	return nil
+}
+
+// Status returns the status of the server.
+func (m *Manager) Status(ctx context.Context) (*pgctldatapb.PostgresStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status()
+}
+
+// status reads the status of the server from its data directory.
+func (m *Manager) status() (*pgctldatapb.PostgresStatus, error) {
+	if err := m.checkDataDir(); err != nil {
+		return nil, err
+	}
+	status := &pgctldatapb.PostgresStatus{
+		State:   pgctldatapb.ServerState_STOPPED,
+		DataDir: m.config.DataDir,
+	}
+	version, err := os.ReadFile(filepath.Join(m.config.DataDir, versionFile))
+	switch {
+	case err == nil:
+		status.Initialized = true
+		status.Version = strings.TrimSpace(string(version))
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, mterrors.Wrap(err, "failed to read the version of the data directory")
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.config.DataDir, pidFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return status, nil
+	}
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to read the postmaster.pid file")
+	}
+	// The lines are the pid, the data directory, the start time, the port,
+	// the socket directory, the listen address, the shared memory key and
+	// the status of the postmaster. The file may be partially written.
+	lines := strings.Split(string(data), "\n")
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil || !m.isPostmaster(pid) {
+		// A stale file, left by a server that crashed.
+		return status, nil
+	}
+	status.State = pgctldatapb.ServerState_RUNNING
+	status.Pid = int32(pid)
+	if len(lines) > 2 {
+		if start, err := strconv.ParseInt(strings.TrimSpace(lines[2]), 10, 64); err == nil {
+			startTime := time.Unix(start, 0)
+			status.StartTime = timestamppb.New(startTime)
+			status.Uptime = durationpb.New(time.Since(startTime).Truncate(time.Second))
+		}
+	}
+	if len(lines) > 3 {
+		if port, err := strconv.Atoi(strings.TrimSpace(lines[3])); err == nil {
+			status.Port = int32(port)
+		}
+	}
+	if len(lines) > 7 {
+		// A hot standby accepts the connections once consistent.
+		switch strings.TrimSpace(lines[7]) {
+		case "ready", "standby":
+			status.Ready = true
+		}
+	}
+	return status, nil
+}
+
+// withTimeout returns ctx bounded by the configured timeout.
+func (m *Manager) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.config.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, m.config.Timeout)
+}
+
+// isPostmaster returns true if the process pid may be the postmaster of
+// the data directory: a process of the user of pgctld, which starts the
+// server, whose working directory is the data directory, where the
+// postmaster moves, if /proc tells. Otherwise, the pid of a crashed
+// postmaster was reused.
+func (m *Manager) isPostmaster(pid int) bool {
+	if pid <= 0 || syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	cwd, err := os.Readlink(fmt.Sprintf("/proc/%d/cwd", pid))
+	if err != nil {
+		return true
+	}
+	dataDir, err := filepath.EvalSymlinks(m.config.DataDir)
+	return err != nil || cwd == dataDir
+}
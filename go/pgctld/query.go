@@ -0,0 +1,182 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	querypb "github.com/multigres/multigres/go/pb/query"
+	"github.com/multigres/multigres/go/stats"
+	"github.com/multigres/multigres/go/tracing"
+)
+
+const (
+	// DefaultMaxRows is the maximum number of rows of ExecuteFetch, if
+	// the request has none.
+	DefaultMaxRows = 10000
+
+	// DefaultQueryTimeout bounds the queries of ExecuteFetch, if the
+	// request has no timeout.
+	DefaultQueryTimeout = 30 * time.Second
+)
+
+var (
+	queryTimings = stats.NewMultiTimings(
+		"Queries",
+		"Timings of the queries run on the local server",
+		[]string{"Operation"})
+
+	queryErrors = stats.NewCountersWithMultiLabels(
+		"QueryErrors",
+		"Errors of the queries run on the local server",
+		[]string{"Operation", "Code"})
+)
+
+// types maps the OIDs of the PostgreSQL types, in pg_type, to their Type.
+var types = map[uint32]querypb.Type{
+	16:   querypb.Type_BOOL,
+	17:   querypb.Type_BYTEA,
+	18:   querypb.Type_CHAR,
+	19:   querypb.Type_NAME,
+	20:   querypb.Type_INT8,
+	21:   querypb.Type_INT2,
+	23:   querypb.Type_INT4,
+	25:   querypb.Type_TEXT,
+	26:   querypb.Type_OID,
+	114:  querypb.Type_JSON,
+	142:  querypb.Type_XML,
+	650:  querypb.Type_CIDR,
+	700:  querypb.Type_FLOAT4,
+	701:  querypb.Type_FLOAT8,
+	869:  querypb.Type_INET,
+	1042: querypb.Type_BPCHAR,
+	1043: querypb.Type_VARCHAR,
+	1082: querypb.Type_DATE,
+	1083: querypb.Type_TIME,
+	1114: querypb.Type_TIMESTAMP,
+	1184: querypb.Type_TIMESTAMPTZ,
+	1186: querypb.Type_INTERVAL,
+	1266: querypb.Type_TIMETZ,
+	1700: querypb.Type_NUMERIC,
+	2950: querypb.Type_UUID,
+	3220: querypb.Type_PG_LSN,
+	3802: querypb.Type_JSONB,
+}
+
+// operation returns the first keyword of query, in upper case, to name
+// the query in the spans and the stats without its values.
+func operation(query string) string {
+	query = strings.TrimLeftFunc(query, unicode.IsSpace)
+	end := strings.IndexFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if end >= 0 {
+		query = query[:end]
+	}
+	if query == "" {
+		return "UNKNOWN"
+	}
+	return strings.ToUpper(query)
+}
+
+// execute runs query with the executor, in a span, and records its timing
+// and its error.
+func (m *Manager) execute(ctx context.Context, query string, maxRows int, args ...string) (result *Result, err error) {
+	op := operation(query)
+	start := time.Now()
+	ctx, span := tracing.StartSpan(ctx, "pgctld.Query",
+		attribute.String("db.system", "postgresql"),
+		attribute.String("db.operation.name", op))
+	defer func() {
+		queryTimings.Record([]string{op}, start)
+		if err != nil {
+			queryErrors.Add([]string{op, mterrors.Code(err).String()}, 1)
+		}
+		tracing.EndSpan(span, err)
+	}()
+	return m.executor.ExecuteFetch(ctx, query, maxRows, args...)
+}
+
+// ExecuteFetch runs query, a single statement, on the server as the
+// superuser, and returns its result. The query fails if it returns more
+// than maxRows rows, or runs longer than timeout. maxRows and timeout
+// default to DefaultMaxRows and DefaultQueryTimeout.
+func (m *Manager) ExecuteFetch(ctx context.Context, query string, maxRows int, timeout time.Duration) (*querypb.QueryResult, error) {
+	switch {
+	case strings.TrimSpace(query) == "":
+		return nil, mterrors.New(mtrpcpb.Code_INVALID_ARGUMENT, "empty query")
+	case maxRows < 0:
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "invalid max rows %v", maxRows)
+	case timeout < 0:
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "invalid timeout %v", timeout)
+	}
+	if maxRows == 0 {
+		maxRows = DefaultMaxRows
+	}
+	if timeout == 0 {
+		timeout = DefaultQueryTimeout
+	}
+
+	// The lock isn't held during the query: the administrative queries
+	// may be long, and must not block the lifecycle operations.
+	m.mu.Lock()
+	err := m.checkReady()
+	m.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := m.execute(ctx, query, maxRows)
+	if err != nil {
+		return nil, err
+	}
+	return result.toProto(), nil
+}
+
+// toProto returns the result as a QueryResult.
+func (r *Result) toProto() *querypb.QueryResult {
+	qr := &querypb.QueryResult{
+		RowsAffected: uint64(r.RowsAffected),
+	}
+	for _, field := range r.Fields {
+		qr.Fields = append(qr.Fields, &querypb.Field{
+			Name:    field.Name,
+			Type:    types[field.TypeOID],
+			TypeOid: field.TypeOID,
+		})
+	}
+	for _, values := range r.Rows {
+		row := &querypb.Row{}
+		for _, value := range values {
+			if value == nil {
+				row.Lengths = append(row.Lengths, -1)
+				continue
+			}
+			row.Lengths = append(row.Lengths, int64(len(value)))
+			row.Values = append(row.Values, value...)
+		}
+		qr.Rows = append(qr.Rows, row)
+	}
+	return qr
+}
@@ -0,0 +1,134 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	querypb "github.com/multigres/multigres/go/pb/query"
+	"github.com/multigres/multigres/go/pgctld"
+	"github.com/multigres/multigres/go/tracing"
+)
+
+func TestExecuteFetch(t *testing.T) {
+	executor, client := newReplicationClient(t)
+	ctx := context.Background()
+
+	roles := executor.on("FROM pg_roles", []string{"postgres", "t", "NULL"}, []string{"app", "f", "2030-01-01 00:00:00+00"})
+	roles.Fields = []pgctld.Field{
+		{Name: "rolname", TypeOID: 19},
+		{Name: "rolsuper", TypeOID: 16},
+		{Name: "rolvaliduntil", TypeOID: 1184},
+	}
+	roles.RowsAffected = 2
+	resp, err := client.ExecuteFetch(ctx, &pgctldatapb.ExecuteFetchRequest{
+		Query: "SELECT rolname, rolsuper, rolvaliduntil FROM pg_roles",
+	})
+	require.NoError(t, err)
+	expected := &querypb.QueryResult{
+		Fields: []*querypb.Field{
+			{Name: "rolname", Type: querypb.Type_NAME, TypeOid: 19},
+			{Name: "rolsuper", Type: querypb.Type_BOOL, TypeOid: 16},
+			{Name: "rolvaliduntil", Type: querypb.Type_TIMESTAMPTZ, TypeOid: 1184},
+		},
+		RowsAffected: 2,
+		Rows: []*querypb.Row{
+			{Lengths: []int64{8, 1, -1}, Values: []byte("postgrest")},
+			{Lengths: []int64{3, 1, 22}, Values: []byte("appf2030-01-01 00:00:00+00")},
+		},
+	}
+	require.True(t, proto.Equal(expected, resp.Result), "got %v", resp.Result)
+
+	// The defaults bound the query.
+	require.Equal(t, pgctld.DefaultMaxRows, executor.maxRows)
+	require.WithinDuration(t, time.Now().Add(pgctld.DefaultQueryTimeout), executor.deadline, 5*time.Second)
+
+	// The types without a Type are unknown.
+	executor.on("ARRAY", []string{"{1,2}"}).Fields = []pgctld.Field{{Name: "array", TypeOID: 1007}}
+	resp, err = client.ExecuteFetch(ctx, &pgctldatapb.ExecuteFetchRequest{
+		Query:   "SELECT ARRAY[1, 2]",
+		MaxRows: 1,
+		Timeout: durationpb.New(time.Hour),
+	})
+	require.NoError(t, err)
+	require.Equal(t, querypb.Type_UNKNOWN, resp.Result.Fields[0].Type)
+	require.Equal(t, uint32(1007), resp.Result.Fields[0].TypeOid)
+	require.Equal(t, 1, executor.maxRows)
+	require.WithinDuration(t, time.Now().Add(time.Hour), executor.deadline, 5*time.Second)
+
+	// The errors of the server are returned.
+	executor.fail("DROP", mterrors.NewPGError(mterrors.UndefinedTable, `table "t" does not exist`, "", ""))
+	_, err = client.ExecuteFetch(ctx, &pgctldatapb.ExecuteFetchRequest{Query: "DROP TABLE t"})
+	require.Equal(t, mtrpcpb.Code_NOT_FOUND, mterrors.Code(err))
+	require.ErrorContains(t, err, `table "t" does not exist`)
+
+	for _, req := range []*pgctldatapb.ExecuteFetchRequest{
+		{Query: " "},
+		{Query: "SELECT 1", MaxRows: -1},
+		{Query: "SELECT 1", Timeout: durationpb.New(-time.Second)},
+	} {
+		_, err = client.ExecuteFetch(ctx, req)
+		require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err), "%v", req)
+	}
+
+	_, err = client.Stop(ctx, &pgctldatapb.StopRequest{})
+	require.NoError(t, err)
+	_, err = client.ExecuteFetch(ctx, &pgctldatapb.ExecuteFetchRequest{Query: "SELECT 1"})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+}
+
+func TestQuerySpans(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracing.NewTracerProvider("test", sdktrace.WithSyncer(exporter), 1))
+	defer otel.SetTracerProvider(previous)
+
+	executor, client := newReplicationClient(t)
+	executor.fail("create role", mterrors.NewPGError(mterrors.InsufficientPrivilege, "permission denied", "", ""))
+	ctx := context.Background()
+	_, err := client.ExecuteFetch(ctx, &pgctldatapb.ExecuteFetchRequest{Query: "\n create role app password 'secret'"})
+	require.Error(t, err)
+	_, err = client.ReplicationStatus(ctx, &pgctldatapb.ReplicationStatusRequest{})
+	require.NoError(t, err)
+
+	// Every query is traced, named by its first keyword only.
+	var operations []string
+	for _, span := range exporter.GetSpans() {
+		require.Equal(t, "pgctld.Query", span.Name)
+		attrs := attribute.NewSet(span.Attributes...)
+		system, _ := attrs.Value("db.system")
+		require.Equal(t, "postgresql", system.AsString())
+		op, _ := attrs.Value("db.operation.name")
+		operations = append(operations, op.AsString())
+		if op.AsString() == "CREATE" {
+			require.Equal(t, codes.Error, span.Status.Code)
+		}
+	}
+	require.Equal(t, []string{"CREATE", "SELECT", "SELECT", "SELECT", "SELECT"}, operations)
+}
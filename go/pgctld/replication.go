@@ -0,0 +1,398 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+)
+
+// This file implements the replication operations. The settings of a
+// standby are written in postgresql.conf like the other settings, and the
+// other operations run the replication functions of the server. See the
+// "Replication" and "Recovery Control" sections of the PostgreSQL
+// documentation.
+
+const (
+	// standbySignalFile makes the server start as a standby.
+	standbySignalFile = "standby.signal"
+
+	// maxReplicationRows bounds the rows of the replication queries.
+	maxReplicationRows = 10000
+
+	// defaultPromoteWait is the time Promote waits for the promotion if
+	// no timeout is configured.
+	defaultPromoteWait = time.Minute
+)
+
+// slotName matches the valid names of replication slots.
+var slotName = regexp.MustCompile(`^[a-z0-9_]{1,63}$`)
+
+// validateSlotName returns an error if name is not a valid replication
+// slot name.
+func validateSlotName(name string) error {
+	if !slotName.MatchString(name) {
+		return mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "invalid replication slot name %q: it must have 1 to 63 lower case letters, digits or underscores", name)
+	}
+	return nil
+}
+
+// checkReady returns an error if the server doesn't accept connections.
+func (m *Manager) checkReady() error {
+	status, err := m.status()
+	if err != nil {
+		return err
+	}
+	if status.State != pgctldatapb.ServerState_RUNNING || !status.Ready {
+		return mterrors.New(mtrpcpb.Code_FAILED_PRECONDITION, "postgres is not running")
+	}
+	return nil
+}
+
+// query runs query on the server, bounded by the configured timeout.
+func (m *Manager) query(ctx context.Context, query string, args ...string) (*Result, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	return m.execute(ctx, query, maxReplicationRows, args...)
+}
+
+// queryRow runs query, and returns its single row.
+func (m *Manager) queryRow(ctx context.Context, query string, args ...string) ([][]byte, error) {
+	result, err := m.query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Rows) != 1 {
+		return nil, mterrors.Errorf(mtrpcpb.Code_INTERNAL, "query %q returned %v rows, expected 1", query, len(result.Rows))
+	}
+	return result.Rows[0], nil
+}
+
+// inRecovery returns true if the server is a standby.
+func (m *Manager) inRecovery(ctx context.Context) (bool, error) {
+	row, err := m.queryRow(ctx, "SELECT pg_is_in_recovery()")
+	if err != nil {
+		return false, err
+	}
+	return parseBool(row[0]), nil
+}
+
+// SetPrimaryConnInfo sets the primary_conninfo and primary_slot_name
+// settings, and makes the running server reload them. The server becomes a
+// standby of the primary, at its next start if it runs as a primary: it
+// returns true if the server must restart. conninfo is required: a standby
+// without a primary stops being one with Promote.
+func (m *Manager) SetPrimaryConnInfo(ctx context.Context, conninfo, slot string) (bool, error) {
+	if conninfo == "" {
+		return false, mterrors.New(mtrpcpb.Code_INVALID_ARGUMENT, "the primary conninfo is required")
+	}
+	if slot != "" {
+		if err := validateSlotName(slot); err != nil {
+			return false, err
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status, err := m.status()
+	if err != nil {
+		return false, err
+	}
+	if !status.Initialized {
+		return false, mterrors.Errorf(mtrpcpb.Code_FAILED_PRECONDITION, "data directory %v is not initialized", m.config.DataDir)
+	}
+
+	settings := map[string]string{"primary_conninfo": conninfo}
+	// A standby replays all the WAL of its primary, whatever the target of
+	// the restore it comes from.
+	reset := []string{"recovery_target_lsn", "recovery_target_time", "recovery_target_action"}
+	if slot != "" {
+		settings["primary_slot_name"] = slot
+	} else {
+		reset = append(reset, "primary_slot_name")
+	}
+	resp, err := m.setConfig(status, settings, reset)
+	if err != nil {
+		return false, err
+	}
+
+	restartRequired := false
+	signalFile := filepath.Join(m.config.DataDir, standbySignalFile)
+	_, err = os.Stat(signalFile)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if err := os.WriteFile(signalFile, nil, 0o600); err != nil {
+			return false, mterrors.Wrap(err, "failed to write the standby signal file")
+		}
+		// A running server without the file is a primary.
+		restartRequired = status.State == pgctldatapb.ServerState_RUNNING
+	case err != nil:
+		return false, mterrors.Wrap(err, "failed to check the standby signal file")
+	}
+	if resp.ReloadRequired {
+		if err := reload(status); err != nil {
+			return false, err
+		}
+	}
+	slog.Info("Set the primary connection", "slot", slot, "restart_required", restartRequired)
+	return restartRequired, nil
+}
+
+// Promote promotes the standby to primary, and waits until it accepts
+// writes. It does nothing if the server is already a primary.
+func (m *Manager) Promote(ctx context.Context) (*pgctldatapb.ReplicationStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkReady(); err != nil {
+		return nil, err
+	}
+	inRecovery, err := m.inRecovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if inRecovery {
+		wait := m.config.Timeout
+		if wait <= 0 {
+			wait = defaultPromoteWait
+		}
+		slog.Info("Promoting postgres")
+		row, err := m.queryRow(ctx, "SELECT pg_promote(true, $1)", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		if err != nil {
+			return nil, err
+		}
+		if !parseBool(row[0]) {
+			return nil, mterrors.Errorf(mtrpcpb.Code_DEADLINE_EXCEEDED, "postgres was not promoted within %v", wait)
+		}
+	}
+	return m.replicationStatus(ctx)
+}
+
+// CreateReplicationSlot creates the physical replication slot name. The
+// slot reserves the WAL from its creation.
+func (m *Manager) CreateReplicationSlot(ctx context.Context, name string) error {
+	if err := validateSlotName(name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkReady(); err != nil {
+		return err
+	}
+	exists, err := m.slotExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return mterrors.Errorf(mtrpcpb.Code_ALREADY_EXISTS, "replication slot %v already exists", name)
+	}
+	if _, err := m.query(ctx, "SELECT pg_create_physical_replication_slot($1, true)", name); err != nil {
+		return err
+	}
+	slog.Info("Created replication slot", "slot", name)
+	return nil
+}
+
+// DropReplicationSlot drops the replication slot name.
+func (m *Manager) DropReplicationSlot(ctx context.Context, name string) error {
+	if err := validateSlotName(name); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkReady(); err != nil {
+		return err
+	}
+	exists, err := m.slotExists(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return mterrors.Errorf(mtrpcpb.Code_NOT_FOUND, "replication slot %v doesn't exist", name)
+	}
+	if _, err := m.query(ctx, "SELECT pg_drop_replication_slot($1)", name); err != nil {
+		return err
+	}
+	slog.Info("Dropped replication slot", "slot", name)
+	return nil
+}
+
+// slotExists returns true if the replication slot name exists.
+func (m *Manager) slotExists(ctx context.Context, name string) (bool, error) {
+	result, err := m.query(ctx, "SELECT 1 FROM pg_replication_slots WHERE slot_name = $1", name)
+	if err != nil {
+		return false, err
+	}
+	return len(result.Rows) > 0, nil
+}
+
+// PauseReplay pauses the replay of the WAL on the standby.
+func (m *Manager) PauseReplay(ctx context.Context) (*pgctldatapb.ReplicationStatus, error) {
+	return m.controlReplay(ctx, "SELECT pg_wal_replay_pause()")
+}
+
+// ResumeReplay resumes the replay of the WAL on the standby.
+func (m *Manager) ResumeReplay(ctx context.Context) (*pgctldatapb.ReplicationStatus, error) {
+	return m.controlReplay(ctx, "SELECT pg_wal_replay_resume()")
+}
+
+// controlReplay runs query, a recovery control function, on the standby.
+func (m *Manager) controlReplay(ctx context.Context, query string) (*pgctldatapb.ReplicationStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkReady(); err != nil {
+		return nil, err
+	}
+	inRecovery, err := m.inRecovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !inRecovery {
+		return nil, mterrors.New(mtrpcpb.Code_FAILED_PRECONDITION, "postgres is not a standby")
+	}
+	if _, err := m.query(ctx, query); err != nil {
+		return nil, err
+	}
+	return m.replicationStatus(ctx)
+}
+
+// ReplicationStatus returns the replication status of the server.
+func (m *Manager) ReplicationStatus(ctx context.Context) (*pgctldatapb.ReplicationStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkReady(); err != nil {
+		return nil, err
+	}
+	return m.replicationStatus(ctx)
+}
+
+// replicationStatus implements ReplicationStatus.
+func (m *Manager) replicationStatus(ctx context.Context) (*pgctldatapb.ReplicationStatus, error) {
+	inRecovery, err := m.inRecovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status := &pgctldatapb.ReplicationStatus{InRecovery: inRecovery}
+	if inRecovery {
+		row, err := m.queryRow(ctx, `SELECT pg_last_wal_receive_lsn(), pg_last_wal_replay_lsn(),
+  EXTRACT(EPOCH FROM pg_last_xact_replay_timestamp()), pg_is_wal_replay_paused()`)
+		if err != nil {
+			return nil, err
+		}
+		status.ReceiveLsn = string(row[0])
+		status.ReplayLsn = string(row[1])
+		if row[2] != nil {
+			lastReplay := time.UnixMicro(int64(parseFloat(row[2]) * 1e6))
+			status.LastReplayTime = timestamppb.New(lastReplay)
+			// Without new transactions on the primary, the last replay
+			// gets old, but the standby doesn't lag.
+			var lag time.Duration
+			if !caughtUp(status.ReceiveLsn, status.ReplayLsn) {
+				lag = max(time.Since(lastReplay), 0)
+			}
+			status.ReplayLag = durationpb.New(lag)
+		}
+		status.ReplayPaused = parseBool(row[3])
+
+		result, err := m.query(ctx, "SELECT status, sender_host, sender_port, slot_name FROM pg_stat_wal_receiver")
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Rows) > 0 {
+			row := result.Rows[0]
+			port, _ := strconv.Atoi(string(row[2]))
+			status.WalReceiver = &pgctldatapb.WalReceiver{
+				Status:      string(row[0]),
+				PrimaryHost: string(row[1]),
+				PrimaryPort: int32(port),
+				SlotName:    string(row[3]),
+			}
+		}
+	} else {
+		row, err := m.queryRow(ctx, "SELECT pg_current_wal_lsn()")
+		if err != nil {
+			return nil, err
+		}
+		status.CurrentLsn = string(row[0])
+	}
+
+	result, err := m.query(ctx, `SELECT application_name, client_addr, state, sent_lsn, write_lsn, flush_lsn, replay_lsn,
+  EXTRACT(EPOCH FROM replay_lag), sync_state FROM pg_stat_replication ORDER BY application_name`)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range result.Rows {
+		status.Standbys = append(status.Standbys, &pgctldatapb.StandbyStatus{
+			ApplicationName: string(row[0]),
+			ClientAddr:      string(row[1]),
+			State:           string(row[2]),
+			SentLsn:         string(row[3]),
+			WriteLsn:        string(row[4]),
+			FlushLsn:        string(row[5]),
+			ReplayLsn:       string(row[6]),
+			ReplayLag:       durationpb.New(time.Duration(parseFloat(row[7]) * float64(time.Second))),
+			SyncState:       string(row[8]),
+		})
+	}
+
+	result, err = m.query(ctx, "SELECT slot_name, slot_type, active, restart_lsn FROM pg_replication_slots ORDER BY slot_name")
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range result.Rows {
+		status.Slots = append(status.Slots, &pgctldatapb.ReplicationSlot{
+			Name:       string(row[0]),
+			Type:       string(row[1]),
+			Active:     parseBool(row[2]),
+			RestartLsn: string(row[3]),
+		})
+	}
+	return status, nil
+}
+
+// parseBool returns the value of a boolean in the text format.
+// caughtUp returns true if the standby replayed all the WAL it received.
+// It returns false if it doesn't stream from a primary.
+func caughtUp(receiveLSN, replayLSN string) bool {
+	receive, err := parseLSN(receiveLSN)
+	if err != nil {
+		return false
+	}
+	replay, err := parseLSN(replayLSN)
+	return err == nil && replay >= receive
+}
+
+func parseBool(value []byte) bool {
+	return string(value) == "t"
+}
+
+// parseFloat returns the value of a number in the text format, or 0 if it
+// is NULL.
+func parseFloat(value []byte) float64 {
+	f, _ := strconv.ParseFloat(string(value), 64)
+	return f
+}
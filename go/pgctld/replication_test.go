@@ -0,0 +1,362 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	pgctldservicepb "github.com/multigres/multigres/go/pb/pgctldservice"
+	"github.com/multigres/multigres/go/pgctld"
+)
+
+// fakeExecutor is a stub Executor. It returns the result of the first
+// rule whose pattern is in the query, or an empty result, and records the
+// queries.
+type fakeExecutor struct {
+	mu      sync.Mutex
+	rules   []fakeRule
+	queries []string
+
+	// maxRows and deadline are the arguments of the last query.
+	maxRows  int
+	deadline time.Time
+}
+
+type fakeRule struct {
+	pattern string
+	result  *pgctld.Result
+	err     error
+}
+
+// on makes the queries containing pattern return rows, a row of text
+// values each. "NULL" values are NULL. It returns the result, to complete
+// before the queries.
+func (e *fakeExecutor) on(pattern string, rows ...[]string) *pgctld.Result {
+	result := &pgctld.Result{}
+	for _, values := range rows {
+		var row [][]byte
+		for _, value := range values {
+			if value == "NULL" {
+				row = append(row, nil)
+			} else {
+				row = append(row, []byte(value))
+			}
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// The new rules take precedence.
+	e.rules = append([]fakeRule{{pattern: pattern, result: result}}, e.rules...)
+	return result
+}
+
+// fail makes the queries containing pattern fail with err.
+func (e *fakeExecutor) fail(pattern string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = append([]fakeRule{{pattern: pattern, err: err}}, e.rules...)
+}
+
+// ExecuteFetch is part of the Executor interface.
+func (e *fakeExecutor) ExecuteFetch(ctx context.Context, query string, maxRows int, args ...string) (*pgctld.Result, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.queries = append(e.queries, strings.Join(append([]string{query}, args...), " "))
+	e.maxRows = maxRows
+	e.deadline, _ = ctx.Deadline()
+	for _, rule := range e.rules {
+		if strings.Contains(query, rule.pattern) {
+			return rule.result, rule.err
+		}
+	}
+	return &pgctld.Result{}, nil
+}
+
+// executed returns the recorded queries containing pattern, with their
+// arguments, and forgets all the queries.
+func (e *fakeExecutor) executed(pattern string) []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	var queries []string
+	for _, query := range e.queries {
+		if strings.Contains(query, pattern) {
+			queries = append(queries, query)
+		}
+	}
+	e.queries = nil
+	return queries
+}
+
+// newReplicationClient returns a client of a running server, whose SQL
+// runs on the returned stub executor. The server is a primary.
+func newReplicationClient(t *testing.T) (*fakeExecutor, pgctldservicepb.PgCtldClient) {
+	t.Helper()
+	executor := &fakeExecutor{}
+	executor.on("pg_is_in_recovery", []string{"f"})
+	executor.on("pg_current_wal_lsn", []string{"0/3000148"})
+	config := newConfig(t)
+	config.Executor = executor
+	client := newClient(t, newManager(t, config))
+	ctx := context.Background()
+	_, err := client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.NoError(t, err)
+	_, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	return executor, client
+}
+
+func TestReplicationStatus(t *testing.T) {
+	executor, client := newReplicationClient(t)
+	ctx := context.Background()
+
+	// A primary with a standby and a slot.
+	executor.on("pg_stat_replication", []string{"standby1", "10.0.0.2", "streaming", "0/3000148", "0/3000148", "0/3000100", "0/3000060", "0.25", "async"})
+	executor.on("pg_replication_slots ORDER", []string{"standby1", "physical", "t", "0/3000060"})
+	resp, err := client.ReplicationStatus(ctx, &pgctldatapb.ReplicationStatusRequest{})
+	require.NoError(t, err)
+	status := resp.Status
+	require.False(t, status.InRecovery)
+	require.Equal(t, "0/3000148", status.CurrentLsn)
+	require.Nil(t, status.WalReceiver)
+	require.Len(t, status.Standbys, 1)
+	require.Equal(t, "standby1", status.Standbys[0].ApplicationName)
+	require.Equal(t, "0/3000060", status.Standbys[0].ReplayLsn)
+	require.Equal(t, 250*time.Millisecond, status.Standbys[0].ReplayLag.AsDuration())
+	require.Equal(t, "async", status.Standbys[0].SyncState)
+	require.Len(t, status.Slots, 1)
+	require.True(t, status.Slots[0].Active)
+
+	// A standby, before its first replayed transaction.
+	executor.on("pg_is_in_recovery", []string{"t"})
+	executor.on("pg_last_wal_receive_lsn", []string{"0/5000000", "0/4000000", "NULL", "f"})
+	executor.on("pg_stat_wal_receiver", []string{"streaming", "primary.example.com", "5432", "standby1"})
+	resp, err = client.ReplicationStatus(ctx, &pgctldatapb.ReplicationStatusRequest{})
+	require.NoError(t, err)
+	status = resp.Status
+	require.True(t, status.InRecovery)
+	require.Empty(t, status.CurrentLsn)
+	require.Equal(t, "0/5000000", status.ReceiveLsn)
+	require.Equal(t, "0/4000000", status.ReplayLsn)
+	require.Nil(t, status.LastReplayTime)
+	require.False(t, status.ReplayPaused)
+	require.Equal(t, &pgctldatapb.WalReceiver{
+		Status:      "streaming",
+		PrimaryHost: "primary.example.com",
+		PrimaryPort: 5432,
+		SlotName:    "standby1",
+	}, status.WalReceiver)
+
+	executor.on("pg_last_wal_receive_lsn", []string{"0/5000000", "0/4000000", "1700000000.5", "t"})
+	resp, err = client.ReplicationStatus(ctx, &pgctldatapb.ReplicationStatusRequest{})
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1700000000, 5e8).UTC(), resp.Status.LastReplayTime.AsTime())
+	require.Greater(t, resp.Status.ReplayLag.AsDuration(), time.Since(time.Unix(1700000000, 0))-time.Minute)
+	require.True(t, resp.Status.ReplayPaused)
+
+	// A standby that replayed all the WAL it received doesn't lag, however
+	// old its last replay.
+	executor.on("pg_last_wal_receive_lsn", []string{"0/5000000", "0/5000000", "1700000000.5", "f"})
+	resp, err = client.ReplicationStatus(ctx, &pgctldatapb.ReplicationStatusRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Status.LastReplayTime)
+	require.Zero(t, resp.Status.ReplayLag.AsDuration())
+
+	// The errors of the server are returned.
+	executor.fail("pg_stat_wal_receiver", mterrors.NewPGError(mterrors.InsufficientPrivilege, "permission denied", "", ""))
+	_, err = client.ReplicationStatus(ctx, &pgctldatapb.ReplicationStatusRequest{})
+	require.Equal(t, mtrpcpb.Code_PERMISSION_DENIED, mterrors.Code(err))
+	require.ErrorContains(t, err, "permission denied")
+}
+
+func TestPromote(t *testing.T) {
+	executor, client := newReplicationClient(t)
+	ctx := context.Background()
+
+	// A primary is not promoted again.
+	resp, err := client.Promote(ctx, &pgctldatapb.PromoteRequest{})
+	require.NoError(t, err)
+	require.False(t, resp.Status.InRecovery)
+	require.Empty(t, executor.executed("pg_promote"))
+
+	executor.on("pg_is_in_recovery", []string{"t"})
+	executor.on("pg_last_wal_receive_lsn", []string{"0/5000000", "0/5000000", "NULL", "f"})
+	executor.on("pg_promote", []string{"f"})
+	_, err = client.Promote(ctx, &pgctldatapb.PromoteRequest{})
+	require.Equal(t, mtrpcpb.Code_DEADLINE_EXCEEDED, mterrors.Code(err))
+	// The promotion waits for the configured timeout.
+	require.Equal(t, []string{"SELECT pg_promote(true, $1) 5"}, executor.executed("pg_promote"))
+
+	executor.on("pg_promote", []string{"t"})
+	resp, err = client.Promote(ctx, &pgctldatapb.PromoteRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, resp.Status)
+	require.Len(t, executor.executed("pg_promote"), 1)
+}
+
+func TestPromoteStandby(t *testing.T) {
+	executor := &fakeExecutor{}
+	executor.on("pg_is_in_recovery", []string{"t"})
+	executor.on("pg_last_wal_receive_lsn", []string{"0/5000000", "0/5000000", "NULL", "f"})
+	executor.on("pg_promote", []string{"t"})
+	config := newConfig(t)
+	config.Executor = executor
+	client := newClient(t, newManager(t, config))
+	ctx := context.Background()
+	_, err := client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.NoError(t, err)
+	_, err = client.SetPrimaryConnInfo(ctx, &pgctldatapb.SetPrimaryConnInfoRequest{PrimaryConninfo: "host=primary"})
+	require.NoError(t, err)
+
+	// A hot standby is ready once it accepts the read-only connections.
+	startResp, err := client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	require.True(t, startResp.Status.Ready)
+	pidFile := readLines(t, config, "postmaster.pid")
+	require.Equal(t, "standby", pidFile[len(pidFile)-1])
+
+	resp, err := client.Promote(ctx, &pgctldatapb.PromoteRequest{})
+	require.NoError(t, err)
+	require.True(t, resp.Status.InRecovery)
+	require.Len(t, executor.executed("pg_promote"), 1)
+}
+
+func TestReplicationSlots(t *testing.T) {
+	executor, client := newReplicationClient(t)
+	ctx := context.Background()
+
+	_, err := client.CreateReplicationSlot(ctx, &pgctldatapb.CreateReplicationSlotRequest{Name: "standby1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"SELECT pg_create_physical_replication_slot($1, true) standby1"}, executor.executed("pg_create"))
+	_, err = client.DropReplicationSlot(ctx, &pgctldatapb.DropReplicationSlotRequest{Name: "standby1"})
+	require.Equal(t, mtrpcpb.Code_NOT_FOUND, mterrors.Code(err))
+
+	executor.on("WHERE slot_name", []string{"1"})
+	_, err = client.CreateReplicationSlot(ctx, &pgctldatapb.CreateReplicationSlotRequest{Name: "standby1"})
+	require.Equal(t, mtrpcpb.Code_ALREADY_EXISTS, mterrors.Code(err))
+	_, err = client.DropReplicationSlot(ctx, &pgctldatapb.DropReplicationSlotRequest{Name: "standby1"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"SELECT pg_drop_replication_slot($1) standby1"}, executor.executed("pg_drop"))
+
+	for _, name := range []string{"", "Standby", "standby-1", strings.Repeat("a", 64)} {
+		_, err = client.CreateReplicationSlot(ctx, &pgctldatapb.CreateReplicationSlotRequest{Name: name})
+		require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err), name)
+	}
+	require.Empty(t, executor.executed(""))
+}
+
+func TestReplay(t *testing.T) {
+	executor, client := newReplicationClient(t)
+	ctx := context.Background()
+
+	// A primary doesn't replay the WAL.
+	_, err := client.PauseReplay(ctx, &pgctldatapb.PauseReplayRequest{})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+
+	executor.on("pg_is_in_recovery", []string{"t"})
+	executor.on("pg_last_wal_receive_lsn", []string{"0/5000000", "0/4000000", "NULL", "t"})
+	pauseResp, err := client.PauseReplay(ctx, &pgctldatapb.PauseReplayRequest{})
+	require.NoError(t, err)
+	require.True(t, pauseResp.Status.ReplayPaused)
+	require.Len(t, executor.executed("pg_wal_replay_pause"), 1)
+
+	executor.on("pg_last_wal_receive_lsn", []string{"0/5000000", "0/4000000", "NULL", "f"})
+	resumeResp, err := client.ResumeReplay(ctx, &pgctldatapb.ResumeReplayRequest{})
+	require.NoError(t, err)
+	require.False(t, resumeResp.Status.ReplayPaused)
+	require.Len(t, executor.executed("pg_wal_replay_resume"), 1)
+}
+
+func TestSetPrimaryConnInfo(t *testing.T) {
+	config := newConfig(t)
+	client := newClient(t, newManager(t, config))
+	ctx := context.Background()
+	_, err := client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.NoError(t, err)
+	signalFile := filepath.Join(config.DataDir, "standby.signal")
+
+	// A stopped server starts as a standby.
+	resp, err := client.SetPrimaryConnInfo(ctx, &pgctldatapb.SetPrimaryConnInfoRequest{
+		PrimaryConninfo: "host=primary port=5432 user=replicator application_name=standby1",
+		PrimarySlotName: "standby1",
+	})
+	require.NoError(t, err)
+	require.False(t, resp.RestartRequired)
+	require.FileExists(t, signalFile)
+	conf := readFile(t, config.DataDir, "postgresql.conf")
+	require.Contains(t, conf, "primary_conninfo = 'host=primary port=5432 user=replicator application_name=standby1'\n")
+	require.Contains(t, conf, "primary_slot_name = 'standby1'\n")
+
+	// A running standby reloads the new settings.
+	_, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	resp, err = client.SetPrimaryConnInfo(ctx, &pgctldatapb.SetPrimaryConnInfoRequest{PrimaryConninfo: "host=primary2"})
+	require.NoError(t, err)
+	require.False(t, resp.RestartRequired)
+	conf = readFile(t, config.DataDir, "postgresql.conf")
+	require.Contains(t, conf, "primary_conninfo = 'host=primary2'\n")
+	require.NotContains(t, conf, "primary_slot_name")
+	require.Eventually(t, func() bool {
+		return len(readLines(t, config, "reloads")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The conninfo is required.
+	_, err = client.SetPrimaryConnInfo(ctx, &pgctldatapb.SetPrimaryConnInfoRequest{})
+	require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+	require.Contains(t, readFile(t, config.DataDir, "postgresql.conf"), "primary_conninfo = 'host=primary2'\n")
+	require.FileExists(t, signalFile)
+
+	// A running primary must restart to become a standby.
+	require.NoError(t, os.Remove(signalFile))
+	resp, err = client.SetPrimaryConnInfo(ctx, &pgctldatapb.SetPrimaryConnInfoRequest{PrimaryConninfo: "host=primary"})
+	require.NoError(t, err)
+	require.True(t, resp.RestartRequired)
+	require.FileExists(t, signalFile)
+
+	_, err = client.SetPrimaryConnInfo(ctx, &pgctldatapb.SetPrimaryConnInfoRequest{PrimaryConninfo: "host=primary", PrimarySlotName: "bad-name"})
+	require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+	_, err = client.SetPrimaryConnInfo(ctx, &pgctldatapb.SetPrimaryConnInfoRequest{PrimaryConninfo: "host=primary\nport=5432"})
+	require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+}
+
+func TestReplicationErrors(t *testing.T) {
+	config := newConfig(t)
+	client := newClient(t, newManager(t, config))
+	ctx := context.Background()
+
+	// The server must run.
+	_, err := client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.NoError(t, err)
+	_, err = client.ReplicationStatus(ctx, &pgctldatapb.ReplicationStatusRequest{})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+
+	// The fake server doesn't accept connections on its socket.
+	_, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	_, err = client.ReplicationStatus(ctx, &pgctldatapb.ReplicationStatusRequest{})
+	require.Equal(t, mtrpcpb.Code_UNAVAILABLE, mterrors.Code(err))
+	require.ErrorContains(t, err, "failed to connect to postgres")
+}
@@ -0,0 +1,194 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	pgctldservicepb "github.com/multigres/multigres/go/pb/pgctldservice"
+)
+
+// server implements the PgCtld gRPC service with a Manager.
+type server struct {
+	pgctldservicepb.UnimplementedPgCtldServer
+	manager *Manager
+}
+
+// RegisterServer registers the PgCtld service of manager on s.
+func RegisterServer(s *grpc.Server, manager *Manager) {
+	pgctldservicepb.RegisterPgCtldServer(s, &server{manager: manager})
+}
+
+// InitDataDir is part of the PgCtld service.
+func (s *server) InitDataDir(ctx context.Context, req *pgctldatapb.InitDataDirRequest) (*pgctldatapb.InitDataDirResponse, error) {
+	status, err := s.manager.InitDataDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.InitDataDirResponse{Status: status}, nil
+}
+
+// Start is part of the PgCtld service.
+func (s *server) Start(ctx context.Context, req *pgctldatapb.StartRequest) (*pgctldatapb.StartResponse, error) {
+	status, err := s.manager.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.StartResponse{Status: status}, nil
+}
+
+// Stop is part of the PgCtld service.
+func (s *server) Stop(ctx context.Context, req *pgctldatapb.StopRequest) (*pgctldatapb.StopResponse, error) {
+	status, err := s.manager.Stop(ctx, req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.StopResponse{Status: status}, nil
+}
+
+// Restart is part of the PgCtld service.
+func (s *server) Restart(ctx context.Context, req *pgctldatapb.RestartRequest) (*pgctldatapb.RestartResponse, error) {
+	status, err := s.manager.Restart(ctx, req.Mode)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.RestartResponse{Status: status}, nil
+}
+
+// ReloadConfig is part of the PgCtld service.
+func (s *server) ReloadConfig(ctx context.Context, req *pgctldatapb.ReloadConfigRequest) (*pgctldatapb.ReloadConfigResponse, error) {
+	if err := s.manager.ReloadConfig(ctx); err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.ReloadConfigResponse{}, nil
+}
+
+// Status is part of the PgCtld service.
+func (s *server) Status(ctx context.Context, req *pgctldatapb.StatusRequest) (*pgctldatapb.StatusResponse, error) {
+	status, err := s.manager.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.StatusResponse{Status: status}, nil
+}
+
+// SetConfig is part of the PgCtld service.
+func (s *server) SetConfig(ctx context.Context, req *pgctldatapb.SetConfigRequest) (*pgctldatapb.SetConfigResponse, error) {
+	return s.manager.SetConfig(ctx, req.Settings, req.ResetSettings)
+}
+
+// SetPrimaryConnInfo is part of the PgCtld service.
+func (s *server) SetPrimaryConnInfo(ctx context.Context, req *pgctldatapb.SetPrimaryConnInfoRequest) (*pgctldatapb.SetPrimaryConnInfoResponse, error) {
+	restartRequired, err := s.manager.SetPrimaryConnInfo(ctx, req.PrimaryConninfo, req.PrimarySlotName)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.SetPrimaryConnInfoResponse{RestartRequired: restartRequired}, nil
+}
+
+// Promote is part of the PgCtld service.
+func (s *server) Promote(ctx context.Context, req *pgctldatapb.PromoteRequest) (*pgctldatapb.PromoteResponse, error) {
+	status, err := s.manager.Promote(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.PromoteResponse{Status: status}, nil
+}
+
+// CreateReplicationSlot is part of the PgCtld service.
+func (s *server) CreateReplicationSlot(ctx context.Context, req *pgctldatapb.CreateReplicationSlotRequest) (*pgctldatapb.CreateReplicationSlotResponse, error) {
+	if err := s.manager.CreateReplicationSlot(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.CreateReplicationSlotResponse{}, nil
+}
+
+// DropReplicationSlot is part of the PgCtld service.
+func (s *server) DropReplicationSlot(ctx context.Context, req *pgctldatapb.DropReplicationSlotRequest) (*pgctldatapb.DropReplicationSlotResponse, error) {
+	if err := s.manager.DropReplicationSlot(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.DropReplicationSlotResponse{}, nil
+}
+
+// PauseReplay is part of the PgCtld service.
+func (s *server) PauseReplay(ctx context.Context, req *pgctldatapb.PauseReplayRequest) (*pgctldatapb.PauseReplayResponse, error) {
+	status, err := s.manager.PauseReplay(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.PauseReplayResponse{Status: status}, nil
+}
+
+// ResumeReplay is part of the PgCtld service.
+func (s *server) ResumeReplay(ctx context.Context, req *pgctldatapb.ResumeReplayRequest) (*pgctldatapb.ResumeReplayResponse, error) {
+	status, err := s.manager.ResumeReplay(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.ResumeReplayResponse{Status: status}, nil
+}
+
+// ReplicationStatus is part of the PgCtld service.
+func (s *server) ReplicationStatus(ctx context.Context, req *pgctldatapb.ReplicationStatusRequest) (*pgctldatapb.ReplicationStatusResponse, error) {
+	status, err := s.manager.ReplicationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.ReplicationStatusResponse{Status: status}, nil
+}
+
+// ExecuteFetch is part of the PgCtld service.
+func (s *server) ExecuteFetch(ctx context.Context, req *pgctldatapb.ExecuteFetchRequest) (*pgctldatapb.ExecuteFetchResponse, error) {
+	result, err := s.manager.ExecuteFetch(ctx, req.Query, int(req.MaxRows), req.Timeout.AsDuration())
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.ExecuteFetchResponse{Result: result}, nil
+}
+
+// Backup is part of the PgCtld service.
+func (s *server) Backup(ctx context.Context, req *pgctldatapb.BackupRequest) (*pgctldatapb.BackupResponse, error) {
+	manifest, err := s.manager.Backup(ctx, req.Location, req.Directory)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.BackupResponse{Manifest: manifest}, nil
+}
+
+// ListBackups is part of the PgCtld service.
+func (s *server) ListBackups(ctx context.Context, req *pgctldatapb.ListBackupsRequest) (*pgctldatapb.ListBackupsResponse, error) {
+	manifests, err := s.manager.ListBackups(ctx, req.Location, req.Directory)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.ListBackupsResponse{Manifests: manifests}, nil
+}
+
+// Restore is part of the PgCtld service.
+func (s *server) Restore(ctx context.Context, req *pgctldatapb.RestoreRequest) (*pgctldatapb.RestoreResponse, error) {
+	target := RecoveryTarget{LSN: req.GetTargetLsn()}
+	if req.GetTargetTime() != nil {
+		target.Time = req.GetTargetTime().AsTime()
+	}
+	manifest, status, err := s.manager.Restore(ctx, req.Location, req.Directory, req.BackupName, target)
+	if err != nil {
+		return nil, err
+	}
+	return &pgctldatapb.RestoreResponse{Manifest: manifest, Status: status}, nil
+}
@@ -0,0 +1,220 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	pgctldatapb "github.com/multigres/multigres/go/pb/pgctldata"
+	pgctldservicepb "github.com/multigres/multigres/go/pb/pgctldservice"
+	"github.com/multigres/multigres/go/pgctld"
+)
+
+// newConfig returns the configuration of a server run by the fake
+// binaries of testdata/bin, in a data directory that isn't initialized.
+func newConfig(t *testing.T) pgctld.Config {
+	t.Helper()
+	binDir, err := filepath.Abs("testdata/bin")
+	require.NoError(t, err)
+	return pgctld.Config{
+		BinDir:  binDir,
+		DataDir: filepath.Join(t.TempDir(), "data"),
+		Port:    5433,
+		User:    "postgres",
+		Timeout: 5 * time.Second,
+	}
+}
+
+// stateDir returns the state directory of the server of config.
+func stateDir(config pgctld.Config) string {
+	return config.DataDir + ".pgctld"
+}
+
+// newManager returns a Manager of config, and stops its server at the end
+// of the test.
+func newManager(t *testing.T, config pgctld.Config) *pgctld.Manager {
+	t.Helper()
+	manager := pgctld.NewManager(config)
+	t.Cleanup(func() {
+		_, _ = manager.Stop(context.Background(), pgctldatapb.StopMode_IMMEDIATE)
+	})
+	return manager
+}
+
+// newClient returns a client of the PgCtld service of manager.
+func newClient(t *testing.T, manager *pgctld.Manager) pgctldservicepb.PgCtldClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(mterrors.UnaryServerInterceptor),
+	)
+	pgctld.RegisterServer(server, manager)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(mterrors.UnaryClientInterceptor),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return pgctldservicepb.NewPgCtldClient(conn)
+}
+
+// readLines returns the lines the fake postgres recorded in file.
+func readLines(t *testing.T, config pgctld.Config, file string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(config.DataDir, file))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	return strings.Fields(string(data))
+}
+
+func TestLifecycle(t *testing.T) {
+	config := newConfig(t)
+	config.Password = "secret"
+	client := newClient(t, newManager(t, config))
+	ctx := context.Background()
+
+	// The data directory must be initialized first.
+	status, err := client.Status(ctx, &pgctldatapb.StatusRequest{})
+	require.NoError(t, err)
+	require.False(t, status.Status.Initialized)
+	require.Equal(t, pgctldatapb.ServerState_STOPPED, status.Status.State)
+	_, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+
+	initResp, err := client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.NoError(t, err)
+	require.True(t, initResp.Status.Initialized)
+	require.Equal(t, "17", initResp.Status.Version)
+	require.Contains(t, readLines(t, config, "initdb_args"), "postgres")
+	require.Equal(t, []string{"secret"}, readLines(t, config, "initdb_password"))
+	_, err = client.InitDataDir(ctx, &pgctldatapb.InitDataDirRequest{})
+	require.Equal(t, mtrpcpb.Code_ALREADY_EXISTS, mterrors.Code(err))
+
+	// Start waits until the server is ready, and is idempotent.
+	startResp, err := client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	require.Equal(t, pgctldatapb.ServerState_RUNNING, startResp.Status.State)
+	require.True(t, startResp.Status.Ready)
+	require.NotZero(t, startResp.Status.Pid)
+	require.Equal(t, int32(5433), startResp.Status.Port)
+	require.NotNil(t, startResp.Status.StartTime)
+	require.NotNil(t, startResp.Status.Uptime)
+	pid := startResp.Status.Pid
+	startResp, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	require.Equal(t, pid, startResp.Status.Pid)
+
+	_, err = client.ReloadConfig(ctx, &pgctldatapb.ReloadConfigRequest{})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(readLines(t, config, "reloads")) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Restart stops the server in the requested mode.
+	restartResp, err := client.Restart(ctx, &pgctldatapb.RestartRequest{Mode: pgctldatapb.StopMode_SMART})
+	require.NoError(t, err)
+	require.True(t, restartResp.Status.Ready)
+	require.NotEqual(t, pid, restartResp.Status.Pid)
+	require.Equal(t, []string{"smart"}, readLines(t, config, "shutdowns"))
+
+	// Stop is fast by default, and idempotent.
+	stopResp, err := client.Stop(ctx, &pgctldatapb.StopRequest{})
+	require.NoError(t, err)
+	require.Equal(t, pgctldatapb.ServerState_STOPPED, stopResp.Status.State)
+	require.Zero(t, stopResp.Status.Pid)
+	_, err = client.Stop(ctx, &pgctldatapb.StopRequest{Mode: pgctldatapb.StopMode_IMMEDIATE})
+	require.NoError(t, err)
+	require.Equal(t, []string{"smart", "fast"}, readLines(t, config, "shutdowns"))
+
+	_, err = client.Start(ctx, &pgctldatapb.StartRequest{})
+	require.NoError(t, err)
+	_, err = client.Stop(ctx, &pgctldatapb.StopRequest{Mode: pgctldatapb.StopMode_IMMEDIATE})
+	require.NoError(t, err)
+	require.Equal(t, []string{"smart", "fast", "immediate"}, readLines(t, config, "shutdowns"))
+
+	// A stopped server can't reload its configuration.
+	_, err = client.ReloadConfig(ctx, &pgctldatapb.ReloadConfigRequest{})
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+}
+
+func TestStartFailures(t *testing.T) {
+	config := newConfig(t)
+	config.Timeout = 100 * time.Millisecond
+	manager := newManager(t, config)
+	ctx := context.Background()
+	_, err := manager.InitDataDir(ctx)
+	require.NoError(t, err)
+
+	// The server doesn't accept connections in time, and is stopped.
+	_, err = manager.Start(ctx)
+	require.Equal(t, mtrpcpb.Code_DEADLINE_EXCEEDED, mterrors.Code(err))
+	status, err := manager.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, pgctldatapb.ServerState_STOPPED, status.State)
+	require.Equal(t, []string{"fast"}, readLines(t, config, "shutdowns"))
+
+	// The server exits.
+	require.NoError(t, os.WriteFile(filepath.Join(config.DataDir, "fail_start"), nil, 0o644))
+	_, err = manager.Start(ctx)
+	require.ErrorContains(t, err, "postgres exited during startup")
+	log, err := os.ReadFile(filepath.Join(stateDir(config), "postgres.log"))
+	require.NoError(t, err)
+	require.Contains(t, string(log), "FATAL: could not create listen socket")
+
+	// The postmaster.pid file of a crashed server is ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(config.DataDir, "postmaster.pid"), []byte("999999999\n"), 0o644))
+	status, err = manager.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, pgctldatapb.ServerState_STOPPED, status.State)
+
+	// So is the one of a crashed server whose pid was reused.
+	pid := []byte(strconv.Itoa(os.Getpid()) + "\n")
+	require.NoError(t, os.WriteFile(filepath.Join(config.DataDir, "postmaster.pid"), pid, 0o644))
+	status, err = manager.Status(ctx)
+	require.NoError(t, err)
+	require.Equal(t, pgctldatapb.ServerState_STOPPED, status.State)
+}
+
+func TestManagerErrors(t *testing.T) {
+	ctx := context.Background()
+	_, err := pgctld.NewManager(pgctld.Config{}).Status(ctx)
+	require.Equal(t, mtrpcpb.Code_FAILED_PRECONDITION, mterrors.Code(err))
+
+	manager := newManager(t, newConfig(t))
+	_, err = manager.Stop(ctx, pgctldatapb.StopMode(42))
+	require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+}
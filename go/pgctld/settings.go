@@ -0,0 +1,183 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// settingKind is the type of the value of a postgresql.conf setting.
+type settingKind int
+
+const (
+	boolSetting settingKind = iota
+	intSetting
+	realSetting
+	memorySetting
+	durationSetting
+	enumSetting
+	stringSetting
+)
+
+// settingDef describes a postgresql.conf setting that pgctld accepts.
+type settingDef struct {
+	kind settingKind
+
+	// restart is true if the server must restart to apply a new value,
+	// like the settings of the postmaster context. The others are applied
+	// by a reload.
+	restart bool
+
+	// values are the accepted values of an enumSetting.
+	values []string
+}
+
+// knownSettings are the postgresql.conf settings that can be set through
+// the overrides and SetConfig. See the "Server Configuration" chapter of
+// the PostgreSQL documentation.
+var knownSettings = map[string]settingDef{
+	// Connections.
+	"listen_addresses":               {kind: stringSetting, restart: true},
+	"max_connections":                {kind: intSetting, restart: true},
+	"superuser_reserved_connections": {kind: intSetting, restart: true},
+	"password_encryption":            {kind: enumSetting, values: []string{"md5", "scram-sha-256"}},
+	"ssl":                            {kind: boolSetting},
+	"ssl_cert_file":                  {kind: stringSetting},
+	"ssl_key_file":                   {kind: stringSetting},
+	"ssl_ca_file":                    {kind: stringSetting},
+
+	// Resources.
+	"shared_buffers":                  {kind: memorySetting, restart: true},
+	"huge_pages":                      {kind: enumSetting, restart: true, values: []string{"on", "off", "try"}},
+	"work_mem":                        {kind: memorySetting},
+	"maintenance_work_mem":            {kind: memorySetting},
+	"effective_cache_size":            {kind: memorySetting},
+	"effective_io_concurrency":        {kind: intSetting},
+	"max_locks_per_transaction":       {kind: intSetting, restart: true},
+	"max_worker_processes":            {kind: intSetting, restart: true},
+	"max_parallel_workers":            {kind: intSetting},
+	"max_parallel_workers_per_gather": {kind: intSetting},
+	"shared_preload_libraries":        {kind: stringSetting, restart: true},
+	"random_page_cost":                {kind: realSetting},
+
+	// Write-ahead log.
+	"wal_level":                    {kind: enumSetting, restart: true, values: []string{"replica", "logical"}},
+	"wal_log_hints":                {kind: boolSetting, restart: true},
+	"wal_buffers":                  {kind: memorySetting, restart: true},
+	"fsync":                        {kind: boolSetting},
+	"synchronous_commit":           {kind: enumSetting, values: []string{"on", "off", "local", "remote_write", "remote_apply"}},
+	"max_wal_size":                 {kind: memorySetting},
+	"min_wal_size":                 {kind: memorySetting},
+	"checkpoint_timeout":           {kind: durationSetting},
+	"checkpoint_completion_target": {kind: realSetting},
+	"archive_timeout":              {kind: durationSetting},
+
+	// Replication.
+	"max_wal_senders":           {kind: intSetting, restart: true},
+	"max_replication_slots":     {kind: intSetting, restart: true},
+	"wal_keep_size":             {kind: memorySetting},
+	"synchronous_standby_names": {kind: stringSetting},
+	"hot_standby":               {kind: boolSetting, restart: true},
+	"hot_standby_feedback":      {kind: boolSetting},
+	"primary_conninfo":          {kind: stringSetting},
+	"primary_slot_name":         {kind: stringSetting},
+
+	// Recovery targets, see Restore.
+	"recovery_target_lsn":    {kind: stringSetting, restart: true},
+	"recovery_target_time":   {kind: stringSetting, restart: true},
+	"recovery_target_action": {kind: enumSetting, restart: true, values: []string{"pause", "promote", "shutdown"}},
+
+	// Logging.
+	"logging_collector":          {kind: boolSetting, restart: true},
+	"log_destination":            {kind: stringSetting},
+	"log_directory":              {kind: stringSetting},
+	"log_filename":               {kind: stringSetting},
+	"log_line_prefix":            {kind: stringSetting},
+	"log_min_duration_statement": {kind: durationSetting},
+	"log_statement":              {kind: enumSetting, values: []string{"none", "ddl", "mod", "all"}},
+	"log_connections":            {kind: boolSetting},
+	"log_disconnections":         {kind: boolSetting},
+	"log_lock_waits":             {kind: boolSetting},
+
+	// Clients.
+	"statement_timeout":                   {kind: durationSetting},
+	"lock_timeout":                        {kind: durationSetting},
+	"idle_in_transaction_session_timeout": {kind: durationSetting},
+	"timezone":                            {kind: stringSetting},
+	"autovacuum":                          {kind: boolSetting},
+}
+
+// managedSettings are set by pgctld from its own configuration, and can't
+// be overridden: the backups need the WAL archive. wal_level can't be
+// minimal either, which disables it.
+var managedSettings = []string{"port", "unix_socket_directories", "archive_mode", "archive_command", "restore_command"}
+
+var (
+	boolValues    = []string{"on", "off", "true", "false", "yes", "no", "1", "0"}
+	memoryValue   = regexp.MustCompile(`^-?[0-9]+\s*(B|kB|MB|GB|TB)?$`)
+	durationValue = regexp.MustCompile(`^-?[0-9]+\s*(us|ms|s|min|h|d)?$`)
+)
+
+// validateSetting returns an error if value is not a valid value of the
+// setting name.
+func validateSetting(name, value string) error {
+	if slices.Contains(managedSettings, name) {
+		return mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "setting %v is managed by pgctld", name)
+	}
+	def, ok := knownSettings[name]
+	if !ok {
+		return mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "unknown setting %v", name)
+	}
+	var valid bool
+	switch def.kind {
+	case boolSetting:
+		valid = slices.Contains(boolValues, strings.ToLower(value))
+	case intSetting:
+		_, err := strconv.Atoi(value)
+		valid = err == nil
+	case realSetting:
+		_, err := strconv.ParseFloat(value, 64)
+		valid = err == nil
+	case memorySetting:
+		valid = memoryValue.MatchString(value)
+	case durationSetting:
+		valid = durationValue.MatchString(value)
+	case enumSetting:
+		valid = slices.Contains(def.values, strings.ToLower(value))
+	case stringSetting:
+		valid = !strings.ContainsAny(value, "\n\r")
+	}
+	if !valid {
+		return mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "invalid value %q for setting %v", value, name)
+	}
+	return nil
+}
+
+// requiresRestart returns true if a change of the setting name requires
+// a restart of the server.
+func requiresRestart(name string) bool {
+	return knownSettings[name].restart || slices.Contains(managedSettings, name)
+}
+
+// quoteSetting returns value quoted for postgresql.conf.
+func quoteSetting(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
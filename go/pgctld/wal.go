@@ -0,0 +1,267 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgctld
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	"github.com/multigres/multigres/go/pgctld/backupstorage"
+)
+
+// This file implements the shipping of the archived WAL.
+//
+// The archive_command copies the WAL segments in walArchiveDir of the
+// state directory, a fast local copy the server waits for. The shipper
+// then uploads them to the last backup taken of the server, the WAL
+// target, in wal/ with the index walIndexFile, and removes them. Without
+// target, nothing can replay them: they are removed. A backup holds walMu
+// from before it starts to the change of the target, so that the WAL
+// archived meanwhile is shipped to it. A restore replays the WAL shipped
+// to the restored backup and to the later ones.
+
+const (
+	// walTargetFile holds the WAL target, in the data directory.
+	walTargetFile = "pgctld_wal_target.yaml"
+
+	// walIndexFile lists the WAL segments shipped to a backup.
+	walIndexFile = "WAL"
+
+	// DefaultWALShipInterval is the default interval of ShipWAL.
+	DefaultWALShipInterval = 10 * time.Second
+)
+
+// walTarget is the backup the archived WAL is shipped to.
+type walTarget struct {
+	Location string `yaml:"location"`
+	Dir      string `yaml:"dir"`
+	Backup   string `yaml:"backup"`
+}
+
+// walIndex lists the WAL segments shipped to a backup, in the order of
+// the WAL.
+type walIndex struct {
+	Segments []walSegment `json:"segments"`
+
+	// backup is the name of the backup.
+	backup string
+}
+
+// walSegment is a file of the WAL archive, shipped to a backup. The end
+// of the WAL is only set for the WAL segments, not the history files.
+type walSegment struct {
+	Name   string `json:"name"`
+	Sha256 string `json:"sha256"`
+
+	// EndLSN is the WAL location at the end of the segment.
+	EndLSN string `json:"end_lsn,omitempty"`
+
+	// EndTime is the time of the last write of the segment, before the
+	// server switched to the next one.
+	EndTime time.Time `json:"end_time,omitzero"`
+}
+
+// formatLSN returns lsn in the format of the WAL locations, like
+// 0/3000148.
+func formatLSN(lsn uint64) string {
+	return fmt.Sprintf("%X/%X", lsn>>32, uint32(lsn))
+}
+
+// segmentEnd returns the WAL location at the end of the WAL segment name,
+// of size bytes, like 000000010000000000000003: its timeline, then the
+// high and the low parts of its number. It returns false if name is not
+// a WAL segment.
+func segmentEnd(name string, size int64) (string, bool) {
+	if len(name) != 24 || size <= 0 {
+		return "", false
+	}
+	high, err := strconv.ParseUint(name[8:16], 16, 32)
+	if err != nil {
+		return "", false
+	}
+	low, err := strconv.ParseUint(name[16:24], 16, 32)
+	if err != nil {
+		return "", false
+	}
+	return formatLSN(high<<32 + (low+1)*uint64(size)), true
+}
+
+// readWALTarget returns the WAL target, or nil if there is none.
+func (m *Manager) readWALTarget() (*walTarget, error) {
+	data, err := os.ReadFile(filepath.Join(m.config.DataDir, walTargetFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, mterrors.Wrap(err, "failed to read the WAL target")
+	}
+	target := &walTarget{}
+	if err := yaml.Unmarshal(data, target); err != nil {
+		return nil, mterrors.Wrapf(err, "failed to parse %v", walTargetFile)
+	}
+	return target, nil
+}
+
+// writeWALTarget sets the WAL target.
+func (m *Manager) writeWALTarget(target *walTarget) error {
+	data, err := yaml.Marshal(target)
+	if err != nil {
+		return mterrors.Wrap(err, "failed to marshal the WAL target")
+	}
+	if err := writeFileAtomic(filepath.Join(m.config.DataDir, walTargetFile), data); err != nil {
+		return mterrors.Wrap(err, "failed to write the WAL target")
+	}
+	return nil
+}
+
+// readWALIndex returns the index of the WAL shipped to the backup name in
+// dir, empty if none was.
+func readWALIndex(ctx context.Context, storage backupstorage.Storage, dir, name string) (*walIndex, error) {
+	index := &walIndex{backup: name}
+	r, err := storage.ReadFile(ctx, dir, name, walIndexFile)
+	if mterrors.Code(err) == mtrpcpb.Code_NOT_FOUND {
+		return index, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, mterrors.Wrapf(err, "failed to read the WAL index of backup %v", name)
+	}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, mterrors.Wrapf(err, "failed to parse the WAL index of backup %v", name)
+	}
+	return index, nil
+}
+
+// writeWALIndex replaces the index of the WAL shipped to the backup name
+// in dir.
+func writeWALIndex(ctx context.Context, storage backupstorage.Storage, dir, name string, index *walIndex) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return mterrors.Wrap(err, "failed to marshal the WAL index")
+	}
+	w, err := storage.AddFile(ctx, dir, name, walIndexFile)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return mterrors.Wrapf(err, "failed to write the WAL index of backup %v", name)
+	}
+	return w.Close()
+}
+
+// ShipWAL ships the WAL archived by the server to the WAL target every
+// interval, until ctx is done. It skips the ticks during the backups and
+// the restores.
+func (m *Manager) ShipWAL(ctx context.Context, interval time.Duration) {
+	if m.config.DataDir == "" {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultWALShipInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !m.walMu.TryLock() {
+				continue
+			}
+			err := m.shipWALLocked(ctx)
+			m.walMu.Unlock()
+			if err != nil {
+				slog.Error("Failed to ship the archived WAL", "error", err)
+			}
+		}
+	}
+}
+
+// shipWALLocked ships the archived WAL segments to the WAL target, and
+// removes them, with walMu held. Without target, it only removes them.
+func (m *Manager) shipWALLocked(ctx context.Context) error {
+	archiveDir := filepath.Join(m.config.StateDir, walArchiveDir)
+	segments, err := archivedSegments(archiveDir)
+	if err != nil || len(segments) == 0 {
+		return err
+	}
+	target, err := m.readWALTarget()
+	if err != nil {
+		return err
+	}
+	if target == nil {
+		for _, segment := range segments {
+			if err := os.Remove(filepath.Join(archiveDir, segment)); err != nil {
+				return mterrors.Wrapf(err, "failed to remove the archived WAL segment %v", segment)
+			}
+		}
+		slog.Info("Removed the archived WAL, without backup to ship it to", "segments", len(segments))
+		return nil
+	}
+
+	storage, err := openStorage(target.Location, target.Dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = storage.Close() }()
+	index, err := readWALIndex(ctx, storage, target.Dir, target.Backup)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		p := filepath.Join(archiveDir, segment)
+		info, err := os.Stat(p)
+		if err != nil {
+			return mterrors.Wrapf(err, "failed to stat the archived WAL segment %v", segment)
+		}
+		file, err := uploadFile(ctx, storage, target.Dir, target.Backup, walPrefix+segment, p)
+		if err != nil {
+			return err
+		}
+		shipped := walSegment{Name: file.Name, Sha256: file.Sha256}
+		if end, ok := segmentEnd(segment, info.Size()); ok {
+			// The archive_command keeps the modification time.
+			shipped.EndLSN = end
+			shipped.EndTime = info.ModTime().UTC()
+		}
+		index.Segments = append(index.Segments, shipped)
+		if err := writeWALIndex(ctx, storage, target.Dir, target.Backup, index); err != nil {
+			return err
+		}
+		if err := os.Remove(p); err != nil {
+			return mterrors.Wrapf(err, "failed to remove the shipped WAL segment %v", segment)
+		}
+	}
+	slog.Info("Shipped the archived WAL", "backup", target.Backup, "segments", len(segments))
+	return nil
+}
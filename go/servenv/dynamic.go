@@ -0,0 +1,318 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servenv
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cast"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// This file implements the settings that can be changed while a binary
+// runs, like its log level or its timeouts.
+//
+// A dynamic value is declared at the package level with one of the
+// NewDynamic functions, before Init is called. It is registered as a flag
+// of the binary, and is initialized like any other flag. It is then
+// reloaded from the environment and the config file when the config file
+// changes or when the binary receives SIGHUP, unless it was given on the
+// command line. All the reloaded values are validated before any of them
+// is applied, and every change is logged.
+
+// Dynamic is a setting of type T that can change while the binary runs.
+type Dynamic[T comparable] struct {
+	name      string
+	shorthand string
+	usage     string
+	typeName  string
+	def       T
+	parse     func(any) (T, error)
+
+	mu        sync.Mutex
+	value     T
+	validate  func(T) error
+	listeners []func(oldValue, newValue T)
+}
+
+// setting is the type-independent interface of the dynamic values.
+type setting interface {
+	pflag.Value
+
+	flag() (name, shorthand, usage string)
+
+	// defaultValue returns the default value.
+	defaultValue() any
+
+	// reset sets the value back to its default.
+	reset()
+
+	// prepare parses and validates raw. It returns the function applying
+	// the new value, or nil if the value does not change.
+	prepare(raw any) (func(), error)
+}
+
+var (
+	// settingsMu protects settings.
+	settingsMu sync.Mutex
+	settings   []setting
+
+	// commandLine contains the names of the flags given on the command
+	// line, which are not reloaded.
+	commandLine = map[string]bool{}
+
+	// reloadMu serializes the reloads.
+	reloadMu sync.Mutex
+)
+
+func newDynamic[T comparable](name string, def T, usage, typeName string, parse func(any) (T, error)) *Dynamic[T] {
+	d := &Dynamic[T]{
+		name:     name,
+		usage:    usage,
+		typeName: typeName,
+		def:      def,
+		parse:    parse,
+		value:    def,
+	}
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	settings = append(settings, d)
+	return d
+}
+
+// NewDynamicString returns a dynamic string registered as the flag name.
+func NewDynamicString(name, def, usage string) *Dynamic[string] {
+	return newDynamic(name, def, usage, "string", cast.ToStringE)
+}
+
+// NewDynamicInt returns a dynamic int registered as the flag name.
+func NewDynamicInt(name string, def int, usage string) *Dynamic[int] {
+	return newDynamic(name, def, usage, "int", cast.ToIntE)
+}
+
+// NewDynamicBool returns a dynamic bool registered as the flag name.
+func NewDynamicBool(name string, def bool, usage string) *Dynamic[bool] {
+	return newDynamic(name, def, usage, "bool", cast.ToBoolE)
+}
+
+// NewDynamicDuration returns a dynamic duration registered as the flag
+// name.
+func NewDynamicDuration(name string, def time.Duration, usage string) *Dynamic[time.Duration] {
+	return newDynamic(name, def, usage, "duration", cast.ToDurationE)
+}
+
+// WithValidator sets the function validating the new values. Invalid
+// values are rejected, both on the command line and when reloading.
+func (d *Dynamic[T]) WithValidator(validate func(T) error) *Dynamic[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.validate = validate
+	return d
+}
+
+// OnChange registers f to be called with the old and the new value each
+// time the value changes.
+func (d *Dynamic[T]) OnChange(f func(oldValue, newValue T)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.listeners = append(d.listeners, f)
+}
+
+// Get returns the current value.
+func (d *Dynamic[T]) Get() T {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.value
+}
+
+// Name returns the name of the flag of the value.
+func (d *Dynamic[T]) Name() string {
+	return d.name
+}
+
+// String implements pflag.Value.
+func (d *Dynamic[T]) String() string {
+	return fmt.Sprint(d.Get())
+}
+
+// Type implements pflag.Value.
+func (d *Dynamic[T]) Type() string {
+	return d.typeName
+}
+
+// Set implements pflag.Value.
+func (d *Dynamic[T]) Set(s string) error {
+	apply, err := d.prepare(s)
+	if err != nil {
+		return err
+	}
+	if apply != nil {
+		apply()
+	}
+	return nil
+}
+
+func (d *Dynamic[T]) flag() (name, shorthand, usage string) {
+	return d.name, d.shorthand, d.usage
+}
+
+func (d *Dynamic[T]) defaultValue() any {
+	return d.def
+}
+
+func (d *Dynamic[T]) reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.value = d.def
+}
+
+func (d *Dynamic[T]) prepare(raw any) (func(), error) {
+	value, err := d.parse(raw)
+	if err != nil {
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "invalid value %q for %v: %v", fmt.Sprint(raw), d.name, err)
+	}
+
+	d.mu.Lock()
+	validate, oldValue := d.validate, d.value
+	d.mu.Unlock()
+	if validate != nil {
+		if err := validate(value); err != nil {
+			return nil, mterrors.Wrapf(err, "invalid value %q for %v", fmt.Sprint(raw), d.name)
+		}
+	}
+	if value == oldValue {
+		return nil, nil
+	}
+
+	return func() {
+		d.mu.Lock()
+		oldValue := d.value
+		d.value = value
+		listeners := append([]func(T, T){}, d.listeners...)
+		d.mu.Unlock()
+		for _, f := range listeners {
+			f(oldValue, value)
+		}
+	}, nil
+}
+
+// registerSettings registers the dynamic values as flags of fs, with
+// their default values.
+func registerSettings(fs *pflag.FlagSet) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	for _, s := range settings {
+		s.reset()
+		name, shorthand, usage := s.flag()
+		fs.VarP(s, name, shorthand, usage)
+	}
+}
+
+// Reload reads the config file again, then reloads the dynamic values
+// that were not given on the command line from the environment and the
+// config file, and fires the OnReload hooks. If any of the new values is
+// invalid, none is applied.
+func Reload() error {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return mterrors.Wrap(err, "failed to read config file")
+		}
+	}
+	if err := reloadSettings(); err != nil {
+		return err
+	}
+	onReloadHooks.Fire()
+	return nil
+}
+
+// reloadSettings reloads the dynamic values from the viper configuration.
+func reloadSettings() error {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+
+	var changes []func()
+	var errs []error
+	for _, s := range settings {
+		name, _, _ := s.flag()
+		if commandLine[name] {
+			continue
+		}
+		var raw any = viper.Get(name)
+		if !viper.IsSet(name) {
+			raw = s.defaultValue()
+		}
+		oldValue := s.String()
+		apply, err := s.prepare(raw)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if apply != nil {
+			changes = append(changes, func() {
+				apply()
+				slog.Info("Setting changed", "name", name, "old", oldValue, "new", s.String())
+			})
+		}
+	}
+	if err := mterrors.Aggregate(errs); err != nil {
+		return mterrors.Wrap(err, "configuration not reloaded")
+	}
+	for _, apply := range changes {
+		apply()
+	}
+	return nil
+}
+
+// watchConfig reloads the dynamic values when the config file changes or
+// when the binary receives SIGHUP.
+func watchConfig() {
+	if viper.ConfigFileUsed() != "" {
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			reloadMu.Lock()
+			defer reloadMu.Unlock()
+			slog.Info("Config file changed", "file", e.Name)
+			if err := reloadSettings(); err != nil {
+				slog.Error("Failed to reload configuration", "error", err)
+				return
+			}
+			onReloadHooks.Fire()
+		})
+		viper.WatchConfig()
+	}
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	go func() {
+		for range sighup {
+			slog.Info("Received SIGHUP, reloading configuration")
+			if err := Reload(); err != nil {
+				slog.Error("Failed to reload configuration", "error", err)
+			}
+		}
+	}()
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servenv_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/servenv"
+)
+
+var (
+	poolSize = servenv.NewDynamicInt("test-pool-size", 10, "size of the test pool").WithValidator(func(size int) error {
+		if size <= 0 {
+			return errors.New("must be positive")
+		}
+		return nil
+	})
+	poolTimeout = servenv.NewDynamicDuration("test-pool-timeout", time.Second, "timeout of the test pool")
+	preferLocal = servenv.NewDynamicBool("test-prefer-local", true, "prefer local replicas")
+)
+
+func TestDynamicReload(t *testing.T) {
+	fs := newFlagSet(t)
+	configFile := filepath.Join(t.TempDir(), "multipooler.yaml")
+	writeConfig := func(config string) {
+		t.Helper()
+		require.NoError(t, os.WriteFile(configFile, []byte(config), 0o644))
+	}
+	writeConfig("test-pool-size: 20\nlog-level: info\n")
+
+	require.NoError(t, servenv.ParseFlags(fs, "multipooler", []string{"--config", configFile, "--test-pool-timeout", "2s"}))
+	require.Equal(t, 20, poolSize.Get())
+	require.Equal(t, 2*time.Second, poolTimeout.Get())
+	require.True(t, preferLocal.Get())
+
+	var changes [][2]int
+	poolSize.OnChange(func(oldValue, newValue int) {
+		changes = append(changes, [2]int{oldValue, newValue})
+	})
+	reloads := 0
+	servenv.OnReload(func() { reloads++ })
+
+	// Values given on the command line are not reloaded, values removed
+	// from the config file are back to their default.
+	writeConfig("test-pool-size: 30\ntest-pool-timeout: 5s\ntest-prefer-local: false\n")
+	require.NoError(t, servenv.Reload())
+	require.Equal(t, 30, poolSize.Get())
+	require.Equal(t, 2*time.Second, poolTimeout.Get())
+	require.False(t, preferLocal.Get())
+	require.Equal(t, [][2]int{{20, 30}}, changes)
+
+	writeConfig("test-pool-size: 30\n")
+	require.NoError(t, servenv.Reload())
+	require.True(t, preferLocal.Get())
+	require.Len(t, changes, 1)
+
+	// Invalid values are rejected, and none of the values is applied.
+	writeConfig("test-pool-size: 0\ntest-prefer-local: false\nlog-level: loud\n")
+	err := servenv.Reload()
+	require.ErrorContains(t, err, `invalid value "0" for test-pool-size: must be positive`)
+	require.ErrorContains(t, err, `unknown log level "loud"`)
+	require.Equal(t, 30, poolSize.Get())
+	require.True(t, preferLocal.Get())
+	require.Equal(t, 2, reloads)
+
+	// Environment variables are reloaded too.
+	writeConfig("")
+	t.Setenv("MULTIPOOLER_TEST_POOL_SIZE", "40")
+	require.NoError(t, servenv.Reload())
+	require.Equal(t, 40, poolSize.Get())
+	require.Equal(t, [][2]int{{20, 30}, {30, 40}}, changes)
+	require.Equal(t, 3, reloads)
+}
+
+func TestDynamicCommandLineValidation(t *testing.T) {
+	fs := newFlagSet(t)
+	require.ErrorContains(t, servenv.ParseFlags(fs, "multipooler", []string{"--test-pool-size", "-1"}), "must be positive")
+
+	fs = newFlagSet(t)
+	require.ErrorContains(t, servenv.ParseFlags(fs, "multipooler", []string{"--log-level", "loud"}), "unknown log level")
+
+	// Registering the flags sets the values back to their default.
+	fs = newFlagSet(t)
+	require.NoError(t, servenv.ParseFlags(fs, "multipooler", nil))
+	require.Equal(t, 10, poolSize.Get())
+	require.Equal(t, "10", fs.Lookup("test-pool-size").Value.String())
+	require.Equal(t, "int", fs.Lookup("test-pool-size").Value.Type())
+}
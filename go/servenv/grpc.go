@@ -0,0 +1,75 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servenv
+
+import (
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/multigres/multigres/go/mterrors"
+	"github.com/multigres/multigres/go/tracing"
+)
+
+// NewGRPCServer returns a gRPC server with the interceptors shared by the
+// binaries: the tracing interceptors, then the mterrors interceptors. It
+// also serves the standard gRPC health service, SERVING while the binary
+// is serving.
+func NewGRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor, mterrors.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor, mterrors.StreamServerInterceptor),
+	}, opts...)
+	server := grpc.NewServer(opts...)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+	OnRun(func() {
+		healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	})
+	OnTerm(func() {
+		healthServer.Shutdown()
+	})
+	return server
+}
+
+// ServeGRPC serves server on host and port while the binary runs: it
+// starts listening in an OnRun hook, and stops gracefully in an OnTerm
+// hook, after the ongoing calls complete. An empty host listens on all
+// the interfaces. The binary exits if it can't listen.
+func ServeGRPC(server *grpc.Server, host string, port int) {
+	OnRun(func() {
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			slog.Error("Failed to listen for gRPC", "host", host, "port", port, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Serving gRPC", "address", listener.Addr().String())
+		go func() {
+			if err := server.Serve(listener); err != nil {
+				slog.Error("gRPC server failed", "error", err)
+			}
+		}()
+	})
+	OnTerm(func() {
+		server.GracefulStop()
+	})
+}
@@ -0,0 +1,237 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servenv
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/multigres/multigres/go/mterrors"
+	"github.com/multigres/multigres/go/stats"
+)
+
+// This file implements the HTTP server of the binaries, on --http-port.
+// It serves:
+//
+//   - /healthz: 200 as long as the process runs.
+//   - /readyz: 200 when the binary is serving and all its readiness checks
+//     pass, 503 otherwise.
+//   - /debug/vars: the expvars, without the command line.
+//   - /debug/pprof: the Go profiles, without the command line.
+//   - /metrics: the Prometheus metrics.
+//   - /debug/status: an HTML page describing the binary.
+//
+// Binaries and packages add their own pages with HTTPHandle. The server
+// listens on localhost by default: it is not authenticated.
+
+var (
+	// defaultHTTPPort is the default of --http-port.
+	defaultHTTPPort int
+
+	// httpPort is the port of the HTTP server. 0 disables it.
+	httpPort int
+
+	// httpBindAddress is the address of the HTTP server, all the
+	// interfaces if empty.
+	httpBindAddress string
+
+	// mux is the handler of the HTTP server.
+	mux = http.NewServeMux()
+
+	// serving is true between the end of the OnRun hooks and the
+	// reception of a termination signal.
+	serving atomic.Bool
+
+	// readinessChecksMu protects readinessChecks.
+	readinessChecksMu sync.Mutex
+	readinessChecks   = map[string]func() error{}
+)
+
+func init() {
+	stats.PublishExpvarCounters(mterrors.ErrorCountsName, mterrors.ErrorCountsName,
+		"Errors returned by the components, by code and state", mterrors.ErrorCountsLabels)
+
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", handleReadyz)
+	// The command line may hold secrets, like --pg-password: neither the
+	// expvars nor pprof serve it.
+	mux.HandleFunc("/debug/vars", handleExpvars)
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/metrics", stats.Handler())
+	mux.HandleFunc("/debug/status", handleStatus)
+}
+
+// SetDefaultHTTPPort sets the default of --http-port. Binaries call it
+// before Init, so that they don't conflict when run on the same host.
+func SetDefaultHTTPPort(port int) {
+	defaultHTTPPort = port
+}
+
+// HTTPPort returns the port of the HTTP server, 0 if it is disabled.
+func HTTPPort() int {
+	return httpPort
+}
+
+// HTTPHandle registers handler for pattern on the HTTP server.
+func HTTPHandle(pattern string, handler http.Handler) {
+	mux.Handle(pattern, handler)
+}
+
+// HTTPHandleFunc registers handler for pattern on the HTTP server.
+func HTTPHandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	mux.HandleFunc(pattern, handler)
+}
+
+// HTTPHandler returns the handler of the HTTP server.
+func HTTPHandler() http.Handler {
+	return mux
+}
+
+// AddReadinessCheck adds a check to /readyz. The binary is only ready when
+// check returns nil.
+func AddReadinessCheck(name string, check func() error) {
+	readinessChecksMu.Lock()
+	defer readinessChecksMu.Unlock()
+	readinessChecks[name] = check
+}
+
+// IsServing returns true if the binary is serving: its OnRun hooks
+// completed and it did not receive a termination signal.
+func IsServing() bool {
+	return serving.Load()
+}
+
+// readinessErrors returns the failures of the readiness checks, sorted by
+// check name.
+func readinessErrors() []string {
+	var failures []string
+	if !serving.Load() {
+		failures = append(failures, "not serving")
+	}
+
+	readinessChecksMu.Lock()
+	defer readinessChecksMu.Unlock()
+	names := make([]string, 0, len(readinessChecks))
+	for name := range readinessChecks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := readinessChecks[name](); err != nil {
+			failures = append(failures, name+": "+err.Error())
+		}
+	}
+	return failures
+}
+
+func handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if failures := readinessErrors(); len(failures) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, strings.Join(failures, "\n"))
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// handleExpvars serves the expvars in JSON, like expvar.Handler, except
+// the command line.
+func handleExpvars(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprintf(w, "{\n")
+	first := true
+	expvar.Do(func(kv expvar.KeyValue) {
+		if kv.Key == "cmdline" {
+			return
+		}
+		if !first {
+			fmt.Fprintf(w, ",\n")
+		}
+		first = false
+		fmt.Fprintf(w, "%q: %s", kv.Key, kv.Value)
+	})
+	fmt.Fprintf(w, "\n}\n")
+}
+
+// startHTTPServer starts serving HTTP on --http-bind-address and
+// --http-port, if not 0. It returns the function stopping the server.
+func startHTTPServer() (func(), error) {
+	if httpPort == 0 {
+		return func() {}, nil
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(httpBindAddress, strconv.Itoa(httpPort)))
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("HTTP server failed", "error", err)
+		}
+	}()
+	slog.Info("Serving HTTP", "address", listener.Addr().String())
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+// ServeHTTP serves handler on host and port while the binary runs, apart
+// from the HTTP server: it starts listening in an OnRun hook, and stops in
+// an OnTerm hook, after the ongoing requests complete. An empty host
+// listens on all the interfaces. The binary exits if it can't listen.
+func ServeHTTP(handler http.Handler, host string, port int) {
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	OnRun(func() {
+		listener, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			slog.Error("Failed to listen for HTTP", "host", host, "port", port, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Serving HTTP", "address", listener.Addr().String())
+		go func() {
+			if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("HTTP server failed", "error", err)
+			}
+		}()
+	})
+	OnTerm(func() {
+		_ = server.Shutdown(context.Background())
+	})
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servenv_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/clustermetadata/topo/memorytopo"
+	"github.com/multigres/multigres/go/mterrors"
+	clustermetadatapb "github.com/multigres/multigres/go/pb/clustermetadata"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	"github.com/multigres/multigres/go/servenv"
+)
+
+// get returns the status code and the body of the response to path.
+func get(t *testing.T, server *httptest.Server, path string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestHTTPEndpoints(t *testing.T) {
+	server := httptest.NewServer(servenv.HTTPHandler())
+	defer server.Close()
+
+	code, body := get(t, server, "/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok\n", body)
+
+	code, body = get(t, server, "/debug/vars")
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, `"ErrorCounts"`)
+	require.NotContains(t, body, `"cmdline"`)
+
+	mterrors.CountError("Status", mterrors.New(mtrpcpb.Code_NOT_FOUND, "no such page"))
+	code, body = get(t, server, "/metrics")
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, "go_goroutines")
+	require.Contains(t, body, `error_counts{code="NOT_FOUND",component="Status",state="undefined"} 1`)
+
+	code, _ = get(t, server, "/debug/pprof/")
+	require.Equal(t, http.StatusOK, code)
+	code, _ = get(t, server, "/debug/pprof/cmdline")
+	require.Equal(t, http.StatusNotFound, code)
+
+	// The binary is not ready until Run is called.
+	servenv.AddReadinessCheck("pool", func() error { return errors.New("pool is empty") })
+	defer servenv.AddReadinessCheck("pool", func() error { return nil })
+	code, body = get(t, server, "/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "not serving\npool: pool is empty\n", body)
+}
+
+func TestStatusPage(t *testing.T) {
+	fs := newFlagSet(t)
+	fs.String("pg-password", "", "PostgreSQL password")
+	require.NoError(t, servenv.ParseFlags(fs, "multipooler", []string{"--pg-password", "hunter2", "--grpc-port", "16100"}))
+
+	ctx := context.Background()
+	ts, factory := memorytopo.NewServerAndFactory(ctx, "zone1")
+	defer ts.Close()
+	factory.AddOperationError(memorytopo.Get, "poolers/", topo.NewError(topo.Timeout, "poolers"))
+	_, err := ts.GetMultiPooler(ctx, &clustermetadatapb.ID{Component: clustermetadatapb.ID_MULTIPOOLER, Cell: "zone1", Name: "1"})
+	require.Error(t, err)
+	servenv.AddTopoStatusPart(ts)
+
+	var registered atomic.Pointer[clustermetadatapb.MultiPooler]
+	servenv.AddRecordStatusPart("MultiPooler", func() proto.Message {
+		if record := registered.Load(); record != nil {
+			return record
+		}
+		return nil
+	})
+	servenv.AddStatusPart("Pool", `<p>{{.}} connections</p>`, func() any { return "<b>7</b>" })
+
+	server := httptest.NewServer(servenv.HTTPHandler())
+	defer server.Close()
+
+	code, body := get(t, server, "/debug/status")
+	require.Equal(t, http.StatusOK, code)
+	require.Contains(t, body, "<h1>multipooler on ")
+	require.Contains(t, body, "not serving")
+	require.Contains(t, body, "<td>grpc-port</td><td>16100</td><td>15100</td>")
+	require.Contains(t, body, "<td>pg-password</td><td>&lt;redacted&gt;</td>")
+	require.NotContains(t, body, "hunter2")
+	require.Contains(t, body, "<td>zone1</td><td>closed</td><td>1</td><td>topo error")
+	require.Contains(t, body, "Not registered.")
+	require.Contains(t, body, "<p>&lt;b&gt;7&lt;/b&gt; connections</p>")
+
+	registered.Store(&clustermetadatapb.MultiPooler{Hostname: "host1"})
+	_, body = get(t, server, "/debug/status")
+	require.Contains(t, body, `&#34;hostname&#34;: &#34;host1&#34;`)
+}
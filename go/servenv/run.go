@@ -0,0 +1,131 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servenv
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+var (
+	onInitHooks   hooks
+	onRunHooks    hooks
+	onReloadHooks hooks
+	onTermHooks   hooks
+	onCloseHooks  hooks
+)
+
+// hooks is a list of functions fired in the order they were added.
+type hooks struct {
+	mu    sync.Mutex
+	funcs []func()
+}
+
+// Add adds f to the hooks.
+func (h *hooks) Add(f func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.funcs = append(h.funcs, f)
+}
+
+// Fire calls the hooks in order.
+func (h *hooks) Fire() {
+	h.mu.Lock()
+	funcs := append([]func(){}, h.funcs...)
+	h.mu.Unlock()
+	for _, f := range funcs {
+		f()
+	}
+}
+
+// FireWithTimeout calls the hooks in order, and waits at most timeout for
+// them to complete. It returns false if they did not complete in time, in
+// which case they keep running in the background.
+func (h *hooks) FireWithTimeout(name string, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.Fire()
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		slog.Warn("hooks did not complete in time", "hooks", name, "timeout", timeout)
+		return false
+	}
+}
+
+// OnInit registers f to be called at the end of Init, once the
+// configuration is loaded and the logging is set up.
+func OnInit(f func()) {
+	onInitHooks.Add(f)
+}
+
+// OnRun registers f to be called when Run starts. The binaries start
+// serving in their OnRun hooks.
+func OnRun(f func()) {
+	onRunHooks.Add(f)
+}
+
+// OnReload registers f to be called after the configuration is reloaded,
+// when the config file changes or on SIGHUP. The OnReload hooks refresh
+// what the binary derives from its configuration. They are not called if
+// the new configuration is invalid.
+func OnReload(f func()) {
+	onReloadHooks.Add(f)
+}
+
+// OnTerm registers f to be called when the binary is asked to terminate.
+// The OnTerm hooks stop accepting new work and drain the ongoing work.
+func OnTerm(f func()) {
+	onTermHooks.Add(f)
+}
+
+// OnClose registers f to be called after the OnTerm hooks. The OnClose
+// hooks release the resources of the binary, like its connections.
+func OnClose(f func()) {
+	onCloseHooks.Add(f)
+}
+
+// Run starts the HTTP server and fires the OnRun hooks, then waits for
+// SIGINT or SIGTERM and shuts the binary down gracefully: it stops being
+// ready, the OnTerm hooks are fired, then the OnClose hooks, each within
+// their timeout, and the HTTP server is stopped.
+func Run() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	stopHTTPServer, err := startHTTPServer()
+	if err != nil {
+		slog.Error("Failed to start HTTP server", "port", httpPort, "error", err)
+		os.Exit(1)
+	}
+	defer stopHTTPServer()
+
+	onRunHooks.Fire()
+	serving.Store(true)
+
+	<-ctx.Done()
+	slog.Info("shutting down " + binaryName)
+	serving.Store(false)
+	onTermHooks.FireWithTimeout("OnTerm", onTermTimeout.Get())
+	onCloseHooks.FireWithTimeout("OnClose", onCloseTimeout.Get())
+}
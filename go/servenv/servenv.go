@@ -0,0 +1,224 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package servenv contains the environment shared by the Multigres
+// binaries: their configuration, their logging, and their lifecycle.
+//
+// Every flag of a binary can also be set through an environment variable
+// prefixed with the binary name in uppercase (MULTIPOOLER_LOG_LEVEL for
+// --log-level), or in a YAML config file named after the binary and looked
+// up in ".", "./config" and "/etc/multigres", unless --config is given.
+// Flags take precedence over environment variables, which take precedence
+// over the config file. The dynamic values, like --log-level, are
+// reloaded when the config file changes or on SIGHUP. Init also sets up
+// the tracing of the binary, as configured by the --tracing_* flags.
+//
+// A binary registers its own flags on pflag.CommandLine, then calls Init,
+// registers its hooks and calls Run:
+//
+//	func main() {
+//		pflag.StringP("grpc-port", "p", "15100", "gRPC port to listen on")
+//		servenv.Init("multipooler")
+//		servenv.OnRun(func() { ... })
+//		servenv.OnTerm(func() { ... })
+//		servenv.Run()
+//	}
+//
+// The hooks are fired in this order:
+//
+//   - OnInit hooks at the end of Init, once the configuration is loaded.
+//   - OnRun hooks at the beginning of Run, to start serving.
+//   - OnReload hooks after every reload of the configuration.
+//   - OnTerm hooks when SIGINT or SIGTERM is received, to stop serving.
+//     They are given --onterm-timeout to complete.
+//   - OnClose hooks after the OnTerm hooks, to release the resources.
+//     They are given --onclose-timeout to complete.
+package servenv
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	"github.com/multigres/multigres/go/stats"
+	"github.com/multigres/multigres/go/tracing"
+)
+
+var (
+	// binaryName is the name of the binary, as given to Init.
+	binaryName string
+
+	// configFile is the path of the config file, if not looked up.
+	configFile string
+
+	// logLevel is the minimum level of the logged messages.
+	logLevel = newLogLevel()
+
+	// onTermTimeout is the time given to the OnTerm hooks to complete.
+	onTermTimeout = NewDynamicDuration("onterm-timeout", 10*time.Second, "time to wait for the OnTerm hooks to complete on shutdown")
+
+	// onCloseTimeout is the time given to the OnClose hooks to complete.
+	onCloseTimeout = NewDynamicDuration("onclose-timeout", 10*time.Second, "time to wait for the OnClose hooks to complete on shutdown")
+
+	// levelVar is the level of the default logger, kept in sync with
+	// logLevel.
+	levelVar slog.LevelVar
+
+	// logLevels maps the values of --log-level to their level.
+	logLevels = map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+)
+
+func newLogLevel() *Dynamic[string] {
+	d := NewDynamicString("log-level", "info", "Log level (debug, info, warn, error)")
+	d.shorthand = "l"
+	d.WithValidator(func(level string) error {
+		if _, ok := logLevels[level]; !ok {
+			return mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "unknown log level %q", level)
+		}
+		return nil
+	})
+	d.OnChange(func(_, level string) {
+		levelVar.Set(logLevels[level])
+	})
+	return d
+}
+
+// Init loads the configuration of the binary from the command line,
+// the environment and the config file, sets up the logging and fires the
+// OnInit hooks. It exits the process if the configuration is invalid.
+func Init(name string) {
+	if err := ParseFlags(pflag.CommandLine, name, os.Args[1:]); err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+	SetupLogging()
+	shutdownTracing, err := tracing.Init(context.Background(), name)
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+	OnClose(func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Warn("Failed to flush the trace spans", "error", err)
+		}
+	})
+	watchConfig()
+	onInitHooks.Fire()
+}
+
+// RegisterFlags registers the flags shared by all binaries, and the
+// dynamic values, on fs. The topology flags are only registered for the
+// binaries listed in topo.FlagBinaries.
+func RegisterFlags(fs *pflag.FlagSet, name string) {
+	fs.StringVarP(&configFile, "config", "c", "", "Config file path")
+	fs.IntVar(&httpPort, "http-port", defaultHTTPPort, "port of the HTTP server for the status, health and debug pages, 0 to disable")
+	fs.StringVar(&httpBindAddress, "http-bind-address", "localhost", "address of the HTTP server, all the interfaces if empty. The server is not authenticated: only expose it to trusted clients")
+	registerSettings(fs)
+	mterrors.RegisterFlags(fs)
+	tracing.RegisterFlags(fs)
+	if slices.Contains(topo.FlagBinaries, name) {
+		topo.RegisterFlags(fs)
+	}
+}
+
+// ParseFlags registers the shared flags on fs and parses args, then sets
+// the flags that were not given on the command line from the environment
+// and the config file of the binary. The configuration is loaded in the
+// global viper instance, which is bound to fs.
+func ParseFlags(fs *pflag.FlagSet, name string, args []string) error {
+	binaryName = name
+	flagSet = fs
+	stats.SetNamespace(name)
+	RegisterFlags(fs, name)
+	if err := fs.Parse(args); err != nil {
+		return mterrors.Wrap(err, "failed to parse flags")
+	}
+	commandLine = map[string]bool{}
+	fs.Visit(func(f *pflag.Flag) {
+		commandLine[f.Name] = true
+	})
+	if err := viper.BindPFlags(fs); err != nil {
+		return mterrors.Wrap(err, "failed to bind flags")
+	}
+
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
+	} else {
+		viper.SetConfigName(name)
+		viper.SetConfigType("yaml")
+		viper.AddConfigPath(".")
+		viper.AddConfigPath("./config")
+		viper.AddConfigPath("/etc/multigres")
+	}
+
+	viper.SetEnvPrefix(strings.ToUpper(name))
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
+
+	if err := viper.ReadInConfig(); err != nil {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
+			return mterrors.Wrap(err, "failed to read config file")
+		}
+	}
+	return applyConfig(fs)
+}
+
+// applyConfig sets the flags of fs that were not given on the command
+// line from the viper configuration, so that the variables bound to the
+// flags see the values of the environment and the config file.
+func applyConfig(fs *pflag.FlagSet) error {
+	var err error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if err != nil || f.Changed || !viper.IsSet(f.Name) {
+			return
+		}
+		value := viper.GetString(f.Name)
+		if strings.HasSuffix(f.Value.Type(), "Slice") {
+			value = strings.Join(viper.GetStringSlice(f.Name), ",")
+		}
+		if setErr := fs.Set(f.Name, value); setErr != nil {
+			err = mterrors.Wrapf(setErr, "invalid value for %v", f.Name)
+		}
+		// The flag was not given on the command line: it must not take
+		// precedence over the environment and the config file in viper
+		// when they change.
+		f.Changed = false
+	})
+	return err
+}
+
+// SetupLogging sets the default slog logger to a JSON logger on stdout,
+// at the level given by --log-level. The level follows the changes of
+// --log-level.
+func SetupLogging() {
+	levelVar.Set(logLevels[logLevel.Get()])
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: &levelVar,
+	}))
+	slog.SetDefault(logger)
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servenv_test
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/servenv"
+)
+
+// newFlagSet returns a FlagSet with a flag of the binary, and resets the
+// global viper instance once the test is done.
+func newFlagSet(t *testing.T) *pflag.FlagSet {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	fs := pflag.NewFlagSet("multipooler", pflag.ContinueOnError)
+	fs.StringP("grpc-port", "p", "15100", "gRPC port to listen on")
+	return fs
+}
+
+func TestParseFlags(t *testing.T) {
+	fs := newFlagSet(t)
+	configFile := filepath.Join(t.TempDir(), "multipooler.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte(`
+grpc-port: "16000"
+log-level: debug
+onterm-timeout: 3s
+onclose-timeout: 3s
+topo_global_root: /multigres/global
+topo_global_server_addresses:
+  - 10.0.0.1:2379
+  - 10.0.0.2:2379
+`), 0o644))
+	t.Setenv("MULTIPOOLER_ONCLOSE_TIMEOUT", "4s")
+	t.Setenv("MULTIPOOLER_GRPC_PORT", "17000")
+
+	require.NoError(t, servenv.ParseFlags(fs, "multipooler", []string{"--config", configFile, "-l", "warn"}))
+
+	value := func(name string) string {
+		t.Helper()
+		f := fs.Lookup(name)
+		require.NotNil(t, f, name)
+		return f.Value.String()
+	}
+	// The command line takes precedence over the environment, which takes
+	// precedence over the config file.
+	require.Equal(t, "warn", value("log-level"))
+	require.Equal(t, "4s", value("onclose-timeout"))
+	require.Equal(t, "3s", value("onterm-timeout"))
+	require.Equal(t, "17000", value("grpc-port"))
+	require.Equal(t, "17000", viper.GetString("grpc-port"))
+
+	// The topology and error flags are registered for the binary.
+	require.Equal(t, "/multigres/global", value("topo_global_root"))
+	require.Equal(t, "[10.0.0.1:2379,10.0.0.2:2379]", value("topo_global_server_addresses"))
+	require.NotNil(t, fs.Lookup("log_err_stacks"))
+}
+
+func TestParseFlagsErrors(t *testing.T) {
+	fs := newFlagSet(t)
+	require.Error(t, servenv.ParseFlags(fs, "multipooler", []string{"--no-such-flag"}))
+
+	fs = newFlagSet(t)
+	t.Setenv("MULTIPOOLER_ONTERM_TIMEOUT", "soon")
+	require.ErrorContains(t, servenv.ParseFlags(fs, "multipooler", []string{"--config", filepath.Join(t.TempDir(), "none.yaml")}), "failed to read config file")
+
+	fs = newFlagSet(t)
+	require.ErrorContains(t, servenv.ParseFlags(fs, "multipooler", nil), "invalid value for onterm-timeout")
+}
+
+// freePort returns a port that is free to listen on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestRun(t *testing.T) {
+	fs := newFlagSet(t)
+	port := freePort(t)
+	require.NoError(t, servenv.ParseFlags(fs, "multipooler", []string{"--onterm-timeout", "50ms", "--http-port", strconv.Itoa(port)}))
+
+	var mu sync.Mutex
+	var fired []string
+	record := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		fired = append(fired, name)
+	}
+
+	// The OnTerm hook does not complete in time, the OnClose hooks are
+	// fired anyway.
+	release := make(chan struct{})
+	defer close(release)
+	servenv.OnRun(func() { record("run") })
+	servenv.OnTerm(func() {
+		record("term")
+		<-release
+	})
+	servenv.OnClose(func() { record("close1") })
+	servenv.OnClose(func() { record("close2") })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		servenv.Run()
+	}()
+
+	// The binary is ready once the OnRun hooks are done.
+	readyz := fmt.Sprintf("http://localhost:%d/readyz", port)
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(readyz)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, servenv.IsServing())
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after SIGTERM")
+	}
+	require.False(t, servenv.IsServing())
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"run", "term", "close1", "close2"}, fired)
+
+	// The HTTP server is stopped.
+	_, err := http.Get(readyz)
+	require.Error(t, err)
+}
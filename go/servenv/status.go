@@ -0,0 +1,234 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package servenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/multigres/multigres/go/clustermetadata/topo"
+	"github.com/multigres/multigres/go/mterrors"
+)
+
+// This file implements the /debug/status page. It shows the state and
+// the configuration of the binary, followed by the status parts added by
+// the binary and its packages with AddStatusPart.
+
+var (
+	// startTime is the time the binary started.
+	startTime = time.Now()
+
+	// flagSet is the FlagSet given to ParseFlags, shown on the page.
+	flagSet *pflag.FlagSet
+
+	// statusPartsMu protects statusParts.
+	statusPartsMu sync.Mutex
+	statusParts   []statusPart
+
+	// sensitiveFlagWords are the words that mark a flag as sensitive. The
+	// value of sensitive flags is not shown.
+	sensitiveFlagWords = []string{"password", "secret", "token"}
+)
+
+// statusPart is a section of the status page.
+type statusPart struct {
+	title string
+	tmpl  *template.Template
+	data  func() any
+}
+
+// AddStatusPart adds a section to the status page. The section is the
+// result of the html/template frag executed with the value returned by
+// data. It panics if frag is not a valid template.
+func AddStatusPart(title, frag string, data func() any) {
+	tmpl := template.Must(template.New(title).Funcs(statusFuncs).Parse(frag))
+	statusPartsMu.Lock()
+	defer statusPartsMu.Unlock()
+	statusParts = append(statusParts, statusPart{title: title, tmpl: tmpl, data: data})
+}
+
+// AddTopoStatusPart adds a section showing the state of the connections
+// of ts to the cell topology servers.
+func AddTopoStatusPart(ts topo.Store) {
+	AddStatusPart("Topology", topoStatusTemplate, func() any {
+		return ts.CircuitBreakerStatuses()
+	})
+}
+
+// AddRecordStatusPart adds a section showing the record returned by
+// record, like the record the binary registered in the topology. The
+// record is nil until it is registered.
+func AddRecordStatusPart(title string, record func() proto.Message) {
+	AddStatusPart(title, recordStatusTemplate, func() any {
+		rec := record()
+		if rec == nil {
+			return ""
+		}
+		data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(rec)
+		if err != nil {
+			return err.Error()
+		}
+		// protojson output is not stable, indent it ourselves.
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return err.Error()
+		}
+		return buf.String()
+	})
+}
+
+// statusFuncs are the functions available to the status templates.
+var statusFuncs = template.FuncMap{
+	"since": func(t time.Time) time.Duration {
+		return time.Since(t).Truncate(time.Second)
+	},
+	"timestamp": func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	},
+}
+
+const topoStatusTemplate = `
+{{if .}}
+<table>
+  <tr><th>Cell</th><th>State</th><th>Consecutive failures</th><th>Last error</th><th>Opened at</th></tr>
+  {{range .}}
+  <tr><td>{{.Cell}}</td><td>{{.State}}</td><td>{{.ConsecutiveFailures}}</td><td>{{if .LastError}}{{.LastError}}{{end}}</td><td>{{timestamp .OpenedAt}}</td></tr>
+  {{end}}
+</table>
+{{else}}
+No cell accessed yet.
+{{end}}
+`
+
+const recordStatusTemplate = `
+{{if .}}<pre>{{.}}</pre>{{else}}Not registered.{{end}}
+`
+
+const statusTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<title>{{.Binary}} on {{.Hostname}}</title>
+<style>
+  body { font-family: sans-serif; margin: 1em 2em; }
+  h2 { border-bottom: 1px solid #ccc; }
+  table { border-collapse: collapse; }
+  th, td { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; vertical-align: top; }
+  .notready { color: #c00; }
+</style>
+</head>
+<body>
+<h1>{{.Binary}} on {{.Hostname}}</h1>
+<table>
+  <tr><th>Started</th><td>{{timestamp .StartTime}}</td></tr>
+  <tr><th>Uptime</th><td>{{since .StartTime}}</td></tr>
+  <tr><th>Ready</th><td>{{if .NotReady}}<span class="notready">{{range .NotReady}}{{.}}<br>{{end}}</span>{{else}}yes{{end}}</td></tr>
+</table>
+{{range .Parts}}
+<h2>{{.Title}}</h2>
+{{.HTML}}
+{{end}}
+<h2>Configuration</h2>
+<table>
+  <tr><th>Flag</th><th>Value</th><th>Default</th></tr>
+  {{range .Flags}}
+  <tr><td>{{.Name}}</td><td>{{.Value}}</td><td>{{.Default}}</td></tr>
+  {{end}}
+</table>
+</body>
+</html>
+`
+
+var statusPage = template.Must(template.New("status").Funcs(statusFuncs).Parse(statusTemplate))
+
+// statusFlag is a flag shown on the status page.
+type statusFlag struct {
+	Name, Value, Default string
+}
+
+// statusFlags returns the flags of the binary, with the values of the
+// sensitive flags redacted.
+func statusFlags() []statusFlag {
+	if flagSet == nil {
+		return nil
+	}
+	var flags []statusFlag
+	flagSet.VisitAll(func(f *pflag.Flag) {
+		flag := statusFlag{Name: f.Name, Value: f.Value.String(), Default: f.DefValue}
+		for _, word := range sensitiveFlagWords {
+			if strings.Contains(f.Name, word) {
+				flag.Value = mterrors.RedactedPlaceholder
+				flag.Default = mterrors.RedactedPlaceholder
+			}
+		}
+		flags = append(flags, flag)
+	})
+	return flags
+}
+
+func handleStatus(w http.ResponseWriter, r *http.Request) {
+	type renderedPart struct {
+		Title string
+		HTML  template.HTML
+	}
+	statusPartsMu.Lock()
+	parts := append([]statusPart{}, statusParts...)
+	statusPartsMu.Unlock()
+	rendered := make([]renderedPart, 0, len(parts))
+	for _, part := range parts {
+		var buf bytes.Buffer
+		if err := part.tmpl.Execute(&buf, part.data()); err != nil {
+			slog.Error("Failed to render status part", "title", part.title, "error", err)
+			buf.Reset()
+			template.HTMLEscape(&buf, []byte(err.Error()))
+		}
+		// The part was rendered by html/template, it is safe.
+		rendered = append(rendered, renderedPart{Title: part.title, HTML: template.HTML(buf.String())})
+	}
+
+	hostname, _ := os.Hostname()
+	data := struct {
+		Binary    string
+		Hostname  string
+		StartTime time.Time
+		NotReady  []string
+		Parts     []renderedPart
+		Flags     []statusFlag
+	}{
+		Binary:    binaryName,
+		Hostname:  hostname,
+		StartTime: startTime,
+		NotReady:  readinessErrors(),
+		Parts:     rendered,
+		Flags:     statusFlags(),
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusPage.Execute(w, data); err != nil {
+		slog.Error("Failed to render status page", "error", err)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"expvar"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// expvarCounters exposes an expvar.Map of counters to Prometheus.
+type expvarCounters struct {
+	expvarName string
+	name, help string
+	labels     []string
+}
+
+// PublishExpvarCounters exposes to Prometheus, as name, the counters of
+// the expvar.Map published as expvarName by another package. The keys of
+// the map are the values of labels joined with ".", the values are
+// expvar.Int or expvar.Float. The expvar itself is left as is.
+func PublishExpvarCounters(expvarName, name, help string, labels []string) {
+	c := &expvarCounters{expvarName: expvarName, name: name, help: help, labels: labels}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := metrics[name]; ok {
+		panic(fmt.Sprintf("stats: metric %v is already published", name))
+	}
+	metrics[name] = c
+}
+
+func (c *expvarCounters) collect(namespace string, ch chan<- prometheus.Metric) {
+	m, ok := expvar.Get(c.expvarName).(*expvar.Map)
+	if !ok {
+		return
+	}
+	desc := newDesc(namespace, c.name, c.help, c.labels)
+	m.Do(func(kv expvar.KeyValue) {
+		values := strings.Split(kv.Key, ".")
+		if len(values) != len(c.labels) {
+			slog.Warn("Ignoring expvar key with unexpected labels", "expvar", c.expvarName, "key", kv.Key)
+			return
+		}
+		var value float64
+		switch v := kv.Value.(type) {
+		case *expvar.Int:
+			value = float64(v.Value())
+		case *expvar.Float:
+			value = v.Value()
+		default:
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, value, values...)
+	})
+}
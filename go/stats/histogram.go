@@ -0,0 +1,162 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultTimingBuckets are the buckets of the timings, in seconds.
+var DefaultTimingBuckets = []float64{0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10}
+
+// histogramData is the histogram of a set of label values.
+type histogramData struct {
+	values []string
+	count  uint64
+	sum    float64
+	// buckets contains the number of observations less than or equal to
+	// each upper bound, not cumulated.
+	buckets []uint64
+}
+
+// HistogramSnapshot is the state of a histogram for a set of label
+// values.
+type HistogramSnapshot struct {
+	Count uint64
+	Sum   float64
+}
+
+// Histogram counts observations in buckets, per set of values of its
+// labels.
+type Histogram struct {
+	name, help string
+	labels     []string
+	bounds     []float64
+
+	// mu protects data.
+	mu   sync.Mutex
+	data map[string]*histogramData
+}
+
+// NewHistogram returns a new histogram published as name, with the given
+// bucket upper bounds and labels.
+func NewHistogram(name, help string, bounds []float64, labels []string) *Histogram {
+	h := &Histogram{
+		name:   name,
+		help:   help,
+		labels: labels,
+		bounds: append([]float64(nil), bounds...),
+		data:   map[string]*histogramData{},
+	}
+	sort.Float64s(h.bounds)
+	publish(name, h)
+	return h
+}
+
+// Observe adds value to the histogram of the label values.
+func (h *Histogram) Observe(values []string, value float64) {
+	checkLabels(h.name, h.labels, values)
+	key := labelsKey(values)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	d, ok := h.data[key]
+	if !ok {
+		d = &histogramData{
+			values:  append([]string(nil), values...),
+			buckets: make([]uint64, len(h.bounds)),
+		}
+		h.data[key] = d
+	}
+	d.count++
+	d.sum += value
+	if i := sort.SearchFloat64s(h.bounds, value); i < len(h.bounds) {
+		d.buckets[i]++
+	}
+}
+
+// Labels returns the names of the labels.
+func (h *Histogram) Labels() []string {
+	return h.labels
+}
+
+// Snapshots returns the state of the histograms, keyed by their label
+// values joined with ".".
+func (h *Histogram) Snapshots() map[string]HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	snapshots := make(map[string]HistogramSnapshot, len(h.data))
+	for key, d := range h.data {
+		snapshots[key] = HistogramSnapshot{Count: d.count, Sum: d.sum}
+	}
+	return snapshots
+}
+
+// String implements expvar.Var.
+func (h *Histogram) String() string {
+	data, err := json.Marshal(h.Snapshots())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+func (h *Histogram) collect(namespace string, ch chan<- prometheus.Metric) {
+	desc := newDesc(namespace, h.name, h.help, h.labels)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	keys := make([]string, 0, len(h.data))
+	for key := range h.data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		d := h.data[key]
+		buckets := make(map[float64]uint64, len(h.bounds))
+		var cumulated uint64
+		for i, bound := range h.bounds {
+			cumulated += d.buckets[i]
+			buckets[bound] = cumulated
+		}
+		ch <- prometheus.MustNewConstHistogram(desc, d.count, d.sum, buckets, d.values...)
+	}
+}
+
+// MultiTimings is a histogram of durations, in seconds, per set of values
+// of its labels.
+type MultiTimings struct {
+	*Histogram
+}
+
+// NewMultiTimings returns new timings published as name, with the given
+// labels and DefaultTimingBuckets.
+func NewMultiTimings(name, help string, labels []string) *MultiTimings {
+	return &MultiTimings{Histogram: NewHistogram(name, help, DefaultTimingBuckets, labels)}
+}
+
+// Add adds the duration d to the timings of the label values.
+func (t *MultiTimings) Add(values []string, d time.Duration) {
+	t.Observe(values, d.Seconds())
+}
+
+// Record adds the time elapsed since start to the timings of the label
+// values.
+func (t *MultiTimings) Record(values []string, start time.Time) {
+	t.Add(values, time.Since(start))
+}
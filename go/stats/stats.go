@@ -0,0 +1,175 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stats provides the metrics shared by the Multigres binaries:
+// counters, gauges, histograms and timings, with or without labels.
+//
+// Metrics are declared at the package level with a CamelCase name:
+//
+//	var topoOperations = stats.NewMultiTimings(
+//		"TopologyConnOperations", "Timings of the topology operations",
+//		[]string{"Operation", "Cell"})
+//
+// Each metric is published twice:
+//
+//   - as an expvar with its name, in JSON on /debug/vars.
+//   - as a Prometheus metric by Handler, on /metrics. Its name and the
+//     names of its labels are converted to snake_case, and prefixed with
+//     the namespace of the binary: multipooler_topology_conn_operations.
+//
+// The label values of a metric are joined with "." in the expvar keys,
+// dots in the values are replaced with "_". Metrics with an empty name
+// are not published, they are meant to be used by tests.
+//
+// Expvars published by other packages are exposed to Prometheus with
+// PublishExpvarCounters.
+package stats
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// metric is implemented by all the metrics published to Prometheus.
+type metric interface {
+	// collect sends the Prometheus samples of the metric, with their
+	// names prefixed by namespace.
+	collect(namespace string, ch chan<- prometheus.Metric)
+}
+
+var (
+	// mu protects the following fields.
+	mu        sync.Mutex
+	namespace string
+	metrics   = map[string]metric{}
+
+	// registry contains the metrics served by Handler: the metrics of
+	// this package, and the Go runtime and process metrics.
+	registry = prometheus.NewRegistry()
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		statsCollector{},
+	)
+}
+
+// SetNamespace sets the prefix of the Prometheus names of the metrics,
+// usually the name of the binary.
+func SetNamespace(ns string) {
+	mu.Lock()
+	defer mu.Unlock()
+	namespace = ns
+}
+
+// Handler returns the handler serving the metrics in the Prometheus
+// exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// publish publishes m as name, as an expvar and to Prometheus. It panics
+// if name is already published.
+func publish(name string, m interface {
+	metric
+	expvar.Var
+},
+) {
+	if name == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := metrics[name]; ok {
+		panic(fmt.Sprintf("stats: metric %v is already published", name))
+	}
+	metrics[name] = m
+	expvar.Publish(name, m)
+}
+
+// statsCollector collects the metrics of this package. It is unchecked:
+// the names of the metrics depend on the namespace, which is set after
+// the metrics are declared.
+type statsCollector struct{}
+
+// Describe implements prometheus.Collector.
+func (statsCollector) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (statsCollector) Collect(ch chan<- prometheus.Metric) {
+	mu.Lock()
+	ns := namespace
+	all := make([]metric, 0, len(metrics))
+	for _, m := range metrics {
+		all = append(all, m)
+	}
+	mu.Unlock()
+	for _, m := range all {
+		m.collect(ns, ch)
+	}
+}
+
+// newDesc returns the Prometheus description of a metric.
+func newDesc(namespace, name, help string, labels []string) *prometheus.Desc {
+	promLabels := make([]string, len(labels))
+	for i, label := range labels {
+		promLabels[i] = toSnakeCase(label)
+	}
+	return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", toSnakeCase(name)), help, promLabels, nil)
+}
+
+// toSnakeCase converts a CamelCase name to snake_case. Acronyms are kept
+// together: "TopoRPCErrors" becomes "topo_rpc_errors".
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' &&
+				(unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+					(i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// labelsKey returns the expvar key of the label values.
+func labelsKey(values []string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = strings.ReplaceAll(value, ".", "_")
+	}
+	return strings.Join(escaped, ".")
+}
+
+// checkLabels panics if values do not match labels.
+func checkLabels(name string, labels, values []string) {
+	if len(labels) != len(values) {
+		panic(fmt.Sprintf("stats: %v has %d labels, got %d values", name, len(labels), len(values)))
+	}
+}
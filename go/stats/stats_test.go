@@ -0,0 +1,114 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats_test
+
+import (
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/multigres/multigres/go/stats"
+)
+
+// scrape returns the metrics served by stats.Handler.
+func scrape(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(stats.Handler())
+	defer server.Close()
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	stats.SetNamespace("multitest")
+
+	queries := stats.NewCounter("TestQueries", "Queries served")
+	queries.Add(3)
+	connections := stats.NewGauge("TestConnections", "Open connections")
+	connections.Set(5)
+	connections.Add(-1)
+	stats.NewGaugeFunc("TestPoolCapacity", "Capacity of the pool", func() int64 { return 100 })
+	rpcErrors := stats.NewCountersWithMultiLabels("TestRPCErrors", "RPC errors", []string{"Method", "Code"})
+	rpcErrors.Add([]string{"Execute", "UNAVAILABLE"}, 2)
+	rpcErrors.Add([]string{"Execute", "UNAVAILABLE"}, 1)
+	rpcErrors.Add([]string{"pg.Begin", "ABORTED"}, 1)
+	inUse := stats.NewGaugesWithMultiLabels("TestInUse", "Connections in use", []string{"Pool"})
+	inUse.Set([]string{"primary"}, 4)
+	timings := stats.NewMultiTimings("TestOperations", "Operation timings", []string{"Operation"})
+	timings.Add([]string{"Get"}, 2*time.Millisecond)
+	timings.Add([]string{"Get"}, 2*time.Second)
+
+	require.Equal(t, int64(3), queries.Get())
+	require.Equal(t, int64(4), connections.Get())
+	require.Equal(t, map[string]int64{"Execute.UNAVAILABLE": 3, "pg_Begin.ABORTED": 1}, rpcErrors.Counts())
+	require.Equal(t, stats.HistogramSnapshot{Count: 2, Sum: 2.002}, timings.Snapshots()["Get"])
+
+	// The metrics are published as expvars.
+	require.Equal(t, "3", expvar.Get("TestQueries").String())
+	require.Equal(t, `{"Execute.UNAVAILABLE":3,"pg_Begin.ABORTED":1}`, expvar.Get("TestRPCErrors").String())
+	require.Equal(t, `{"Get":{"Count":2,"Sum":2.002}}`, expvar.Get("TestOperations").String())
+
+	// And to Prometheus, in the namespace of the binary.
+	body := scrape(t)
+	for _, line := range []string{
+		"# HELP multitest_test_queries Queries served",
+		"# TYPE multitest_test_queries counter",
+		"multitest_test_queries 3",
+		"multitest_test_connections 4",
+		"multitest_test_pool_capacity 100",
+		`multitest_test_rpc_errors{code="UNAVAILABLE",method="Execute"} 3`,
+		`multitest_test_rpc_errors{code="ABORTED",method="pg.Begin"} 1`,
+		`multitest_test_in_use{pool="primary"} 4`,
+		"# TYPE multitest_test_operations histogram",
+		`multitest_test_operations_bucket{operation="Get",le="0.001"} 0`,
+		`multitest_test_operations_bucket{operation="Get",le="0.005"} 1`,
+		`multitest_test_operations_bucket{operation="Get",le="5"} 2`,
+		`multitest_test_operations_bucket{operation="Get",le="+Inf"} 2`,
+		`multitest_test_operations_count{operation="Get"} 2`,
+		"go_goroutines",
+	} {
+		require.Contains(t, body, line)
+	}
+
+	// Names are published once, and label values must match the labels.
+	require.Panics(t, func() { stats.NewCounter("TestQueries", "") })
+	require.Panics(t, func() { rpcErrors.Add([]string{"Execute"}, 1) })
+	require.Panics(t, func() { queries.Add(-1) })
+
+	// Metrics without a name are not published.
+	stats.NewCounter("", "").Add(1)
+	stats.NewCounter("", "").Add(1)
+}
+
+func TestPublishExpvarCounters(t *testing.T) {
+	stats.SetNamespace("multitest")
+	m := expvar.NewMap("TestExpvarErrors")
+	m.Add("Health.NOT_FOUND.undefined", 2)
+	m.Add("unexpected", 1)
+	stats.PublishExpvarCounters("TestExpvarErrors", "TestErrorCounts", "Errors by code", []string{"Component", "Code", "State"})
+
+	body := scrape(t)
+	require.Contains(t, body, `multitest_test_error_counts{code="NOT_FOUND",component="Health",state="undefined"} 2`)
+	require.NotContains(t, body, "unexpected")
+}
@@ -0,0 +1,263 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Counter is a value that only goes up.
+type Counter struct {
+	name, help string
+	value      atomic.Int64
+}
+
+// NewCounter returns a new Counter published as name.
+func NewCounter(name, help string) *Counter {
+	c := &Counter{name: name, help: help}
+	publish(name, c)
+	return c
+}
+
+// Add adds delta to the counter. delta must not be negative.
+func (c *Counter) Add(delta int64) {
+	if delta < 0 {
+		panic(fmt.Sprintf("stats: counter %v cannot decrease", c.name))
+	}
+	c.value.Add(delta)
+}
+
+// Get returns the value of the counter.
+func (c *Counter) Get() int64 {
+	return c.value.Load()
+}
+
+// String implements expvar.Var.
+func (c *Counter) String() string {
+	return strconv.FormatInt(c.Get(), 10)
+}
+
+func (c *Counter) collect(namespace string, ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(newDesc(namespace, c.name, c.help, nil), prometheus.CounterValue, float64(c.Get()))
+}
+
+// Gauge is a value that goes up and down.
+type Gauge struct {
+	name, help string
+	value      atomic.Int64
+}
+
+// NewGauge returns a new Gauge published as name.
+func NewGauge(name, help string) *Gauge {
+	g := &Gauge{name: name, help: help}
+	publish(name, g)
+	return g
+}
+
+// Set sets the value of the gauge.
+func (g *Gauge) Set(value int64) {
+	g.value.Store(value)
+}
+
+// Add adds delta to the gauge.
+func (g *Gauge) Add(delta int64) {
+	g.value.Add(delta)
+}
+
+// Get returns the value of the gauge.
+func (g *Gauge) Get() int64 {
+	return g.value.Load()
+}
+
+// String implements expvar.Var.
+func (g *Gauge) String() string {
+	return strconv.FormatInt(g.Get(), 10)
+}
+
+func (g *Gauge) collect(namespace string, ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(newDesc(namespace, g.name, g.help, nil), prometheus.GaugeValue, float64(g.Get()))
+}
+
+// GaugeFunc is a gauge whose value is computed when it is read.
+type GaugeFunc struct {
+	name, help string
+	f          func() int64
+}
+
+// NewGaugeFunc returns a new GaugeFunc published as name, with the value
+// returned by f.
+func NewGaugeFunc(name, help string, f func() int64) *GaugeFunc {
+	g := &GaugeFunc{name: name, help: help, f: f}
+	publish(name, g)
+	return g
+}
+
+// Get returns the value of the gauge.
+func (g *GaugeFunc) Get() int64 {
+	return g.f()
+}
+
+// String implements expvar.Var.
+func (g *GaugeFunc) String() string {
+	return strconv.FormatInt(g.Get(), 10)
+}
+
+func (g *GaugeFunc) collect(namespace string, ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(newDesc(namespace, g.name, g.help, nil), prometheus.GaugeValue, float64(g.Get()))
+}
+
+// labeledValue is the value of a labeled metric for a set of label
+// values.
+type labeledValue struct {
+	values []string
+	value  atomic.Int64
+}
+
+// multiLabels contains the values of a labeled counter or gauge.
+type multiLabels struct {
+	name, help string
+	labels     []string
+	valueType  prometheus.ValueType
+
+	// mu protects values.
+	mu     sync.RWMutex
+	values map[string]*labeledValue
+}
+
+func newMultiLabels(name, help string, labels []string, valueType prometheus.ValueType) multiLabels {
+	return multiLabels{
+		name:      name,
+		help:      help,
+		labels:    labels,
+		valueType: valueType,
+		values:    map[string]*labeledValue{},
+	}
+}
+
+// get returns the value for the label values, creating it if needed.
+func (m *multiLabels) get(values []string) *labeledValue {
+	checkLabels(m.name, m.labels, values)
+	key := labelsKey(values)
+	m.mu.RLock()
+	v, ok := m.values[key]
+	m.mu.RUnlock()
+	if ok {
+		return v
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if v, ok := m.values[key]; ok {
+		return v
+	}
+	v = &labeledValue{values: append([]string(nil), values...)}
+	m.values[key] = v
+	return v
+}
+
+// Labels returns the names of the labels.
+func (m *multiLabels) Labels() []string {
+	return m.labels
+}
+
+// Counts returns a copy of the values, keyed by their label values
+// joined with ".".
+func (m *multiLabels) Counts() map[string]int64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	counts := make(map[string]int64, len(m.values))
+	for key, v := range m.values {
+		counts[key] = v.value.Load()
+	}
+	return counts
+}
+
+// String implements expvar.Var.
+func (m *multiLabels) String() string {
+	data, err := json.Marshal(m.Counts())
+	if err != nil {
+		return "{}"
+	}
+	return string(data)
+}
+
+func (m *multiLabels) collect(namespace string, ch chan<- prometheus.Metric) {
+	desc := newDesc(namespace, m.name, m.help, m.labels)
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]string, 0, len(m.values))
+	for key := range m.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		v := m.values[key]
+		ch <- prometheus.MustNewConstMetric(desc, m.valueType, float64(v.value.Load()), v.values...)
+	}
+}
+
+// CountersWithMultiLabels is a set of counters, one per set of values
+// of its labels.
+type CountersWithMultiLabels struct {
+	multiLabels
+}
+
+// NewCountersWithMultiLabels returns new counters published as name,
+// with the given labels.
+func NewCountersWithMultiLabels(name, help string, labels []string) *CountersWithMultiLabels {
+	c := &CountersWithMultiLabels{multiLabels: newMultiLabels(name, help, labels, prometheus.CounterValue)}
+	publish(name, c)
+	return c
+}
+
+// Add adds delta to the counter of the label values. delta must not be
+// negative.
+func (c *CountersWithMultiLabels) Add(values []string, delta int64) {
+	if delta < 0 {
+		panic(fmt.Sprintf("stats: counter %v cannot decrease", c.name))
+	}
+	c.get(values).value.Add(delta)
+}
+
+// GaugesWithMultiLabels is a set of gauges, one per set of values of its
+// labels.
+type GaugesWithMultiLabels struct {
+	multiLabels
+}
+
+// NewGaugesWithMultiLabels returns new gauges published as name, with the
+// given labels.
+func NewGaugesWithMultiLabels(name, help string, labels []string) *GaugesWithMultiLabels {
+	g := &GaugesWithMultiLabels{multiLabels: newMultiLabels(name, help, labels, prometheus.GaugeValue)}
+	publish(name, g)
+	return g
+}
+
+// Set sets the gauge of the label values.
+func (g *GaugesWithMultiLabels) Set(values []string, value int64) {
+	g.get(values).value.Store(value)
+}
+
+// Add adds delta to the gauge of the label values.
+func (g *GaugesWithMultiLabels) Add(values []string, delta int64) {
+	g.get(values).value.Add(delta)
+}
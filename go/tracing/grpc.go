@@ -0,0 +1,176 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/multigres/multigres/go/mterrors"
+)
+
+// This file implements the gRPC interceptors starting a span for every
+// call, and propagating the trace context in the gRPC metadata. The
+// interceptors must be installed before the mterrors interceptors:
+//
+//	grpc.NewServer(
+//		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor, mterrors.UnaryServerInterceptor),
+//		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor, mterrors.StreamServerInterceptor),
+//	)
+
+// metadataCarrier adapts the gRPC metadata to propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+// Get implements propagation.TextMapCarrier.
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Set implements propagation.TextMapCarrier.
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys implements propagation.TextMapCarrier.
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// rpcAttributes returns the span attributes of a gRPC method.
+func rpcAttributes(fullMethod string) []attribute.KeyValue {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return []attribute.KeyValue{
+		attribute.String("rpc.system", "grpc"),
+		attribute.String("rpc.service", service),
+		attribute.String("rpc.method", method),
+	}
+}
+
+// startServerSpan starts the span of a call received by a server, child
+// of the span of the caller.
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	return otel.Tracer(tracerName).Start(ctx, fullMethod,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(rpcAttributes(fullMethod)...))
+}
+
+// startClientSpan starts the span of a call made by a client, and adds
+// its trace context to the outgoing metadata.
+func startClientSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(ctx, fullMethod,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(rpcAttributes(fullMethod)...))
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if ok {
+		md = md.Copy()
+	} else {
+		md = metadata.MD{}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, metadataCarrier(md))
+	return metadata.NewOutgoingContext(ctx, md), span
+}
+
+// endRPCSpan ends the span of a call that returned err. On the server
+// side, err was already converted to a gRPC status by the mterrors
+// interceptors, and is converted back to record its code.
+func endRPCSpan(span trace.Span, err error) {
+	err = mterrors.FromGRPC(err)
+	if err != nil {
+		span.SetAttributes(attribute.String("rpc.grpc.status_code", mterrors.CodeToGRPC(mterrors.Code(err)).String()))
+	}
+	EndSpan(span, err)
+}
+
+// UnaryServerInterceptor starts a span for every unary call received.
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, span := startServerSpan(ctx, info.FullMethod)
+	resp, err := handler(ctx, req)
+	endRPCSpan(span, err)
+	return resp, err
+}
+
+// StreamServerInterceptor starts a span for every stream received.
+func StreamServerInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+	err := handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	endRPCSpan(span, err)
+	return err
+}
+
+// serverStream is a grpc.ServerStream with the context of the span.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context implements grpc.ServerStream.
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor starts a span for every unary call made.
+func UnaryClientInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	ctx, span := startClientSpan(ctx, method)
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	endRPCSpan(span, err)
+	return err
+}
+
+// StreamClientInterceptor starts a span for every stream opened. The span
+// ends when the stream returns an error, including io.EOF at its end.
+func StreamClientInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	ctx, span := startClientSpan(ctx, method)
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		endRPCSpan(span, err)
+		return nil, err
+	}
+	return &clientStream{ClientStream: stream, span: span}, nil
+}
+
+// clientStream ends the span of a stream when the stream ends.
+type clientStream struct {
+	grpc.ClientStream
+	span trace.Span
+}
+
+// RecvMsg implements grpc.ClientStream.
+func (s *clientStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	switch err {
+	case nil:
+	case io.EOF:
+		endRPCSpan(s.span, nil)
+	default:
+		endRPCSpan(s.span, err)
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing provides the distributed tracing of the Multigres
+// binaries, with OpenTelemetry.
+//
+// Spans are started with StartSpan and ended with EndSpan, which records
+// the error of the operation:
+//
+//	ctx, span := tracing.StartSpan(ctx, "Pool.Get", attribute.String("pool", name))
+//	defer func() { tracing.EndSpan(span, err) }()
+//
+// The trace context is propagated across the gRPC calls by the
+// interceptors of this package, with the W3C Trace Context headers.
+//
+// Until Init is called, spans are not recorded. Init selects the exporter
+// with --tracing_exporter: "none", "stdout" to print the spans, or "otlp"
+// to send them to an OpenTelemetry collector at --tracing_otlp_endpoint.
+// --tracing_sampling_ratio is the fraction of the traces started by the
+// binary that are recorded. Traces started by a caller are recorded if the
+// caller recorded them.
+package tracing
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+)
+
+// tracerName is the name of the tracer of all the Multigres spans.
+const tracerName = "github.com/multigres/multigres"
+
+var (
+	// tracingExporter is the exporter of the spans: none, stdout or otlp.
+	tracingExporter = "none"
+
+	// otlpEndpoint is the address of the OpenTelemetry collector.
+	otlpEndpoint = "localhost:4317"
+
+	// otlpInsecure disables TLS to the OpenTelemetry collector.
+	otlpInsecure = true
+
+	// samplingRatio is the fraction of the new traces that are recorded.
+	samplingRatio = 1.0
+)
+
+func init() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// RegisterFlags registers the tracing flags on the provided FlagSet.
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&tracingExporter, "tracing_exporter", tracingExporter, "exporter of the trace spans (none, stdout, otlp)")
+	fs.StringVar(&otlpEndpoint, "tracing_otlp_endpoint", otlpEndpoint, "address of the OpenTelemetry collector, for the otlp exporter")
+	fs.BoolVar(&otlpInsecure, "tracing_otlp_insecure", otlpInsecure, "connect to the OpenTelemetry collector without TLS")
+	fs.Float64Var(&samplingRatio, "tracing_sampling_ratio", samplingRatio, "fraction of the new traces that are recorded, between 0 and 1")
+}
+
+// Init sets up the tracing of the binary serviceName, as configured by
+// the flags. It returns the function flushing the spans and stopping the
+// exporter, to call before the binary exits.
+func Init(ctx context.Context, serviceName string) (shutdown func(context.Context) error, err error) {
+	switch tracingExporter {
+	case "none", "":
+		return func(context.Context) error { return nil }, nil
+	case "stdout", "otlp":
+	default:
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "unknown tracing exporter %q", tracingExporter)
+	}
+	// Validate the flags before creating the exporter, which would leak.
+	if samplingRatio < 0 || samplingRatio > 1 {
+		return nil, mterrors.Errorf(mtrpcpb.Code_INVALID_ARGUMENT, "tracing sampling ratio %v is not between 0 and 1", samplingRatio)
+	}
+
+	var exporter sdktrace.SpanExporter
+	if tracingExporter == "stdout" {
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	} else {
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(otlpEndpoint)}
+		if otlpInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	}
+	if err != nil {
+		return nil, mterrors.Wrapf(err, "failed to create the %v tracing exporter", tracingExporter)
+	}
+
+	provider := NewTracerProvider(serviceName, sdktrace.WithBatcher(exporter), samplingRatio)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider returns a tracer provider for the binary serviceName,
+// sending the spans to processor and recording samplingRatio of the new
+// traces. Tests use it with an in-memory exporter:
+//
+//	exporter := tracetest.NewInMemoryExporter()
+//	otel.SetTracerProvider(tracing.NewTracerProvider("test", sdktrace.WithSyncer(exporter), 1))
+func NewTracerProvider(serviceName string, processor sdktrace.TracerProviderOption, samplingRatio float64) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		processor,
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio))),
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+	)
+}
+
+// StartSpan starts a span named name, child of the span of ctx if any.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// EndSpan ends span, recording err if not nil.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.SetAttributes(attribute.String("error.code", mterrors.Code(err).String()))
+	}
+	span.End()
+}
@@ -0,0 +1,239 @@
+// Copyright 2025 The Multigres Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/multigres/multigres/go/mterrors"
+	mtrpcpb "github.com/multigres/multigres/go/pb/mtrpc"
+	"github.com/multigres/multigres/go/tracing"
+)
+
+// setupExporter records the spans in memory, with samplingRatio of the
+// new traces, until the end of the test.
+func setupExporter(t *testing.T, samplingRatio float64) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tracing.NewTracerProvider("test", sdktrace.WithSyncer(exporter), samplingRatio))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+// attributes returns the attributes of span as a map.
+func attributes(span tracetest.SpanStub) map[attribute.Key]string {
+	attrs := make(map[attribute.Key]string)
+	for _, kv := range span.Attributes {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	return attrs
+}
+
+// serviceName returns the service name of the resource of span.
+func serviceName(span tracetest.SpanStub) string {
+	value, _ := span.Resource.Set().Value("service.name")
+	return value.Emit()
+}
+
+func TestSpans(t *testing.T) {
+	exporter := setupExporter(t, 1)
+	ctx := context.Background()
+
+	ctx, parent := tracing.StartSpan(ctx, "parent")
+	_, child := tracing.StartSpan(ctx, "child", attribute.String("pool", "primary"))
+	tracing.EndSpan(child, mterrors.New(mtrpcpb.Code_RESOURCE_EXHAUSTED, "pool is full"))
+	tracing.EndSpan(parent, nil)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, "child", spans[0].Name)
+	require.Equal(t, "parent", spans[1].Name)
+	require.Equal(t, spans[1].SpanContext.TraceID(), spans[0].SpanContext.TraceID())
+	require.Equal(t, spans[1].SpanContext.SpanID(), spans[0].Parent.SpanID())
+	require.Equal(t, "test", serviceName(spans[0]))
+
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+	require.Equal(t, "pool is full", spans[0].Status.Description)
+	require.Equal(t, "RESOURCE_EXHAUSTED", attributes(spans[0])["error.code"])
+	require.Equal(t, "primary", attributes(spans[0])["pool"])
+	require.Len(t, spans[0].Events, 1)
+	require.Equal(t, codes.Unset, spans[1].Status.Code)
+}
+
+func TestSampling(t *testing.T) {
+	exporter := setupExporter(t, 0)
+	ctx := context.Background()
+
+	// New traces are not recorded.
+	_, span := tracing.StartSpan(ctx, "new")
+	tracing.EndSpan(span, nil)
+	require.Empty(t, exporter.GetSpans())
+
+	// But traces recorded by the caller are.
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	remote := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		Remote:     true,
+	})
+	_, span = tracing.StartSpan(trace.ContextWithRemoteSpanContext(ctx, remote), "continued")
+	tracing.EndSpan(span, nil)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, traceID, spans[0].SpanContext.TraceID())
+}
+
+// healthServer returns err from all its calls, in a span of its own.
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	err error
+}
+
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	_, span := tracing.StartSpan(ctx, "check")
+	tracing.EndSpan(span, nil)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING}, nil
+}
+
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	_, span := tracing.StartSpan(stream.Context(), "watch")
+	tracing.EndSpan(span, nil)
+	return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+}
+
+func TestInterceptors(t *testing.T) {
+	exporter := setupExporter(t, 1)
+	health := &healthServer{}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(tracing.UnaryServerInterceptor, mterrors.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(tracing.StreamServerInterceptor, mterrors.StreamServerInterceptor),
+	)
+	healthpb.RegisterHealthServer(server, health)
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(tracing.UnaryClientInterceptor, mterrors.UnaryClientInterceptor),
+		grpc.WithChainStreamInterceptor(tracing.StreamClientInterceptor, mterrors.StreamClientInterceptor),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := healthpb.NewHealthClient(conn)
+	ctx := context.Background()
+
+	// The spans of the client, the server and the handler are in the
+	// same trace.
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+	handler, serverSpan, clientSpan := spans[0], spans[1], spans[2]
+	require.Equal(t, "check", handler.Name)
+	require.Equal(t, "/grpc.health.v1.Health/Check", serverSpan.Name)
+	require.Equal(t, "/grpc.health.v1.Health/Check", clientSpan.Name)
+	require.Equal(t, trace.SpanKindServer, serverSpan.SpanKind)
+	require.Equal(t, trace.SpanKindClient, clientSpan.SpanKind)
+	require.Equal(t, clientSpan.SpanContext.TraceID(), serverSpan.SpanContext.TraceID())
+	require.Equal(t, clientSpan.SpanContext.SpanID(), serverSpan.Parent.SpanID())
+	require.Equal(t, serverSpan.SpanContext.SpanID(), handler.Parent.SpanID())
+	require.Equal(t, "grpc.health.v1.Health", attributes(serverSpan)["rpc.service"])
+	require.Equal(t, "Check", attributes(serverSpan)["rpc.method"])
+
+	// The errors are recorded on both sides.
+	exporter.Reset()
+	health.err = mterrors.New(mtrpcpb.Code_UNAVAILABLE, "not serving")
+	_, err = client.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.Error(t, err)
+	spans = exporter.GetSpans()
+	require.Len(t, spans, 3)
+	for _, span := range spans[1:] {
+		require.Equal(t, codes.Error, span.Status.Code)
+		require.Equal(t, "UNAVAILABLE", attributes(span)["error.code"])
+		require.Equal(t, "Unavailable", attributes(span)["rpc.grpc.status_code"])
+	}
+
+	// The span of a stream ends with the stream.
+	exporter.Reset()
+	stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.Equal(t, io.EOF, err)
+	spans = exporter.GetSpans()
+	require.Len(t, spans, 3)
+	require.Equal(t, "watch", spans[0].Name)
+	require.Equal(t, "/grpc.health.v1.Health/Watch", spans[2].Name)
+	require.Equal(t, spans[2].SpanContext.TraceID(), spans[0].SpanContext.TraceID())
+	require.Equal(t, codes.Unset, spans[2].Status.Code)
+}
+
+func TestInit(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	tracing.RegisterFlags(fs)
+	t.Cleanup(func() {
+		require.NoError(t, fs.Parse([]string{"--tracing_exporter=none", "--tracing_sampling_ratio=1"}))
+	})
+
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{[]string{"--tracing_exporter=none"}, ""},
+		{[]string{"--tracing_exporter=zipkin"}, `unknown tracing exporter "zipkin"`},
+		{[]string{"--tracing_exporter=stdout", "--tracing_sampling_ratio=2"}, "tracing sampling ratio 2 is not between 0 and 1"},
+		// The exporter is not created with invalid flags.
+		{[]string{"--tracing_exporter=otlp", "--tracing_sampling_ratio=-1"}, "tracing sampling ratio -1 is not between 0 and 1"},
+	}
+	for _, tt := range tests {
+		require.NoError(t, fs.Parse(tt.args))
+		shutdown, err := tracing.Init(context.Background(), "test")
+		if tt.wantErr != "" {
+			require.ErrorContains(t, err, tt.wantErr)
+			require.Equal(t, mtrpcpb.Code_INVALID_ARGUMENT, mterrors.Code(err))
+			continue
+		}
+		require.NoError(t, err)
+		require.NoError(t, shutdown(context.Background()))
+	}
+}